@@ -0,0 +1,247 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ComputedRole returns the WAI-ARIA role of elem, as computed by the browser,
+// e.g. "button" or "heading".
+func (elem *WebElement) ComputedRole() (string, error) {
+	return elem.stringCommand(getComputedRole)
+}
+
+// ComputedLabel returns the accessible name of elem, as computed by the
+// browser: the name a screen reader announces.
+func (elem *WebElement) ComputedLabel() (string, error) {
+	return elem.stringCommand(getComputedLabel)
+}
+
+// roleSelectors lists the elements whose implicit role is the key. Elements
+// with an explicit role attribute are always candidates.
+var roleSelectors = map[string]string{
+	"article":       "article",
+	"banner":        "header",
+	"button":        "button, input[type=button], input[type=submit], input[type=reset], input[type=image], summary",
+	"cell":          "td",
+	"checkbox":      "input[type=checkbox]",
+	"columnheader":  "th",
+	"combobox":      "select, input[list]",
+	"complementary": "aside",
+	"contentinfo":   "footer",
+	"dialog":        "dialog",
+	"form":          "form",
+	"heading":       "h1, h2, h3, h4, h5, h6",
+	"img":           "img",
+	"link":          "a[href], area[href]",
+	"list":          "ul, ol, menu",
+	"listbox":       "select",
+	"listitem":      "li",
+	"main":          "main",
+	"navigation":    "nav",
+	"option":        "option",
+	"paragraph":     "p",
+	"progressbar":   "progress",
+	"radio":         "input[type=radio]",
+	"region":        "section",
+	"row":           "tr",
+	"rowheader":     "th",
+	"searchbox":     "input[type=search]",
+	"separator":     "hr",
+	"slider":        "input[type=range]",
+	"spinbutton":    "input[type=number]",
+	"table":         "table",
+	"textbox":       "input:not([type]), input[type=text], input[type=email], input[type=tel], input[type=url], textarea",
+}
+
+// labelSelector matches the elements that may be labelled.
+const labelSelector = "input:not([type=hidden]), select, textarea, button, meter, output, progress, [aria-label], [aria-labelledby]"
+
+// FindByRole returns the first element with the WAI-ARIA role, e.g. "button",
+// and the accessible name, e.g. "Sign in", the way assistive technologies
+// see the page. An empty name matches any name. It returns an error matching
+// ErrNoSuchElement if no element matches.
+//
+// The roles and the names are computed by a script, in one command. If the
+// server does not run the script, e.g. because JavaScript is disabled, they
+// are computed by the browser with the commands of ComputedRole and
+// ComputedLabel, one element at a time.
+func (wd *WebDriver) FindByRole(role, name string) (*WebElement, error) {
+	return firstElement(wd.FindAllByRole(role, name))
+}
+
+// FindAllByRole returns the elements with the role and the accessible name,
+// in document order. See FindByRole.
+func (wd *WebDriver) FindAllByRole(role, name string) ([]*WebElement, error) {
+	css := fmt.Sprintf("[role~=%q]", role)
+	if s, ok := roleSelectors[role]; ok {
+		css += ", " + s
+	}
+	return wd.findAccessible(css, role, name)
+}
+
+// FindByLabel returns the first element whose accessible name is text, such
+// as a form field labelled by a label element or an aria-label attribute. It
+// returns an error matching ErrNoSuchElement if no element matches.
+func (wd *WebDriver) FindByLabel(text string) (*WebElement, error) {
+	return firstElement(wd.FindAllByLabel(text))
+}
+
+// FindAllByLabel returns the elements whose accessible name is text, in
+// document order. See FindByLabel.
+func (wd *WebDriver) FindAllByLabel(text string) ([]*WebElement, error) {
+	return wd.findAccessible(labelSelector, "", text)
+}
+
+// findAccessible returns the elements matched by css whose role is role and
+// whose accessible name is name. Empty strings match any role or name.
+func (wd *WebDriver) findAccessible(css, role, name string) ([]*WebElement, error) {
+	name = normalizeSpace(name)
+	found, err := wd.findAccessibleByScript(css, role, name)
+	// The commands of the browser take two round trips per candidate, so
+	// they are only used if the server does not run the script.
+	if !wd.w3cCompatible || !(unsupported(err) || errors.Is(err, ErrJavascriptError)) {
+		return found, err
+	}
+
+	candidates, err := wd.FindElements(ByCSSSelector, css)
+	if err != nil {
+		return nil, err
+	}
+	found = nil
+	for _, elem := range candidates {
+		if role != "" {
+			r, err := elem.ComputedRole()
+			if err != nil {
+				return nil, err
+			}
+			if r != role {
+				continue
+			}
+		}
+		if name != "" {
+			l, err := elem.ComputedLabel()
+			if err != nil {
+				return nil, err
+			}
+			if normalizeSpace(l) != name {
+				continue
+			}
+		}
+		found = append(found, elem)
+	}
+	return found, nil
+}
+
+// unsupported reports whether err means that the driver does not implement
+// a command.
+func unsupported(err error) bool {
+	return errors.Is(err, ErrUnknownCommand) || errors.Is(err, ErrUnknownMethod) || errors.Is(err, ErrUnsupportedOperation)
+}
+
+func (wd *WebDriver) findAccessibleByScript(css, role, name string) ([]*WebElement, error) {
+	response, err := wd.ExecuteScriptRaw(accessibleScript, []interface{}{css, role, name})
+	if err != nil {
+		return nil, err
+	}
+	return wd.DecodeElements(response)
+}
+
+func firstElement(elems []*WebElement, err error) (*WebElement, error) {
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) == 0 {
+		return nil, &Error{Err: string(ErrNoSuchElement), Message: "no element has the role or the name"}
+	}
+	return elems[0], nil
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// accessibleScript returns the elements matched by the CSS selector
+// arguments[0] whose role is arguments[1] and whose accessible name is
+// arguments[2]. It approximates the computations of the browsers, following
+// https://www.w3.org/TR/html-aam-1.0/ and https://www.w3.org/TR/accname-1.1/.
+const accessibleScript = `
+var css = arguments[0], wantRole = arguments[1], wantName = arguments[2];
+var tags = {
+	article: 'article', aside: 'complementary', dialog: 'dialog', footer: 'contentinfo',
+	form: 'form', h1: 'heading', h2: 'heading', h3: 'heading', h4: 'heading',
+	h5: 'heading', h6: 'heading', header: 'banner', hr: 'separator', li: 'listitem',
+	main: 'main', menu: 'list', nav: 'navigation', ol: 'list', option: 'option',
+	p: 'paragraph', progress: 'progressbar', section: 'region', summary: 'button',
+	table: 'table', td: 'cell', textarea: 'textbox', th: 'columnheader', tr: 'row',
+	ul: 'list', button: 'button'
+};
+var inputs = {
+	button: 'button', checkbox: 'checkbox', email: 'textbox', image: 'button',
+	number: 'spinbutton', radio: 'radio', range: 'slider', reset: 'button',
+	search: 'searchbox', submit: 'button', tel: 'textbox', text: 'textbox', url: 'textbox'
+};
+var fromContent = {
+	button: 1, cell: 1, checkbox: 1, columnheader: 1, heading: 1, link: 1,
+	listitem: 1, menuitem: 1, option: 1, radio: 1, row: 1, rowheader: 1, switch: 1,
+	tab: 1, tooltip: 1, treeitem: 1
+};
+function normalize(s) { return (s || '').replace(/\s+/g, ' ').trim(); }
+function role(e) {
+	var r = (e.getAttribute('role') || '').trim().split(/\s+/)[0];
+	if (r) return r;
+	var tag = e.localName;
+	if (tag === 'a' || tag === 'area') return e.hasAttribute('href') ? 'link' : '';
+	if (tag === 'img') return e.getAttribute('alt') === '' ? 'presentation' : 'img';
+	if (tag === 'select') return e.multiple || e.size > 1 ? 'listbox' : 'combobox';
+	if (tag === 'input') {
+		if (e.hasAttribute('list')) return 'combobox';
+		return inputs[(e.getAttribute('type') || 'text').toLowerCase()] || '';
+	}
+	return tags[tag] || '';
+}
+function text(e) {
+	var s = '';
+	e.childNodes.forEach(function(c) {
+		if (c.nodeType === Node.TEXT_NODE) {
+			s += c.textContent;
+		} else if (c.nodeType === Node.ELEMENT_NODE && !c.hidden && c.getAttribute('aria-hidden') !== 'true' &&
+				!/^(input|select|textarea|script|style|template)$/.test(c.localName)) {
+			s += ' ' + (c.localName === 'img' ? c.alt || '' : name(c, true)) + ' ';
+		}
+	});
+	return s;
+}
+function name(e, nested) {
+	var ids = e.getAttribute('aria-labelledby');
+	if (ids && !nested) {
+		return normalize(ids.split(/\s+/).map(function(id) {
+			var l = document.getElementById(id);
+			return l ? text(l) : '';
+		}).join(' '));
+	}
+	var label = normalize(e.getAttribute('aria-label'));
+	if (label) return label;
+	var tag = e.localName;
+	if (tag === 'input' && /^(button|submit|reset)$/.test(e.type)) return normalize(e.value || (e.type === 'submit' ? 'Submit' : e.type === 'reset' ? 'Reset' : ''));
+	if (tag === 'input' && e.type === 'image') return normalize(e.alt);
+	if (!nested && e.labels && e.labels.length) {
+		return normalize(Array.prototype.map.call(e.labels, text).join(' '));
+	}
+	if (tag === 'img') return normalize(e.alt || e.title);
+	if (nested || fromContent[role(e)]) {
+		var t = normalize(text(e));
+		if (t) return t;
+	}
+	if (tag === 'input' || tag === 'textarea') return normalize(e.title || e.placeholder);
+	return normalize(e.title);
+}
+var found = [];
+document.querySelectorAll(css).forEach(function(e) {
+	if (wantRole && role(e) !== wantRole) return;
+	if (wantName && name(e) !== wantName) return;
+	found.push(e);
+});
+return found;
+`
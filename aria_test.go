@@ -0,0 +1,122 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+const ariaPage = `<title>Sign in</title>
+<nav><a href="/">Home</a><a>Not a link</a></nav>
+<h1>Welcome <img src="wave.png" alt="back"></h1>
+<form>
+  <label for="user">User name</label> <input id="user">
+  <label>Password <input type="password" id="password"></label>
+  <span id="remember-label">Remember me</span>
+  <input type="checkbox" aria-labelledby="remember-label">
+  <button type="submit">Sign <em>in</em></button>
+  <input type="reset">
+  <div role="button" aria-label="Help">?</div>
+  <button role="tab">Settings</button>
+</form>`
+
+func TestComputedRoleAndLabel(t *testing.T) {
+	wd, _ := newFakeSession(t, nil, map[string]string{homePage: ariaPage})
+
+	for _, tc := range []struct {
+		css, role, label string
+	}{
+		{"h1", "heading", "Welcome back"},
+		{"#user", "textbox", "User name"},
+		{"input[type=checkbox]", "checkbox", "Remember me"},
+		{"button[type=submit]", "button", "Sign in"},
+		{"input[type=reset]", "button", "Reset"},
+		{"a:not([href])", "", ""},
+	} {
+		elem, err := wd.FindElement(ByCSSSelector, tc.css)
+		if err != nil {
+			t.Fatalf("FindElement(%q) returned error: %v", tc.css, err)
+		}
+		if role, err := elem.ComputedRole(); err != nil || role != tc.role {
+			t.Errorf("ComputedRole() of %q = %q, %v, want %q", tc.css, role, err, tc.role)
+		}
+		if label, err := elem.ComputedLabel(); err != nil || label != tc.label {
+			t.Errorf("ComputedLabel() of %q = %q, %v, want %q", tc.css, label, err, tc.label)
+		}
+	}
+}
+
+func TestFindByRole(t *testing.T) {
+	wd, s := newFakeSession(t, nil, map[string]string{homePage: ariaPage})
+	// The server does not run the script, so the roles and the names are
+	// computed element by element.
+	s.HandleScript(accessibleScript, func([]interface{}) (interface{}, error) {
+		return nil, errors.New("JavaScript is disabled")
+	})
+
+	buttons, err := wd.FindAllByRole("button", "")
+	if err != nil {
+		t.Fatalf("FindAllByRole() returned error: %v", err)
+	}
+	// The tab is left out despite being a button element.
+	if len(buttons) != 3 {
+		t.Errorf("FindAllByRole(%q) returned %d elements, want 3", "button", len(buttons))
+	}
+	help, err := wd.FindByRole("button", "Help")
+	if err != nil {
+		t.Fatalf("FindByRole() returned error: %v", err)
+	}
+	if text, err := help.Text(); err != nil || text != "?" {
+		t.Errorf("FindByRole(%q, %q) returned the element with text %q, %v, want %q", "button", "Help", text, err, "?")
+	}
+	if links, err := wd.FindAllByRole("link", ""); err != nil || len(links) != 1 {
+		t.Errorf("FindAllByRole(%q) returned %d elements, %v, want 1", "link", len(links), err)
+	}
+	if _, err := wd.FindByRole("button", "Sign out"); !errors.Is(err, ErrNoSuchElement) {
+		t.Errorf("FindByRole() of a missing element returned %v, want %v", err, ErrNoSuchElement)
+	}
+
+	password, err := wd.FindByLabel("Password")
+	if err != nil {
+		t.Fatalf("FindByLabel() returned error: %v", err)
+	}
+	if id, err := password.GetAttribute("id"); err != nil || id != "password" {
+		t.Errorf("FindByLabel(%q) returned the element with ID %q, %v, want %q", "Password", id, err, "password")
+	}
+}
+
+func TestFindByRoleScript(t *testing.T) {
+	var computed int
+	count := func(next Handler) Handler {
+		return func(ctx context.Context, cmd *Command) (*Response, error) {
+			if cmd.Name == getComputedRole || cmd.Name == getComputedLabel {
+				computed++
+			}
+			return next(ctx, cmd)
+		}
+	}
+	wd, s := newFakeSession(t, nil, map[string]string{homePage: ariaPage}, WithInterceptors(count))
+	heading, err := wd.FindElement(ByTagName, "h1")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+
+	var gotArgs []interface{}
+	s.HandleScript(accessibleScript, func(args []interface{}) (interface{}, error) {
+		gotArgs = args
+		return []interface{}{heading}, nil
+	})
+	elem, err := wd.FindByRole("heading", " Welcome  back ")
+	if err != nil {
+		t.Fatalf("FindByRole() returned error: %v", err)
+	}
+	if elem.id != heading.id {
+		t.Errorf("FindByRole() returned element %q, want %q", elem.id, heading.id)
+	}
+	if len(gotArgs) != 3 || gotArgs[1] != "heading" || gotArgs[2] != "Welcome back" {
+		t.Errorf("the script received arguments %v, want the role and the name", gotArgs)
+	}
+	if computed != 0 {
+		t.Errorf("FindByRole() sent %d commands for the computed roles and labels, want none", computed)
+	}
+}
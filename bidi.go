@@ -0,0 +1,28 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+
+	"github.com/injoyai/selenium/bidi"
+)
+
+// BiDi connects to the WebDriver BiDi endpoint of the session. The session
+// must have been created with the "webSocketUrl" capability (see
+// Capabilities.AddBiDi), for which the remote end returns the URL of the
+// endpoint. The caller should close the session when finished; closing it
+// does not end the WebDriver session.
+func (wd *WebDriver) BiDi(ctx context.Context) (*bidi.Session, error) {
+	caps := wd.sessionCapabilities
+	if caps == nil {
+		var err error
+		if caps, err = wd.WithContext(ctx).Capabilities(); err != nil {
+			return nil, err
+		}
+	}
+	u, _ := caps["webSocketUrl"].(string)
+	if u == "" {
+		return nil, errors.New("selenium: the session has no BiDi endpoint; request it with Capabilities.AddBiDi")
+	}
+	return bidi.Dial(ctx, u)
+}
@@ -0,0 +1,245 @@
+// Package bidi is a client for the WebDriver BiDi protocol, the
+// bidirectional successor of the WebDriver HTTP protocol.
+//
+// A Session is usually obtained from a WebDriver session created with the
+// "webSocketUrl" capability (see selenium.Capabilities.AddBiDi) with
+// selenium.WebDriver.BiDi:
+//
+//	caps := selenium.Capabilities{"browserName": "firefox"}
+//	caps.AddBiDi()
+//	wd, err := selenium.NewRemote(caps, url)
+//	...
+//	s, err := wd.BiDi(ctx)
+//	entries, stop, err := s.Log().EntryAdded(ctx)
+//	defer stop()
+//	for e := range entries {
+//		fmt.Println(e.Level, e.Text)
+//	}
+//
+// See https://w3c.github.io/webdriver-bidi/ for the protocol.
+package bidi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/injoyai/selenium/internal/websocket"
+	"github.com/injoyai/selenium/internal/wsrpc"
+)
+
+// Error is an error returned by the remote end for a command. Code is one
+// of the error codes of the WebDriver specification, such as
+// "no such frame".
+type Error struct {
+	Code       string `json:"error"`
+	Message    string `json:"message"`
+	Stacktrace string `json:"stacktrace,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("bidi: %s: %s", e.Code, e.Message)
+}
+
+// ErrClosed is returned for commands sent on, or pending when closing, a
+// closed Session.
+var ErrClosed = errors.New("bidi: connection closed")
+
+// Event is an event sent by the remote end.
+type Event struct {
+	// Method is the name of the event, e.g. "log.entryAdded".
+	Method string
+	// Params are the JSON-encoded parameters of the event.
+	Params json.RawMessage
+}
+
+// Decode decodes the parameters of the event into v.
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Params, v)
+}
+
+// EventBufferSize is the capacity of the channel of each subscription. The
+// events that do not fit are queued until they are received, so that a slow
+// reader neither loses events nor blocks the connection; close the
+// subscriptions that are no longer read.
+const EventBufferSize = wsrpc.EventBufferSize
+
+// Subscription delivers the events it was created for.
+type Subscription struct {
+	// C receives the events. It is closed when the subscription or the
+	// Session is closed.
+	C <-chan Event
+
+	sub *wsrpc.Subscription
+}
+
+// Close stops the delivery of events and closes C. The remote end keeps
+// sending the events until the session ends.
+func (sub *Subscription) Close() {
+	sub.sub.Close()
+}
+
+type command struct {
+	ID     int64       `json:"id"`
+	Method string      `json:"method"`
+	Params interface{} `json:"params"`
+}
+
+type message struct {
+	Type       string          `json:"type"`
+	ID         int64           `json:"id"`
+	Method     string          `json:"method"`
+	Params     json.RawMessage `json:"params"`
+	Result     json.RawMessage `json:"result"`
+	Error      string          `json:"error"`
+	Message    string          `json:"message"`
+	Stacktrace string          `json:"stacktrace"`
+}
+
+// Session is a BiDi connection. It is safe for concurrent use.
+type Session struct {
+	conn *wsrpc.Conn
+}
+
+// Dial connects to the BiDi endpoint at wsURL, the value of the
+// "webSocketUrl" capability returned by a new session.
+func Dial(ctx context.Context, wsURL string) (*Session, error) {
+	conn, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("bidi: %v", err)
+	}
+	return &Session{conn: wsrpc.New(conn, decode, "bidi", ErrClosed)}, nil
+}
+
+// Call sends the command method with params, waits for its reply and
+// decodes the result into result, unless result is nil.
+func (s *Session) Call(ctx context.Context, method string, params, result interface{}) error {
+	if params == nil {
+		params = struct{}{}
+	}
+	res, err := s.conn.Call(ctx, func(id int64) ([]byte, error) {
+		return json.Marshal(command{ID: id, Method: method, Params: params})
+	})
+	if err != nil {
+		return err
+	}
+	if result == nil || len(res) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(res, result); err != nil {
+		return fmt.Errorf("bidi: invalid result of %s: %v", method, err)
+	}
+	return nil
+}
+
+// Subscribe asks the remote end to send events, which are event names such
+// as "log.entryAdded" or module names such as "browsingContext", and
+// returns a subscription that receives them. If contexts are given, only the
+// events of these browsing contexts are sent.
+func (s *Session) Subscribe(ctx context.Context, events []string, contexts ...string) (*Subscription, error) {
+	names := make(map[string]bool, len(events))
+	for _, e := range events {
+		names[e] = true
+	}
+	// Register first, so that no event sent right after the reply is missed.
+	sub := s.conn.Subscribe(func(m *wsrpc.Message) bool { return matches(names, m.Method) })
+	if err := s.conn.Err(); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	ch := make(chan Event, EventBufferSize)
+	go func() {
+		defer close(ch)
+		for {
+			m, ok := sub.Next()
+			if !ok {
+				return
+			}
+			select {
+			case ch <- Event{Method: m.Method, Params: m.Params}:
+			case <-sub.Closing():
+				return
+			}
+		}
+	}()
+
+	params := map[string]interface{}{"events": events}
+	if len(contexts) > 0 {
+		params["contexts"] = contexts
+	}
+	if err := s.Call(ctx, "session.subscribe", params, nil); err != nil {
+		sub.Close()
+		return nil, err
+	}
+	return &Subscription{C: ch, sub: sub}, nil
+}
+
+// matches reports whether the event method is in names, either by name or
+// by module.
+func matches(names map[string]bool, method string) bool {
+	if names[method] {
+		return true
+	}
+	for i := 0; i < len(method); i++ {
+		if method[i] == '.' {
+			return names[method[:i]]
+		}
+	}
+	return false
+}
+
+// Done returns a channel that is closed when the connection is closed.
+func (s *Session) Done() <-chan struct{} {
+	return s.conn.Done()
+}
+
+// Err returns the reason the connection was closed, or nil while it is open.
+func (s *Session) Err() error {
+	return s.conn.Err()
+}
+
+// Close closes the connection. It does not end the WebDriver session.
+func (s *Session) Close() error {
+	return s.conn.Close()
+}
+
+// decode decodes a message of the remote end.
+func decode(data []byte) (wsrpc.Message, bool) {
+	var m message
+	if err := json.Unmarshal(data, &m); err != nil {
+		return wsrpc.Message{}, false
+	}
+	switch m.Type {
+	case "success":
+		return wsrpc.Message{ID: m.ID, Result: m.Result}, true
+	case "error":
+		return wsrpc.Message{ID: m.ID, Err: &Error{Code: m.Error, Message: m.Message, Stacktrace: m.Stacktrace}}, true
+	case "event":
+		return wsrpc.Message{Method: m.Method, Params: m.Params}, m.Method != ""
+	}
+	return wsrpc.Message{}, false
+}
+
+// Status is the reply of session.status.
+type Status struct {
+	Ready   bool   `json:"ready"`
+	Message string `json:"message"`
+}
+
+// Status returns whether the remote end can create new sessions.
+func (s *Session) Status(ctx context.Context) (*Status, error) {
+	st := new(Status)
+	if err := s.Call(ctx, "session.status", nil, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// End ends the WebDriver session and closes the connection.
+func (s *Session) End(ctx context.Context) error {
+	err := s.Call(ctx, "session.end", nil, nil)
+	s.Close()
+	return err
+}
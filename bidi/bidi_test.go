@@ -0,0 +1,186 @@
+package bidi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/injoyai/selenium/internal/websocket"
+)
+
+// newRemote starts a fake BiDi endpoint. It answers script.evaluate with the
+// expression, or an exception for "throw", browsingContext.getTree with a
+// single context, and fails unknown methods. After session.subscribe, it
+// sends a log.entryAdded and a browsingContext.load event.
+func newRemote(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg struct {
+				ID     int64                  `json:"id"`
+				Method string                 `json:"method"`
+				Params map[string]interface{} `json:"params"`
+			}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Errorf("fake remote end received invalid JSON %q", data)
+				return
+			}
+			reply := map[string]interface{}{"type": "success", "id": msg.ID}
+			var events []map[string]interface{}
+			switch msg.Method {
+			case "session.subscribe":
+				reply["result"] = map[string]interface{}{}
+				events = append(events,
+					map[string]interface{}{"type": "event", "method": "log.entryAdded", "params": map[string]interface{}{
+						"type": "console", "level": "warn", "text": "careful", "method": "warn",
+						"source": map[string]string{"realm": "r1", "context": "c1"},
+					}},
+					map[string]interface{}{"type": "event", "method": "browsingContext.load", "params": map[string]interface{}{
+						"context": "c1", "navigation": "n1", "url": "http://example.com/",
+					}},
+				)
+			case "browsingContext.getTree":
+				reply["result"] = map[string]interface{}{"contexts": []map[string]interface{}{{"context": "c1", "url": "about:blank", "children": []interface{}{}}}}
+			case "script.evaluate":
+				if msg.Params["expression"] == "throw" {
+					reply["result"] = map[string]interface{}{"type": "exception", "realm": "r1", "exceptionDetails": map[string]interface{}{
+						"columnNumber": 1, "lineNumber": 2, "text": "Error: boom", "exception": map[string]string{"type": "error"},
+					}}
+					break
+				}
+				reply["result"] = map[string]interface{}{"type": "success", "realm": "r1", "result": map[string]interface{}{"type": "string", "value": msg.Params["expression"]}}
+			default:
+				reply = map[string]interface{}{"type": "error", "id": msg.ID, "error": "unknown command", "message": msg.Method}
+			}
+			data, _ = json.Marshal(reply)
+			conn.WriteMessage(data)
+			for _, e := range events {
+				data, _ = json.Marshal(e)
+				conn.WriteMessage(data)
+			}
+		}
+	}))
+}
+
+func dial(t *testing.T, hs *httptest.Server) *Session {
+	s, err := Dial(context.Background(), "ws"+strings.TrimPrefix(hs.URL, "http")+"/session/1")
+	if err != nil {
+		t.Fatalf("Dial() returned error: %v", err)
+	}
+	return s
+}
+
+func TestCall(t *testing.T) {
+	hs := newRemote(t)
+	defer hs.Close()
+	s := dial(t, hs)
+	defer s.Close()
+	ctx := context.Background()
+
+	tree, err := s.BrowsingContext().GetTree(ctx, "")
+	if err != nil {
+		t.Fatalf("GetTree() returned error: %v", err)
+	}
+	if len(tree) != 1 || tree[0].Context != "c1" {
+		t.Errorf("GetTree() = %+v, want a single context c1", tree)
+	}
+
+	v, err := s.Script().Evaluate(ctx, "1 + 1", Target{Context: "c1"}, false)
+	if err != nil {
+		t.Fatalf("Evaluate() returned error: %v", err)
+	}
+	var str string
+	if err := v.Decode(&str); err != nil || str != "1 + 1" {
+		t.Errorf("Evaluate() value = %q, %v, want %q", str, err, "1 + 1")
+	}
+
+	_, err = s.Script().Evaluate(ctx, "throw", Target{Context: "c1"}, false)
+	var exc *ExceptionDetails
+	if !errors.As(err, &exc) || exc.Text != "Error: boom" {
+		t.Errorf("Evaluate() of a throwing script returned %v, want an *ExceptionDetails", err)
+	}
+
+	err = s.BrowsingContext().Activate(ctx, "c1")
+	var e *Error
+	if !errors.As(err, &e) || e.Code != "unknown command" {
+		t.Errorf("Activate() returned %v, want an unknown command error", err)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	hs := newRemote(t)
+	defer hs.Close()
+	s := dial(t, hs)
+	defer s.Close()
+	ctx := context.Background()
+
+	entries, stop, err := s.Log().EntryAdded(ctx)
+	if err != nil {
+		t.Fatalf("EntryAdded() returned error: %v", err)
+	}
+	defer stop()
+	select {
+	case e := <-entries:
+		if e.Level != "warn" || e.Text != "careful" || e.Source.Context != "c1" {
+			t.Errorf("entry = %+v, want a warning from c1", e)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no log entry received")
+	}
+
+	events, stopEvents, err := s.BrowsingContext().Events(ctx, EventLoad)
+	if err != nil {
+		t.Fatalf("Events() returned error: %v", err)
+	}
+	select {
+	case ev := <-events:
+		var info NavigationInfo
+		if err := ev.Decode(&info); err != nil {
+			t.Fatalf("Decode() returned error: %v", err)
+		}
+		if ev.Method != EventLoad || info.URL != "http://example.com/" {
+			t.Errorf("event = %s %+v, want %s of http://example.com/", ev.Method, info, EventLoad)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no load event received")
+	}
+	stopEvents()
+	for range events {
+		// The channel must be closed after stop.
+	}
+}
+
+func TestClose(t *testing.T) {
+	hs := newRemote(t)
+	defer hs.Close()
+	s := dial(t, hs)
+
+	sub, err := s.Subscribe(context.Background(), []string{"log"})
+	if err != nil {
+		t.Fatalf("Subscribe() returned error: %v", err)
+	}
+	s.Close()
+	select {
+	case <-s.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Done() not closed after Close()")
+	}
+	for range sub.C {
+	}
+	if err := s.Call(context.Background(), "session.status", nil, nil); err != ErrClosed {
+		t.Errorf("Call() after Close() returned %v, want %v", err, ErrClosed)
+	}
+}
@@ -0,0 +1,147 @@
+package bidi
+
+import (
+	"context"
+	"encoding/base64"
+)
+
+// The events of the browsingContext module.
+const (
+	EventContextCreated    = "browsingContext.contextCreated"
+	EventContextDestroyed  = "browsingContext.contextDestroyed"
+	EventNavigationStarted = "browsingContext.navigationStarted"
+	EventDOMContentLoaded  = "browsingContext.domContentLoaded"
+	EventLoad              = "browsingContext.load"
+	EventUserPromptOpened  = "browsingContext.userPromptOpened"
+	EventUserPromptClosed  = "browsingContext.userPromptClosed"
+	EventFragmentNavigated = "browsingContext.fragmentNavigated"
+	EventNavigationFailed  = "browsingContext.navigationFailed"
+	EventNavigationAborted = "browsingContext.navigationAborted"
+)
+
+// The readiness states that Navigate and Reload can wait for.
+const (
+	ReadinessNone        = "none"
+	ReadinessInteractive = "interactive"
+	ReadinessComplete    = "complete"
+)
+
+// BrowsingContext exposes the commands of the browsingContext module.
+type BrowsingContext struct {
+	s *Session
+}
+
+// BrowsingContext returns the commands of the browsingContext module.
+func (s *Session) BrowsingContext() BrowsingContext {
+	return BrowsingContext{s}
+}
+
+// Info describes a browsing context: a tab, a window or a frame.
+type Info struct {
+	Context  string `json:"context"`
+	URL      string `json:"url"`
+	Parent   string `json:"parent,omitempty"`
+	Children []Info `json:"children"`
+}
+
+// GetTree returns the top-level browsing contexts, or the context root, and
+// their descendants.
+func (b BrowsingContext) GetTree(ctx context.Context, root string) ([]Info, error) {
+	params := map[string]interface{}{}
+	if root != "" {
+		params["root"] = root
+	}
+	var result struct {
+		Contexts []Info `json:"contexts"`
+	}
+	if err := b.s.Call(ctx, "browsingContext.getTree", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Contexts, nil
+}
+
+// Create opens a new top-level browsing context of type typ, "tab" or
+// "window", and returns its ID.
+func (b BrowsingContext) Create(ctx context.Context, typ string) (string, error) {
+	var result struct {
+		Context string `json:"context"`
+	}
+	if err := b.s.Call(ctx, "browsingContext.create", map[string]interface{}{"type": typ}, &result); err != nil {
+		return "", err
+	}
+	return result.Context, nil
+}
+
+// NavigateResult is the reply of browsingContext.navigate.
+type NavigateResult struct {
+	Navigation string `json:"navigation"`
+	URL        string `json:"url"`
+}
+
+// Navigate loads url in the browsing context id and waits until its document
+// reaches the readiness state wait, one of the Readiness constants.
+func (b BrowsingContext) Navigate(ctx context.Context, id, url, wait string) (*NavigateResult, error) {
+	params := map[string]interface{}{"context": id, "url": url}
+	if wait != "" {
+		params["wait"] = wait
+	}
+	result := new(NavigateResult)
+	if err := b.s.Call(ctx, "browsingContext.navigate", params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Reload reloads the browsing context id and waits until its document reaches
+// the readiness state wait.
+func (b BrowsingContext) Reload(ctx context.Context, id, wait string) error {
+	params := map[string]interface{}{"context": id}
+	if wait != "" {
+		params["wait"] = wait
+	}
+	return b.s.Call(ctx, "browsingContext.reload", params, nil)
+}
+
+// Close closes the top-level browsing context id.
+func (b BrowsingContext) Close(ctx context.Context, id string) error {
+	return b.s.Call(ctx, "browsingContext.close", map[string]interface{}{"context": id}, nil)
+}
+
+// Activate brings the top-level browsing context id to the foreground.
+func (b BrowsingContext) Activate(ctx context.Context, id string) error {
+	return b.s.Call(ctx, "browsingContext.activate", map[string]interface{}{"context": id}, nil)
+}
+
+// CaptureScreenshot returns a PNG screenshot of the browsing context id.
+func (b BrowsingContext) CaptureScreenshot(ctx context.Context, id string) ([]byte, error) {
+	var result struct {
+		Data string `json:"data"`
+	}
+	if err := b.s.Call(ctx, "browsingContext.captureScreenshot", map[string]interface{}{"context": id}, &result); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(result.Data)
+}
+
+// NavigationInfo is sent with the navigation and load events.
+type NavigationInfo struct {
+	Context    string  `json:"context"`
+	Navigation string  `json:"navigation"`
+	Timestamp  float64 `json:"timestamp"`
+	URL        string  `json:"url"`
+}
+
+// Events subscribes to the given events of the browsingContext module, or
+// to all of them if none is given. The events of EventContextCreated and
+// EventContextDestroyed decode into Info, the others into NavigationInfo.
+// The channel is closed after stop is called or when the session is closed.
+func (b BrowsingContext) Events(ctx context.Context, events ...string) (<-chan Event, func(), error) {
+	if len(events) == 0 {
+		events = []string{"browsingContext"}
+	}
+	sub, err := b.s.Subscribe(ctx, events)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sub.C, sub.Close, nil
+}
@@ -0,0 +1,78 @@
+package bidi
+
+import "context"
+
+// EventEntryAdded is the event of the log module.
+const EventEntryAdded = "log.entryAdded"
+
+// Log exposes the events of the log module.
+type Log struct {
+	s *Session
+}
+
+// Log returns the events of the log module.
+func (s *Session) Log() Log {
+	return Log{s}
+}
+
+// StackFrame is a frame of a JavaScript stack trace.
+type StackFrame struct {
+	ColumnNumber int    `json:"columnNumber"`
+	FunctionName string `json:"functionName"`
+	LineNumber   int    `json:"lineNumber"`
+	URL          string `json:"url"`
+}
+
+// StackTrace is a JavaScript stack trace.
+type StackTrace struct {
+	CallFrames []StackFrame `json:"callFrames"`
+}
+
+// Source is the realm and browsing context an entry comes from.
+type Source struct {
+	Realm   string `json:"realm"`
+	Context string `json:"context,omitempty"`
+}
+
+// LogEntry is a console message or a JavaScript error.
+type LogEntry struct {
+	// Type is "console" for console messages, "javascript" for uncaught
+	// errors.
+	Type string `json:"type"`
+	// Level is "debug", "info", "warn" or "error".
+	Level      string      `json:"level"`
+	Source     Source      `json:"source"`
+	Text       string      `json:"text"`
+	Timestamp  int64       `json:"timestamp"`
+	StackTrace *StackTrace `json:"stackTrace,omitempty"`
+	// Method is the console method, e.g. "log", for console messages.
+	Method string `json:"method,omitempty"`
+	// Args are the arguments of the console method.
+	Args []RemoteValue `json:"args,omitempty"`
+}
+
+// EntryAdded subscribes to log.entryAdded, for the given browsing contexts
+// or all of them, and returns the decoded entries. The channel is closed
+// after stop is called or when the session is closed.
+func (l Log) EntryAdded(ctx context.Context, contexts ...string) (entries <-chan LogEntry, stop func(), err error) {
+	sub, err := l.s.Subscribe(ctx, []string{EventEntryAdded}, contexts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	ch := make(chan LogEntry, EventBufferSize)
+	go func() {
+		defer close(ch)
+		for ev := range sub.C {
+			var e LogEntry
+			if err := ev.Decode(&e); err != nil {
+				continue
+			}
+			select {
+			case ch <- e:
+			case <-sub.sub.Closing():
+				return
+			}
+		}
+	}()
+	return ch, sub.Close, nil
+}
@@ -0,0 +1,161 @@
+package bidi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// The events of the script module.
+const (
+	EventMessage        = "script.message"
+	EventRealmCreated   = "script.realmCreated"
+	EventRealmDestroyed = "script.realmDestroyed"
+)
+
+// Script exposes the commands of the script module.
+type Script struct {
+	s *Session
+}
+
+// Script returns the commands of the script module.
+func (s *Session) Script() Script {
+	return Script{s}
+}
+
+// Target is where a script runs: a browsing context, or a realm.
+type Target struct {
+	Context string `json:"context,omitempty"`
+	Realm   string `json:"realm,omitempty"`
+	Sandbox string `json:"sandbox,omitempty"`
+}
+
+// RemoteValue is a JavaScript value serialized by the remote end.
+type RemoteValue struct {
+	// Type is "undefined", "null", "string", "number", "boolean", "bigint",
+	// "array", "object", "node", ...
+	Type     string          `json:"type"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	Handle   string          `json:"handle,omitempty"`
+	SharedID string          `json:"sharedId,omitempty"`
+}
+
+// Decode decodes the value of primitive types into v.
+func (r *RemoteValue) Decode(v interface{}) error {
+	if len(r.Value) == 0 {
+		return fmt.Errorf("bidi: %s value has no serialization", r.Type)
+	}
+	return json.Unmarshal(r.Value, v)
+}
+
+// ExceptionDetails describes an exception thrown by a script. It is
+// returned as an error by Evaluate and CallFunction.
+type ExceptionDetails struct {
+	ColumnNumber int         `json:"columnNumber"`
+	LineNumber   int         `json:"lineNumber"`
+	Exception    RemoteValue `json:"exception"`
+	Text         string      `json:"text"`
+}
+
+// Error implements the error interface.
+func (e *ExceptionDetails) Error() string {
+	return fmt.Sprintf("bidi: uncaught exception at %d:%d: %s", e.LineNumber, e.ColumnNumber, e.Text)
+}
+
+type evaluateResult struct {
+	Type             string            `json:"type"`
+	Result           *RemoteValue      `json:"result"`
+	ExceptionDetails *ExceptionDetails `json:"exceptionDetails"`
+}
+
+func (r *evaluateResult) value() (*RemoteValue, error) {
+	if r.Type == "exception" && r.ExceptionDetails != nil {
+		return nil, r.ExceptionDetails
+	}
+	return r.Result, nil
+}
+
+// Evaluate evaluates expression in target and returns its value, after
+// waiting for the promise it returns if awaitPromise is set. An uncaught
+// exception is returned as an *ExceptionDetails error.
+func (sc Script) Evaluate(ctx context.Context, expression string, target Target, awaitPromise bool) (*RemoteValue, error) {
+	params := map[string]interface{}{
+		"expression":   expression,
+		"target":       target,
+		"awaitPromise": awaitPromise,
+	}
+	var result evaluateResult
+	if err := sc.s.Call(ctx, "script.evaluate", params, &result); err != nil {
+		return nil, err
+	}
+	return result.value()
+}
+
+// CallFunction calls the function declared by functionDeclaration in target
+// with args, which are serialized as BiDi local values, and returns its
+// value. The string, number, boolean and nil arguments are serialized;
+// arguments of type *RemoteValue are passed by handle or shared ID.
+func (sc Script) CallFunction(ctx context.Context, functionDeclaration string, target Target, awaitPromise bool, args ...interface{}) (*RemoteValue, error) {
+	arguments := make([]interface{}, len(args))
+	for i, a := range args {
+		v, err := localValue(a)
+		if err != nil {
+			return nil, err
+		}
+		arguments[i] = v
+	}
+	params := map[string]interface{}{
+		"functionDeclaration": functionDeclaration,
+		"target":              target,
+		"awaitPromise":        awaitPromise,
+		"arguments":           arguments,
+	}
+	var result evaluateResult
+	if err := sc.s.Call(ctx, "script.callFunction", params, &result); err != nil {
+		return nil, err
+	}
+	return result.value()
+}
+
+func localValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return map[string]string{"type": "null"}, nil
+	case string:
+		return map[string]interface{}{"type": "string", "value": v}, nil
+	case bool:
+		return map[string]interface{}{"type": "boolean", "value": v}, nil
+	case int, int32, int64, float32, float64:
+		return map[string]interface{}{"type": "number", "value": v}, nil
+	case *RemoteValue:
+		ref := map[string]string{}
+		if v.SharedID != "" {
+			ref["sharedId"] = v.SharedID
+		}
+		if v.Handle != "" {
+			ref["handle"] = v.Handle
+		}
+		if len(ref) == 0 {
+			return nil, fmt.Errorf("bidi: %s value has no handle", v.Type)
+		}
+		return ref, nil
+	}
+	return nil, fmt.Errorf("bidi: cannot pass argument of type %T", v)
+}
+
+// AddPreloadScript runs functionDeclaration in every new document before
+// its own scripts, and returns an identifier for RemovePreloadScript.
+func (sc Script) AddPreloadScript(ctx context.Context, functionDeclaration string) (string, error) {
+	var result struct {
+		Script string `json:"script"`
+	}
+	if err := sc.s.Call(ctx, "script.addPreloadScript", map[string]interface{}{"functionDeclaration": functionDeclaration}, &result); err != nil {
+		return "", err
+	}
+	return result.Script, nil
+}
+
+// RemovePreloadScript removes a script added with AddPreloadScript.
+func (sc Script) RemovePreloadScript(ctx context.Context, script string) error {
+	return sc.s.Call(ctx, "script.removePreloadScript", map[string]interface{}{"script": script}, nil)
+}
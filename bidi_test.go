@@ -0,0 +1,87 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/injoyai/selenium/internal/websocket"
+)
+
+func TestBiDi(t *testing.T) {
+	bs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg struct{ ID int64 }
+			json.Unmarshal(data, &msg)
+			conn.WriteMessage([]byte(fmt.Sprintf(`{"type":"success","id":%d,"result":{"ready":false,"message":"busy"}}`, msg.ID)))
+		}
+	}))
+	defer bs.Close()
+
+	var requested interface{}
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Capabilities struct {
+				AlwaysMatch map[string]interface{} `json:"alwaysMatch"`
+			} `json:"capabilities"`
+		}
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		requested = body.Capabilities.AlwaysMatch["webSocketUrl"]
+		w.Header().Set("Content-Type", jsonContentType)
+		fmt.Fprintf(w, `{"value":{"sessionId":"1","capabilities":{"browserName":"firefox","webSocketUrl":"ws%s/session/1"}}}`, strings.TrimPrefix(bs.URL, "http"))
+	}))
+	defer hs.Close()
+
+	caps := Capabilities{"browserName": "firefox"}
+	caps.AddBiDi()
+	wd, err := NewRemote(caps, hs.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	if requested != true {
+		t.Errorf("webSocketUrl capability sent = %v, want true", requested)
+	}
+	s, err := wd.BiDi(context.Background())
+	if err != nil {
+		t.Fatalf("BiDi() returned error: %v", err)
+	}
+	defer s.Close()
+	st, err := s.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status() returned error: %v", err)
+	}
+	if st.Ready || st.Message != "busy" {
+		t.Errorf("Status() = %+v, want not ready and busy", st)
+	}
+}
+
+func TestBiDiWithoutEndpoint(t *testing.T) {
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		fmt.Fprint(w, `{"value":{"sessionId":"1","capabilities":{"browserName":"firefox"}}}`)
+	}))
+	defer hs.Close()
+
+	wd, err := NewRemote(nil, hs.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	if _, err := wd.BiDi(context.Background()); err == nil {
+		t.Fatalf("BiDi() for a session without a BiDi endpoint did not return an error")
+	}
+}
@@ -0,0 +1,421 @@
+// Package cassette records the commands that a selenium.WebDriver sends to its
+// server into a file, and replays them without a server.
+//
+// A cassette is installed as an interceptor:
+//
+//	c := cassette.New()
+//	wd, err := selenium.NewRemoteWithOptions(caps, url, selenium.WithInterceptors(c.Record))
+//	...
+//	err = c.Save("testdata/login.json")
+//
+// and later, in a test without a browser:
+//
+//	c, err := cassette.Load("testdata/login.json")
+//	wd, err := selenium.NewRemoteWithOptions(caps, "http://replay", selenium.WithInterceptors(c.Replay))
+//
+// Session IDs, element references and window handles are replaced by stable
+// placeholders in the URL paths and in the JSON fields that hold them when
+// recording, so that the replayed session is deterministic.
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/injoyai/selenium"
+)
+
+// The keys under which the WebDriver protocol returns references.
+const (
+	webElementIdentifier       = "element-6066-11e4-a52e-4f735466cecf"
+	legacyWebElementIdentifier = "ELEMENT"
+	shadowRootIdentifier       = "shadow-6066-11e4-a52e-4f735466cecf"
+)
+
+// referenceKeys are the keys of the JSON fields that hold session IDs,
+// element and shadow root references, and window handles.
+var referenceKeys = map[string]bool{
+	"sessionId":                true,
+	webElementIdentifier:       true,
+	legacyWebElementIdentifier: true,
+	shadowRootIdentifier:       true,
+	"handle":                   true,
+}
+
+// Interaction is a recorded command and the reply of the server.
+type Interaction struct {
+	// Command is the name of the command, e.g. "findElement".
+	Command string `json:"command"`
+	// Method is the HTTP method of the command.
+	Method string `json:"method"`
+	// Path is the URL path of the command, starting at "/session" or
+	// "/status".
+	Path string `json:"path"`
+	// Request is the normalized payload of the command, if any.
+	Request json.RawMessage `json:"request,omitempty"`
+	// RawRequest reports whether the payload of the command was not JSON. It
+	// is then stored in Request as a JSON string.
+	RawRequest bool `json:"rawRequest,omitempty"`
+	// Status is the HTTP status code of the reply.
+	Status int `json:"status"`
+	// ContentType is the Content-Type header of the reply.
+	ContentType string `json:"contentType,omitempty"`
+	// Response is the payload of the reply.
+	Response json.RawMessage `json:"response"`
+	// Raw reports whether the reply was not JSON, such as an error page. Its
+	// payload is then stored in Response as a JSON string.
+	Raw bool `json:"raw,omitempty"`
+}
+
+// Cassette holds recorded interactions. It is safe for concurrent use.
+type Cassette struct {
+	// Interactions are the recorded interactions, in order.
+	Interactions []*Interaction `json:"interactions"`
+
+	mu   sync.Mutex
+	used []bool
+	// ids maps the IDs seen while recording to their placeholders.
+	ids    map[string]string
+	counts map[string]int
+}
+
+// New returns an empty cassette, ready to record.
+func New() *Cassette {
+	return &Cassette{}
+}
+
+// Load reads a cassette previously written by Save.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Cassette)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("cassette %q: %v", path, err)
+	}
+	// Save indents the payloads, so compact them again for matching.
+	for _, in := range c.Interactions {
+		if in.RawRequest {
+			continue
+		}
+		if in.Request, err = normalize(in.Request); err != nil {
+			return nil, fmt.Errorf("cassette %q: %v", path, err)
+		}
+	}
+	return c, nil
+}
+
+// Save writes the recorded interactions to path as JSON.
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Record is a selenium.Interceptor that sends commands to the server and
+// records them together with their replies.
+func (c *Cassette) Record(next selenium.Handler) selenium.Handler {
+	return func(ctx context.Context, cmd *selenium.Command) (*selenium.Response, error) {
+		resp, err := next(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.learnIDs(cmd.Name, resp.Body)
+		in := &Interaction{
+			Command:     cmd.Name,
+			Method:      cmd.Method,
+			Path:        c.rewritePath(commandPath(cmd.URL)),
+			Status:      resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+		}
+		// The command was run, so payloads that are not JSON are kept as they
+		// are rather than failing it.
+		if in.Request, err = c.rewriteRequest(cmd.Name, cmd.Body); err != nil {
+			in.Request, _ = json.Marshal(string(cmd.Body))
+			in.RawRequest = true
+		}
+		if in.Response, err = c.rewriteReply(cmd.Name, resp.Body); err != nil {
+			in.Response, _ = json.Marshal(string(resp.Body))
+			in.Raw = true
+		}
+		c.Interactions = append(c.Interactions, in)
+		c.used = append(c.used, true)
+		return resp, nil
+	}
+}
+
+// Replay is a selenium.Interceptor that serves the recorded replies instead
+// of contacting the server. Each recorded interaction is served once, in
+// order, to the first command with the same method, command name, path and
+// normalized payload. An error is returned for commands that were not
+// recorded.
+func (c *Cassette) Replay(selenium.Handler) selenium.Handler {
+	return func(ctx context.Context, cmd *selenium.Command) (*selenium.Response, error) {
+		request, err := normalize(cmd.Body)
+		rawRequest := err != nil
+		if rawRequest {
+			request, _ = json.Marshal(string(cmd.Body))
+		}
+		path := commandPath(cmd.URL)
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if len(c.used) < len(c.Interactions) {
+			c.used = append(c.used, make([]bool, len(c.Interactions)-len(c.used))...)
+		}
+		for i, in := range c.Interactions {
+			if c.used[i] || in.Command != cmd.Name || in.Method != cmd.Method || in.Path != path || in.RawRequest != rawRequest || !bytes.Equal(in.Request, request) {
+				continue
+			}
+			c.used[i] = true
+			body := []byte(in.Response)
+			if in.Raw {
+				var s string
+				if err := json.Unmarshal(body, &s); err != nil {
+					return nil, fmt.Errorf("cassette: invalid raw reply to %s: %v", in.Command, err)
+				}
+				body = []byte(s)
+			}
+			header := make(http.Header)
+			switch {
+			case in.ContentType != "":
+				header.Set("Content-Type", in.ContentType)
+			case !in.Raw:
+				// Cassettes recorded without the Content-Type of the replies.
+				header.Set("Content-Type", "application/json; charset=utf-8")
+			}
+			return &selenium.Response{
+				StatusCode: in.Status,
+				Status:     fmt.Sprintf("%d %s", in.Status, http.StatusText(in.Status)),
+				Header:     header,
+				Body:       body,
+			}, nil
+		}
+		return nil, fmt.Errorf("cassette: no recorded interaction for %s %s %s", cmd.Name, cmd.Method, path)
+	}
+}
+
+// learnIDs assigns placeholders to the session ID, the element and shadow
+// root references, and the window handles returned in a reply.
+func (c *Cassette) learnIDs(command string, body []byte) {
+	var reply struct {
+		SessionID string `json:"sessionId"`
+		Value     interface{}
+	}
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return
+	}
+	if reply.SessionID != "" {
+		c.assign("session", reply.SessionID)
+	}
+	switch command {
+	case "newSession":
+		if v, ok := reply.Value.(map[string]interface{}); ok {
+			if id, ok := v["sessionId"].(string); ok {
+				c.assign("session", id)
+			}
+		}
+	case "getWindowHandle":
+		if id, ok := reply.Value.(string); ok {
+			c.assign("window", id)
+		}
+	case "getWindowHandles":
+		if ids, ok := reply.Value.([]interface{}); ok {
+			for _, id := range ids {
+				if id, ok := id.(string); ok {
+					c.assign("window", id)
+				}
+			}
+		}
+	case "newWindow":
+		if v, ok := reply.Value.(map[string]interface{}); ok {
+			if id, ok := v["handle"].(string); ok {
+				c.assign("window", id)
+			}
+		}
+	}
+	c.learnReferences(reply.Value)
+}
+
+func (c *Cassette) learnReferences(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, kind := range map[string]string{
+			webElementIdentifier:       "element",
+			legacyWebElementIdentifier: "element",
+			shadowRootIdentifier:       "shadow",
+		} {
+			if id, ok := v[key].(string); ok && id != "" {
+				c.assign(kind, id)
+			}
+		}
+		for _, e := range v {
+			c.learnReferences(e)
+		}
+	case []interface{}:
+		for _, e := range v {
+			c.learnReferences(e)
+		}
+	}
+}
+
+func (c *Cassette) assign(kind, id string) {
+	if c.ids == nil {
+		c.ids = make(map[string]string)
+		c.counts = make(map[string]int)
+	}
+	if _, ok := c.ids[id]; ok {
+		return
+	}
+	c.counts[kind]++
+	c.ids[id] = fmt.Sprintf("%s-%d", kind, c.counts[kind])
+}
+
+// placeholder returns the placeholder of id, or id if it is not known.
+func (c *Cassette) placeholder(id string) string {
+	if p, ok := c.ids[id]; ok {
+		return p
+	}
+	return id
+}
+
+// rewritePath replaces the segments of the escaped path p that are known IDs
+// with their placeholders. The other segments are kept escaped.
+func (c *Cassette) rewritePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		id, err := url.PathUnescape(seg)
+		if err != nil {
+			continue
+		}
+		if placeholder, ok := c.ids[id]; ok {
+			segments[i] = placeholder
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// rewriteRequest normalizes the payload of a command and replaces the known
+// IDs in its reference fields.
+func (c *Cassette) rewriteRequest(command string, data []byte) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	v, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: invalid command payload: %v", err)
+	}
+	if m, ok := v.(map[string]interface{}); ok && command == "switchToWindow" {
+		// Legacy servers take the handle in the name field.
+		if name, ok := m["name"].(string); ok {
+			m["name"] = c.placeholder(name)
+		}
+	}
+	return json.Marshal(c.replaceReferences(v))
+}
+
+// rewriteReply replaces the known IDs in the reference fields of a reply, and
+// in the window handles returned by command. It returns an error if the reply
+// is not JSON.
+func (c *Cassette) rewriteReply(command string, data []byte) (json.RawMessage, error) {
+	v, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := v.(map[string]interface{}); ok {
+		switch command {
+		case "getWindowHandle":
+			if id, ok := m["value"].(string); ok {
+				m["value"] = c.placeholder(id)
+			}
+		case "getWindowHandles", "closeWindow":
+			if ids, ok := m["value"].([]interface{}); ok {
+				for i, id := range ids {
+					if id, ok := id.(string); ok {
+						ids[i] = c.placeholder(id)
+					}
+				}
+			}
+		}
+	}
+	return json.Marshal(c.replaceReferences(v))
+}
+
+// replaceReferences replaces the known IDs held by the fields of v named in
+// referenceKeys. Other strings are kept as they are, even if they contain an
+// ID.
+func (c *Cassette) replaceReferences(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, e := range v {
+			if id, ok := e.(string); ok && referenceKeys[key] {
+				v[key] = c.placeholder(id)
+			} else {
+				v[key] = c.replaceReferences(e)
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = c.replaceReferences(e)
+		}
+	}
+	return v
+}
+
+// decode decodes a JSON payload, keeping numbers as they are written.
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, errors.New("trailing data after JSON value")
+	}
+	return v, nil
+}
+
+// normalize re-encodes a JSON payload so that equivalent payloads compare
+// equal regardless of key order and white space.
+func normalize(data []byte) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	v, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("cassette: invalid command payload: %v", err)
+	}
+	return json.Marshal(v)
+}
+
+// commandPath strips the URL prefix of the server from the URL of a command.
+func commandPath(u string) string {
+	p := u
+	if parsed, err := url.Parse(u); err == nil {
+		p = parsed.EscapedPath()
+	}
+	if i := strings.Index(p, "/session"); i >= 0 {
+		return p[i:]
+	}
+	if strings.HasSuffix(p, "/status") {
+		return "/status"
+	}
+	return p
+}
@@ -0,0 +1,243 @@
+package cassette
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/injoyai/selenium"
+)
+
+// newServer returns a server that hands out new IDs on every run, like a
+// real browser does.
+func newServer(run int) *httptest.Server {
+	session := fmt.Sprintf("session-%d-real", run)
+	element := fmt.Sprintf("element-%d-real", run)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/session":
+			fmt.Fprintf(w, `{"value":{"sessionId":%q,"capabilities":{}}}`, session)
+		case "/session/" + session + "/element":
+			fmt.Fprintf(w, `{"value":{"element-6066-11e4-a52e-4f735466cecf":%q}}`, element)
+		case "/session/" + session + "/element/" + element + "/text":
+			io.WriteString(w, `{"value":"hello"}`)
+		case "/session/" + session + "/cookie/a b", "/session/" + session + "/cookie/a/b":
+			io.WriteString(w, `{"value":null}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"value":{"error":"unknown command","message":""}}`)
+		}
+	}))
+}
+
+func scrape(wd *selenium.WebDriver) (string, error) {
+	elem, err := wd.FindElement(selenium.ByCSSSelector, "#greeting")
+	if err != nil {
+		return "", err
+	}
+	return elem.Text()
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	hs := newServer(1)
+	c := New()
+	wd, err := selenium.NewRemoteWithOptions(nil, hs.URL, selenium.WithInterceptors(c.Record))
+	if err != nil {
+		t.Fatalf("NewRemoteWithOptions() returned error: %v", err)
+	}
+	if _, err := scrape(wd); err != nil {
+		t.Fatalf("scrape() returned error: %v", err)
+	}
+	hs.Close()
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+	for _, in := range c.Interactions {
+		if s := in.Path + string(in.Response); strings.Contains(s, "-real") {
+			t.Errorf("recorded interaction %s contains a real ID: %s", in.Command, s)
+		}
+	}
+
+	c, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	wd, err = selenium.NewRemoteWithOptions(nil, "http://replay.invalid", selenium.WithInterceptors(c.Replay))
+	if err != nil {
+		t.Fatalf("NewRemoteWithOptions() in replay returned error: %v", err)
+	}
+	if wd.SessionID() != "session-1" {
+		t.Errorf("SessionID() = %q, want %q", wd.SessionID(), "session-1")
+	}
+	text, err := scrape(wd)
+	if err != nil {
+		t.Fatalf("scrape() in replay returned error: %v", err)
+	}
+	if text != "hello" {
+		t.Errorf("Text() = %q, want %q", text, "hello")
+	}
+
+	// Every interaction is served once.
+	if _, err := scrape(wd); err == nil {
+		t.Errorf("scrape() beyond the recording did not return an error")
+	}
+}
+
+func TestRecordAndReplayEscapedPath(t *testing.T) {
+	names := []string{"a b", "a/b"}
+
+	hs := newServer(1)
+	defer hs.Close()
+	c := New()
+	wd, err := selenium.NewRemoteWithOptions(nil, hs.URL, selenium.WithInterceptors(c.Record))
+	if err != nil {
+		t.Fatalf("NewRemoteWithOptions() returned error: %v", err)
+	}
+	for _, name := range names {
+		if err := wd.DeleteCookie(name); err != nil {
+			t.Fatalf("DeleteCookie(%q) returned error: %v", name, err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	c, err = Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	wd, err = selenium.NewRemoteWithOptions(nil, "http://replay.invalid", selenium.WithInterceptors(c.Replay))
+	if err != nil {
+		t.Fatalf("NewRemoteWithOptions() in replay returned error: %v", err)
+	}
+	for _, name := range names {
+		if err := wd.DeleteCookie(name); err != nil {
+			t.Errorf("DeleteCookie(%q) in replay returned error: %v", name, err)
+		}
+	}
+}
+
+func TestReplayMatchesNormalizedBody(t *testing.T) {
+	c := &Cassette{Interactions: []*Interaction{{
+		Command:  "findElement",
+		Method:   http.MethodPost,
+		Path:     "/session/session-1/element",
+		Request:  []byte(`{"using":"css selector","value":"a"}`),
+		Status:   http.StatusOK,
+		Response: []byte(`{"value":{"ELEMENT":"element-1"}}`),
+	}}}
+	handler := c.Replay(nil)
+	resp, err := handler(context.Background(), &selenium.Command{
+		Name:   "findElement",
+		Method: http.MethodPost,
+		URL:    "http://localhost:4444/wd/hub/session/session-1/element",
+		Body:   []byte(`{ "value": "a", "using": "css selector" }`),
+	})
+	if err != nil {
+		t.Fatalf("Replay() returned error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Replay() returned status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestRecordKeepsPayloads(t *testing.T) {
+	replies := map[string]string{
+		"newSession": `{"value":{"sessionId":"abc","capabilities":{"note":"abc"}}}`,
+		"getTitle":   `{"value":"abc and abcd"}`,
+		"getSource":  `"abc"`,
+		"getStatus":  `Bad Gateway abc`,
+	}
+	next := func(ctx context.Context, cmd *selenium.Command) (*selenium.Response, error) {
+		return &selenium.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"text/plain"}},
+			Body:       []byte(replies[cmd.Name]),
+		}, nil
+	}
+	c := New()
+	record := c.Record(next)
+	for _, cmd := range []*selenium.Command{
+		{Name: "newSession", Method: http.MethodPost, URL: "http://localhost/session"},
+		{Name: "getTitle", Method: http.MethodGet, URL: "http://localhost/session/abc/title"},
+		{Name: "getSource", Method: http.MethodGet, URL: "http://localhost/session/abc/source"},
+		{Name: "getStatus", Method: http.MethodPost, URL: "http://localhost/session/abcd/status", Body: []byte("abc")},
+	} {
+		if _, err := record(context.Background(), cmd); err != nil {
+			t.Fatalf("Record() of %s returned error: %v", cmd.Name, err)
+		}
+	}
+
+	for i, tc := range []struct {
+		path, response string
+		raw            bool
+	}{
+		{"/session", `{"value":{"capabilities":{"note":"abc"},"sessionId":"session-1"}}`, false},
+		{"/session/session-1/title", `{"value":"abc and abcd"}`, false},
+		{"/session/session-1/source", `"abc"`, false},
+		{"/session/abcd/status", `"Bad Gateway abc"`, true},
+	} {
+		in := c.Interactions[i]
+		if in.Path != tc.path || string(in.Response) != tc.response || in.Raw != tc.raw {
+			t.Errorf("recorded %s %s (raw %t), want %s %s (raw %t)", in.Path, in.Response, in.Raw, tc.path, tc.response, tc.raw)
+		}
+		if in.ContentType != "text/plain" {
+			t.Errorf("recorded %s with Content-Type %q, want %q", in.Path, in.ContentType, "text/plain")
+		}
+	}
+	if in := c.Interactions[3]; !in.RawRequest || string(in.Request) != `"abc"` {
+		t.Errorf("recorded request %s (raw %t), want %s (raw true)", in.Request, in.RawRequest, `"abc"`)
+	}
+
+	// The command with the payload that is not JSON is replayed too.
+	replay := &Cassette{Interactions: c.Interactions}
+	resp, err := replay.Replay(nil)(context.Background(), &selenium.Command{
+		Name:   "getStatus",
+		Method: http.MethodPost,
+		URL:    "http://replay.invalid/session/abcd/status",
+		Body:   []byte("abc"),
+	})
+	if err != nil {
+		t.Fatalf("Replay() returned error: %v", err)
+	}
+	if string(resp.Body) != "Bad Gateway abc" {
+		t.Errorf("Replay() returned %s, want %s", resp.Body, "Bad Gateway abc")
+	}
+}
+
+func TestReplayRawReply(t *testing.T) {
+	c := &Cassette{Interactions: []*Interaction{
+		{Command: "getSource", Method: http.MethodGet, Path: "/session/session-1/source", Status: http.StatusOK, Response: []byte(`"abc"`)},
+		{Command: "getStatus", Method: http.MethodGet, Path: "/status", Status: http.StatusBadGateway, Response: []byte(`"Bad Gateway"`), Raw: true},
+		{Command: "getStatus", Method: http.MethodGet, Path: "/status", Status: http.StatusBadGateway, ContentType: "text/html", Response: []byte(`"<p>"`), Raw: true},
+	}}
+	handler := c.Replay(nil)
+	for _, tc := range []struct {
+		cmd               *selenium.Command
+		want, contentType string
+	}{
+		{&selenium.Command{Name: "getSource", Method: http.MethodGet, URL: "http://localhost/session/session-1/source"}, `"abc"`, "application/json; charset=utf-8"},
+		{&selenium.Command{Name: "getStatus", Method: http.MethodGet, URL: "http://localhost/status"}, `Bad Gateway`, ""},
+		{&selenium.Command{Name: "getStatus", Method: http.MethodGet, URL: "http://localhost/status"}, `<p>`, "text/html"},
+	} {
+		resp, err := handler(context.Background(), tc.cmd)
+		if err != nil {
+			t.Fatalf("Replay() of %s returned error: %v", tc.cmd.Name, err)
+		}
+		if string(resp.Body) != tc.want {
+			t.Errorf("Replay() of %s returned %s, want %s", tc.cmd.Name, resp.Body, tc.want)
+		}
+		if got := resp.Header.Get("Content-Type"); got != tc.contentType {
+			t.Errorf("Replay() of %s returned Content-Type %q, want %q", tc.cmd.Name, got, tc.contentType)
+		}
+	}
+}
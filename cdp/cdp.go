@@ -0,0 +1,403 @@
+// Package cdp is a client for the Chrome DevTools Protocol.
+//
+// A Client is usually obtained from a running session with
+// selenium.WebDriver.CDP, which connects to the browser started by
+// ChromeDriver. Connect and Dial can be used with any browser that exposes
+// a remote debugging port.
+//
+// Commands are sent with Call, or with the typed helpers of the Network,
+// Page, Runtime and Emulation domains. Events are received by subscribing to
+// them:
+//
+//	sub := client.Subscribe(cdp.EventNetworkResponseReceived)
+//	defer sub.Close()
+//	if err := client.Network().Enable(ctx); err != nil { ... }
+//	for ev := range sub.C {
+//		var resp cdp.ResponseReceived
+//		if err := ev.Decode(&resp); err != nil { ... }
+//	}
+//
+// See https://chromedevtools.github.io/devtools-protocol/ for the protocol.
+package cdp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/injoyai/selenium/internal/websocket"
+	"github.com/injoyai/selenium/internal/wsrpc"
+)
+
+// Error is an error returned by the browser for a command.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Data != "" {
+		return fmt.Sprintf("cdp: %s (%d): %s", e.Message, e.Code, e.Data)
+	}
+	return fmt.Sprintf("cdp: %s (%d)", e.Message, e.Code)
+}
+
+// ErrNoEndpoint is returned when the capabilities of a session advertise no
+// DevTools endpoint, e.g. for browsers other than Chrome and Edge.
+var ErrNoEndpoint = errors.New("cdp: the session capabilities have no DevTools endpoint")
+
+// ErrClosed is returned for commands sent on, or pending when closing, a
+// closed Client.
+var ErrClosed = errors.New("cdp: connection closed")
+
+// Event is an event sent by the browser.
+type Event struct {
+	// Method is the name of the event, e.g. "Network.requestWillBeSent".
+	Method string
+	// Params are the JSON-encoded parameters of the event.
+	Params json.RawMessage
+	// SessionID is the target session that sent the event, if the Client is
+	// connected to the browser target.
+	SessionID string
+}
+
+// Decode decodes the parameters of the event into v.
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Params, v)
+}
+
+// Subscription delivers the events of one or more methods, in the order
+// they are received.
+type Subscription struct {
+	// C receives the events. It is closed when the subscription or the
+	// Client is closed.
+	C <-chan Event
+
+	sub *wsrpc.Subscription
+}
+
+// Close stops the delivery of events and closes C.
+func (s *Subscription) Close() {
+	s.sub.Close()
+}
+
+// EventBufferSize is the capacity of the channel of each subscription. The
+// events that do not fit are queued until they are received, so that a slow
+// reader neither loses events nor blocks the connection; close the
+// subscriptions that are no longer read.
+const EventBufferSize = wsrpc.EventBufferSize
+
+type message struct {
+	ID        int64           `json:"id,omitempty"`
+	SessionID string          `json:"sessionId,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Params    interface{}     `json:"params,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *Error          `json:"error,omitempty"`
+}
+
+type reply struct {
+	ID        int64           `json:"id"`
+	SessionID string          `json:"sessionId"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params"`
+	Result    json.RawMessage `json:"result"`
+	Error     *Error          `json:"error"`
+}
+
+// Client is a connection to a DevTools target. It is safe for concurrent
+// use.
+type Client struct {
+	conn *wsrpc.Conn
+
+	mu sync.Mutex
+	// sessionID is the session of the target that commands are sent to,
+	// when connected to the browser target.
+	sessionID string
+}
+
+// Dial connects to the WebSocket URL of a DevTools target, such as the
+// webSocketDebuggerUrl of an entry of /json/list.
+func Dial(ctx context.Context, wsURL string) (*Client, error) {
+	conn, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cdp: %v", err)
+	}
+	c := new(Client)
+	c.conn = wsrpc.New(conn, c.decode, "cdp", ErrClosed)
+	return c, nil
+}
+
+// Target is a DevTools target, as listed by /json/list.
+type Target struct {
+	ID                   string `json:"id"`
+	Type                 string `json:"type"`
+	Title                string `json:"title"`
+	URL                  string `json:"url"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+}
+
+// Targets lists the targets of the browser whose remote debugging port is
+// at debuggerAddress, e.g. "localhost:9222".
+func Targets(ctx context.Context, debuggerAddress string) ([]Target, error) {
+	var targets []Target
+	if err := getJSON(ctx, debuggerAddress, "/json/list", &targets); err != nil {
+		return nil, err
+	}
+	return targets, nil
+}
+
+// Version describes the browser, as returned by /json/version.
+type Version struct {
+	Browser              string `json:"Browser"`
+	ProtocolVersion      string `json:"Protocol-Version"`
+	UserAgent            string `json:"User-Agent"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+}
+
+// BrowserVersion returns the version of the browser whose remote debugging
+// port is at debuggerAddress.
+func BrowserVersion(ctx context.Context, debuggerAddress string) (*Version, error) {
+	v := new(Version)
+	if err := getJSON(ctx, debuggerAddress, "/json/version", v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func getJSON(ctx context.Context, debuggerAddress, path string, v interface{}) error {
+	addr := debuggerAddress
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(addr, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("cdp: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cdp: GET %s returned %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("cdp: invalid reply to GET %s: %v", path, err)
+	}
+	return nil
+}
+
+// Connect connects to the first page of the browser whose remote debugging
+// port is at debuggerAddress.
+func Connect(ctx context.Context, debuggerAddress string) (*Client, error) {
+	return connect(ctx, debuggerAddress, "")
+}
+
+// connect connects to the target targetID of the browser whose remote
+// debugging port is at debuggerAddress, or to its first page if targetID is
+// empty.
+func connect(ctx context.Context, debuggerAddress, targetID string) (*Client, error) {
+	targets, err := Targets(ctx, debuggerAddress)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		if isTarget(t.ID, t.Type, targetID) && t.WebSocketDebuggerURL != "" {
+			return Dial(ctx, t.WebSocketDebuggerURL)
+		}
+	}
+	return nil, noTarget(targetID)
+}
+
+// ConnectBrowser connects to the browser target at wsURL, such as the
+// "se:cdp" capability of Selenium Grid, and attaches to its first page.
+func ConnectBrowser(ctx context.Context, wsURL string) (*Client, error) {
+	return connectBrowser(ctx, wsURL, "")
+}
+
+// connectBrowser connects to the browser target at wsURL and attaches to
+// its target targetID, or to its first page if targetID is empty.
+func connectBrowser(ctx context.Context, wsURL, targetID string) (*Client, error) {
+	c, err := Dial(ctx, wsURL)
+	if err != nil {
+		return nil, err
+	}
+	var targets struct {
+		TargetInfos []struct {
+			TargetID string `json:"targetId"`
+			Type     string `json:"type"`
+		} `json:"targetInfos"`
+	}
+	if err := c.Call(ctx, "Target.getTargets", nil, &targets); err != nil {
+		c.Close()
+		return nil, err
+	}
+	for _, t := range targets.TargetInfos {
+		if !isTarget(t.TargetID, t.Type, targetID) {
+			continue
+		}
+		var attached struct {
+			SessionID string `json:"sessionId"`
+		}
+		params := map[string]interface{}{"targetId": t.TargetID, "flatten": true}
+		if err := c.Call(ctx, "Target.attachToTarget", params, &attached); err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.mu.Lock()
+		c.sessionID = attached.SessionID
+		c.mu.Unlock()
+		return c, nil
+	}
+	c.Close()
+	return nil, noTarget(targetID)
+}
+
+// isTarget reports whether the target id of type typ is targetID, or is a
+// page if targetID is empty.
+func isTarget(id, typ, targetID string) bool {
+	if targetID == "" {
+		return typ == "page"
+	}
+	return id == targetID
+}
+
+func noTarget(targetID string) error {
+	if targetID == "" {
+		return errors.New("cdp: the browser has no page target")
+	}
+	return fmt.Errorf("cdp: the browser has no target %q", targetID)
+}
+
+// WebSocketURL returns the DevTools endpoint advertised in the capabilities
+// returned by a new session. browser reports whether the endpoint is the
+// browser target, to be used with ConnectBrowser, or the debugger address of
+// the browser, to be used with Connect.
+func WebSocketURL(caps map[string]interface{}) (endpoint string, browser bool, err error) {
+	if u, ok := caps["se:cdp"].(string); ok && u != "" {
+		return u, true, nil
+	}
+	for _, key := range []string{"goog:chromeOptions", "ms:edgeOptions"} {
+		opts, ok := caps[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if addr, ok := opts["debuggerAddress"].(string); ok && addr != "" {
+			return addr, false, nil
+		}
+	}
+	return "", false, ErrNoEndpoint
+}
+
+// ConnectSession connects to the first page of the browser of a session,
+// given the capabilities returned by the new session.
+func ConnectSession(ctx context.Context, caps map[string]interface{}) (*Client, error) {
+	return ConnectSessionTarget(ctx, caps, "")
+}
+
+// ConnectSessionTarget connects to the target targetID, such as the page of a
+// window of ChromeDriver, whose window handles are target IDs, of the browser
+// of a session. It connects to the first page if targetID is empty.
+func ConnectSessionTarget(ctx context.Context, caps map[string]interface{}, targetID string) (*Client, error) {
+	endpoint, browser, err := WebSocketURL(caps)
+	if err != nil {
+		return nil, err
+	}
+	if browser {
+		return connectBrowser(ctx, endpoint, targetID)
+	}
+	return connect(ctx, endpoint, targetID)
+}
+
+// Call sends the command method with params, waits for its reply and
+// decodes the result into result, unless result is nil.
+func (c *Client) Call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	sessionID := c.sessionID
+	c.mu.Unlock()
+	res, err := c.conn.Call(ctx, func(id int64) ([]byte, error) {
+		return json.Marshal(message{ID: id, SessionID: sessionID, Method: method, Params: params})
+	})
+	if err != nil {
+		return err
+	}
+	if result == nil || len(res) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(res, result); err != nil {
+		return fmt.Errorf("cdp: invalid result of %s: %v", method, err)
+	}
+	return nil
+}
+
+// Subscribe returns a subscription to the events named methods, such as
+// EventPageLoadEventFired. Most events are only sent once their domain is
+// enabled.
+func (c *Client) Subscribe(methods ...string) *Subscription {
+	names := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		names[m] = true
+	}
+	sub := c.conn.Subscribe(func(m *wsrpc.Message) bool { return names[m.Method] })
+	ch := make(chan Event, EventBufferSize)
+	go func() {
+		defer close(ch)
+		for {
+			m, ok := sub.Next()
+			if !ok {
+				return
+			}
+			select {
+			case ch <- Event{Method: m.Method, Params: m.Params, SessionID: m.SessionID}:
+			case <-sub.Closing():
+				return
+			}
+		}
+	}()
+	return &Subscription{C: ch, sub: sub}
+}
+
+// Done returns a channel that is closed when the connection is closed.
+func (c *Client) Done() <-chan struct{} {
+	return c.conn.Done()
+}
+
+// Err returns the reason the connection was closed, or nil while it is open.
+func (c *Client) Err() error {
+	return c.conn.Err()
+}
+
+// Close closes the connection. Pending commands fail with ErrClosed.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// decode decodes a message of the browser. The events of the other target
+// sessions are ignored.
+func (c *Client) decode(data []byte) (wsrpc.Message, bool) {
+	var r reply
+	if err := json.Unmarshal(data, &r); err != nil {
+		return wsrpc.Message{}, false
+	}
+	if r.Method == "" {
+		m := wsrpc.Message{ID: r.ID, Result: r.Result}
+		if r.Error != nil {
+			m.Err = r.Error
+		}
+		return m, true
+	}
+	c.mu.Lock()
+	sessionID := c.sessionID
+	c.mu.Unlock()
+	if sessionID != "" && r.SessionID != "" && r.SessionID != sessionID {
+		return wsrpc.Message{}, false
+	}
+	return wsrpc.Message{Method: r.Method, Params: r.Params, SessionID: r.SessionID}, true
+}
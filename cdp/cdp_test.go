@@ -0,0 +1,218 @@
+package cdp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/injoyai/selenium/internal/websocket"
+)
+
+// newDevTools starts a fake DevTools endpoint with a single page target. It
+// answers Runtime.evaluate with the expression, fails unknown methods and
+// sends a Network.requestWillBeSent event after Network.enable. Commands
+// must carry sessionID, if set.
+func newDevTools(t *testing.T, sessionID string) *httptest.Server {
+	var hs *httptest.Server
+	hs = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wsURL := "ws" + strings.TrimPrefix(hs.URL, "http") + "/devtools/page/1"
+		switch r.URL.Path {
+		case "/json/list":
+			fmt.Fprintf(w, `[{"id":"0","type":"service_worker"},{"id":"1","type":"page","url":"about:blank","webSocketDebuggerUrl":%q}]`, wsURL)
+			return
+		case "/json/version":
+			fmt.Fprintf(w, `{"Browser":"Chrome/120.0","webSocketDebuggerUrl":%q}`, wsURL)
+			return
+		}
+
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg struct {
+				ID        int64                  `json:"id"`
+				SessionID string                 `json:"sessionId"`
+				Method    string                 `json:"method"`
+				Params    map[string]interface{} `json:"params"`
+			}
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Errorf("fake DevTools received invalid JSON %q", data)
+				return
+			}
+			reply := map[string]interface{}{"id": msg.ID}
+			var event []byte
+			switch {
+			case msg.Method == "Target.getTargets":
+				reply["result"] = map[string]interface{}{"targetInfos": []map[string]string{{"targetId": "1", "type": "page"}}}
+			case msg.Method == "Target.attachToTarget":
+				reply["result"] = map[string]string{"sessionId": sessionID}
+			case msg.SessionID != sessionID:
+				reply["error"] = map[string]interface{}{"code": -32001, "message": "Session with given id not found."}
+			case msg.Method == "Runtime.evaluate":
+				reply["result"] = map[string]interface{}{"result": map[string]interface{}{"type": "string", "value": msg.Params["expression"]}}
+			case msg.Method == "Network.enable":
+				reply["result"] = map[string]interface{}{}
+				event, _ = json.Marshal(map[string]interface{}{
+					"method":    EventNetworkRequestWillBeSent,
+					"sessionId": sessionID,
+					"params":    map[string]interface{}{"requestId": "r1", "request": map[string]string{"url": "http://example.com/", "method": "GET"}},
+				})
+			default:
+				reply["error"] = map[string]interface{}{"code": -32601, "message": "'" + msg.Method + "' wasn't found"}
+			}
+			data, _ = json.Marshal(reply)
+			if err := conn.WriteMessage(data); err != nil {
+				return
+			}
+			if event != nil {
+				conn.WriteMessage(event)
+			}
+		}
+	}))
+	return hs
+}
+
+func TestConnect(t *testing.T) {
+	hs := newDevTools(t, "")
+	defer hs.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	v, err := BrowserVersion(ctx, strings.TrimPrefix(hs.URL, "http://"))
+	if err != nil || v.Browser != "Chrome/120.0" {
+		t.Fatalf("BrowserVersion() = %+v, %v, want Chrome/120.0", v, err)
+	}
+
+	c, err := Connect(ctx, strings.TrimPrefix(hs.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	defer c.Close()
+	testClient(ctx, t, c)
+}
+
+func TestConnectSession(t *testing.T) {
+	hs := newDevTools(t, "s1")
+	defer hs.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	caps := map[string]interface{}{"se:cdp": "ws" + strings.TrimPrefix(hs.URL, "http") + "/devtools/browser"}
+	c, err := ConnectSession(ctx, caps)
+	if err != nil {
+		t.Fatalf("ConnectSession() returned error: %v", err)
+	}
+	defer c.Close()
+	testClient(ctx, t, c)
+}
+
+func TestConnectSessionTarget(t *testing.T) {
+	hs := newDevTools(t, "s1")
+	defer hs.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, caps := range []map[string]interface{}{
+		{"goog:chromeOptions": map[string]interface{}{"debuggerAddress": strings.TrimPrefix(hs.URL, "http://")}},
+		{"se:cdp": "ws" + strings.TrimPrefix(hs.URL, "http") + "/devtools/browser"},
+	} {
+		sessionID := ""
+		if caps["se:cdp"] != nil {
+			sessionID = "s1"
+		}
+		if _, err := ConnectSessionTarget(ctx, caps, "2"); err == nil {
+			t.Errorf("ConnectSessionTarget(%v) to a missing target returned no error", caps)
+		}
+		c, err := ConnectSessionTarget(ctx, caps, "1")
+		if err != nil {
+			t.Fatalf("ConnectSessionTarget(%v) returned error: %v", caps, err)
+		}
+		if c.sessionID != sessionID {
+			t.Errorf("ConnectSessionTarget(%v) attached to session %q, want %q", caps, c.sessionID, sessionID)
+		}
+		c.Close()
+	}
+}
+
+func testClient(ctx context.Context, t *testing.T, c *Client) {
+	obj, err := c.Runtime().Evaluate(ctx, "1 + 1")
+	if err != nil {
+		t.Fatalf("Evaluate() returned error: %v", err)
+	}
+	var s string
+	if err := obj.Decode(&s); err != nil || s != "1 + 1" {
+		t.Errorf("Evaluate() returned %q, %v, want %q", s, err, "1 + 1")
+	}
+
+	err = c.Call(ctx, "Nope.nothing", nil, nil)
+	var cdpErr *Error
+	if !errors.As(err, &cdpErr) || cdpErr.Code != -32601 {
+		t.Errorf("Call() of an unknown method returned error %v, want code -32601", err)
+	}
+
+	sub := c.Subscribe(EventNetworkRequestWillBeSent)
+	defer sub.Close()
+	if err := c.Network().Enable(ctx); err != nil {
+		t.Fatalf("Network().Enable() returned error: %v", err)
+	}
+	select {
+	case ev := <-sub.C:
+		var req RequestWillBeSent
+		if err := ev.Decode(&req); err != nil || req.Request.URL != "http://example.com/" {
+			t.Errorf("event decoded as %+v, %v, want request to http://example.com/", req, err)
+		}
+	case <-ctx.Done():
+		t.Fatalf("no %s event received", EventNetworkRequestWillBeSent)
+	}
+}
+
+func TestClose(t *testing.T) {
+	hs := newDevTools(t, "")
+	defer hs.Close()
+	ctx := context.Background()
+	c, err := Connect(ctx, hs.URL)
+	if err != nil {
+		t.Fatalf("Connect() returned error: %v", err)
+	}
+	sub := c.Subscribe(EventPageLoadEventFired)
+	c.Close()
+	if _, ok := <-sub.C; ok {
+		t.Errorf("subscription channel not closed by Close()")
+	}
+	if err := c.Call(ctx, "Runtime.enable", nil, nil); err != ErrClosed {
+		t.Errorf("Call() after Close() returned error %v, want %v", err, ErrClosed)
+	}
+}
+
+func TestWebSocketURL(t *testing.T) {
+	tests := []struct {
+		caps    map[string]interface{}
+		want    string
+		browser bool
+	}{
+		{map[string]interface{}{"goog:chromeOptions": map[string]interface{}{"debuggerAddress": "localhost:9222"}}, "localhost:9222", false},
+		{map[string]interface{}{"ms:edgeOptions": map[string]interface{}{"debuggerAddress": "localhost:9223"}}, "localhost:9223", false},
+		{map[string]interface{}{"se:cdp": "ws://grid/session/1/se/cdp", "goog:chromeOptions": map[string]interface{}{"debuggerAddress": "x"}}, "ws://grid/session/1/se/cdp", true},
+	}
+	for _, test := range tests {
+		got, browser, err := WebSocketURL(test.caps)
+		if err != nil || got != test.want || browser != test.browser {
+			t.Errorf("WebSocketURL(%v) = %q, %t, %v, want %q, %t", test.caps, got, browser, err, test.want, test.browser)
+		}
+	}
+	if _, _, err := WebSocketURL(map[string]interface{}{"browserName": "firefox"}); err == nil {
+		t.Errorf("WebSocketURL() without an endpoint did not return an error")
+	}
+}
@@ -0,0 +1,62 @@
+package cdp
+
+import "context"
+
+// Emulation exposes the commands of the Emulation domain.
+type Emulation struct {
+	c *Client
+}
+
+// Emulation returns the commands of the Emulation domain.
+func (c *Client) Emulation() Emulation {
+	return Emulation{c}
+}
+
+// DeviceMetrics describes the screen of an emulated device.
+type DeviceMetrics struct {
+	Width             int     `json:"width"`
+	Height            int     `json:"height"`
+	DeviceScaleFactor float64 `json:"deviceScaleFactor"`
+	Mobile            bool    `json:"mobile"`
+}
+
+// SetDeviceMetricsOverride emulates the screen of a device.
+func (e Emulation) SetDeviceMetricsOverride(ctx context.Context, m DeviceMetrics) error {
+	return e.c.Call(ctx, "Emulation.setDeviceMetricsOverride", m, nil)
+}
+
+// ClearDeviceMetricsOverride restores the screen of the browser.
+func (e Emulation) ClearDeviceMetricsOverride(ctx context.Context) error {
+	return e.c.Call(ctx, "Emulation.clearDeviceMetricsOverride", nil, nil)
+}
+
+// SetUserAgentOverride replaces the user agent of the page.
+func (e Emulation) SetUserAgentOverride(ctx context.Context, userAgent string) error {
+	return e.c.Call(ctx, "Emulation.setUserAgentOverride", map[string]interface{}{"userAgent": userAgent}, nil)
+}
+
+// SetGeolocationOverride sets the position reported by the Geolocation API.
+func (e Emulation) SetGeolocationOverride(ctx context.Context, latitude, longitude, accuracy float64) error {
+	return e.c.Call(ctx, "Emulation.setGeolocationOverride", map[string]interface{}{
+		"latitude":  latitude,
+		"longitude": longitude,
+		"accuracy":  accuracy,
+	}, nil)
+}
+
+// ClearGeolocationOverride restores the position of the browser.
+func (e Emulation) ClearGeolocationOverride(ctx context.Context) error {
+	return e.c.Call(ctx, "Emulation.clearGeolocationOverride", nil, nil)
+}
+
+// SetTimezoneOverride sets the time zone of the page, e.g. "Europe/Paris".
+// An empty time zone restores the one of the browser.
+func (e Emulation) SetTimezoneOverride(ctx context.Context, timezoneID string) error {
+	return e.c.Call(ctx, "Emulation.setTimezoneOverride", map[string]interface{}{"timezoneId": timezoneID}, nil)
+}
+
+// SetEmulatedMedia emulates a CSS media type, such as "print". An empty
+// media type disables the emulation.
+func (e Emulation) SetEmulatedMedia(ctx context.Context, media string) error {
+	return e.c.Call(ctx, "Emulation.setEmulatedMedia", map[string]interface{}{"media": media}, nil)
+}
@@ -0,0 +1,123 @@
+package cdp
+
+import (
+	"context"
+	"encoding/base64"
+)
+
+// EventFetchRequestPaused is the event of the Fetch domain.
+const EventFetchRequestPaused = "Fetch.requestPaused"
+
+// Fetch exposes the commands of the Fetch domain, which intercepts the
+// requests of the page.
+type Fetch struct {
+	c *Client
+}
+
+// Fetch returns the commands of the Fetch domain.
+func (c *Client) Fetch() Fetch {
+	return Fetch{c}
+}
+
+// RequestPattern selects the requests to intercept.
+type RequestPattern struct {
+	// URLPattern matches the URL of the request, where "*" matches any
+	// sequence of characters and "?" any character. Empty matches all.
+	URLPattern string `json:"urlPattern,omitempty"`
+	// ResourceType is e.g. "Document", "Image", "Script" or "XHR".
+	ResourceType string `json:"resourceType,omitempty"`
+	// RequestStage is "Request", the default, or "Response".
+	RequestStage string `json:"requestStage,omitempty"`
+}
+
+// HeaderEntry is a header of a request or response.
+type HeaderEntry struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Enable pauses the requests matching one of patterns, or all the requests
+// if there is none, and sends a EventFetchRequestPaused event for each. A
+// paused request must be resumed with ContinueRequest, FailRequest or
+// FulfillRequest.
+func (f Fetch) Enable(ctx context.Context, patterns []RequestPattern) error {
+	params := map[string]interface{}{}
+	if len(patterns) > 0 {
+		params["patterns"] = patterns
+	}
+	return f.c.Call(ctx, "Fetch.enable", params, nil)
+}
+
+// Disable stops the interception of requests.
+func (f Fetch) Disable(ctx context.Context) error {
+	return f.c.Call(ctx, "Fetch.disable", nil, nil)
+}
+
+// ContinueRequest are the parameters of Fetch.continueRequest. The empty
+// fields keep the values of the original request. Headers, if set, replace
+// all the headers of the request.
+type ContinueRequest struct {
+	RequestID string        `json:"requestId"`
+	URL       string        `json:"url,omitempty"`
+	Method    string        `json:"method,omitempty"`
+	PostData  []byte        `json:"postData,omitempty"`
+	Headers   []HeaderEntry `json:"headers,omitempty"`
+}
+
+// ContinueRequest resumes a paused request, with the changes of p.
+func (f Fetch) ContinueRequest(ctx context.Context, p ContinueRequest) error {
+	return f.c.Call(ctx, "Fetch.continueRequest", p, nil)
+}
+
+// FailRequest fails a paused request with reason, one of the network error
+// reasons such as "Failed", "Aborted", "BlockedByClient" or
+// "ConnectionRefused".
+func (f Fetch) FailRequest(ctx context.Context, requestID, reason string) error {
+	return f.c.Call(ctx, "Fetch.failRequest", map[string]interface{}{"requestId": requestID, "errorReason": reason}, nil)
+}
+
+// FulfillRequest are the parameters of Fetch.fulfillRequest.
+type FulfillRequest struct {
+	RequestID       string        `json:"requestId"`
+	ResponseCode    int           `json:"responseCode"`
+	ResponseHeaders []HeaderEntry `json:"responseHeaders,omitempty"`
+	Body            []byte        `json:"body,omitempty"`
+	ResponsePhrase  string        `json:"responsePhrase,omitempty"`
+}
+
+// FulfillRequest answers a paused request with the response of p, without
+// sending it to the network.
+func (f Fetch) FulfillRequest(ctx context.Context, p FulfillRequest) error {
+	return f.c.Call(ctx, "Fetch.fulfillRequest", p, nil)
+}
+
+// GetResponseBody returns the body of a request paused at the response
+// stage.
+func (f Fetch) GetResponseBody(ctx context.Context, requestID string) ([]byte, error) {
+	var result struct {
+		Body          string `json:"body"`
+		Base64Encoded bool   `json:"base64Encoded"`
+	}
+	if err := f.c.Call(ctx, "Fetch.getResponseBody", map[string]interface{}{"requestId": requestID}, &result); err != nil {
+		return nil, err
+	}
+	if result.Base64Encoded {
+		return base64.StdEncoding.DecodeString(result.Body)
+	}
+	return []byte(result.Body), nil
+}
+
+// RequestPaused is sent for each intercepted request.
+type RequestPaused struct {
+	RequestID    string  `json:"requestId"`
+	Request      Request `json:"request"`
+	FrameID      string  `json:"frameId"`
+	ResourceType string  `json:"resourceType"`
+	// ResponseStatusCode and ResponseHeaders are set for the requests
+	// paused at the response stage.
+	ResponseStatusCode int           `json:"responseStatusCode,omitempty"`
+	ResponseHeaders    []HeaderEntry `json:"responseHeaders,omitempty"`
+	// NetworkID is the ID of the request in the events of the Network
+	// domain, if enabled.
+	NetworkID string `json:"networkId,omitempty"`
+}
@@ -0,0 +1,163 @@
+package cdp
+
+import (
+	"context"
+	"encoding/base64"
+)
+
+// The events of the Network domain.
+const (
+	EventNetworkRequestWillBeSent = "Network.requestWillBeSent"
+	EventNetworkResponseReceived  = "Network.responseReceived"
+	EventNetworkDataReceived      = "Network.dataReceived"
+	EventNetworkLoadingFinished   = "Network.loadingFinished"
+	EventNetworkLoadingFailed     = "Network.loadingFailed"
+)
+
+// Network exposes the commands of the Network domain.
+type Network struct {
+	c *Client
+}
+
+// Network returns the commands of the Network domain.
+func (c *Client) Network() Network {
+	return Network{c}
+}
+
+// Enable starts the delivery of network events.
+func (n Network) Enable(ctx context.Context) error {
+	return n.c.Call(ctx, "Network.enable", nil, nil)
+}
+
+// Disable stops the delivery of network events.
+func (n Network) Disable(ctx context.Context) error {
+	return n.c.Call(ctx, "Network.disable", nil, nil)
+}
+
+// SetExtraHTTPHeaders adds headers to every request of the page.
+func (n Network) SetExtraHTTPHeaders(ctx context.Context, headers map[string]string) error {
+	return n.c.Call(ctx, "Network.setExtraHTTPHeaders", map[string]interface{}{"headers": headers}, nil)
+}
+
+// SetBlockedURLs blocks the requests whose URL matches one of patterns,
+// where "*" matches any sequence of characters.
+func (n Network) SetBlockedURLs(ctx context.Context, patterns []string) error {
+	return n.c.Call(ctx, "Network.setBlockedURLs", map[string]interface{}{"urls": patterns}, nil)
+}
+
+// SetCacheDisabled disables or enables the cache of the browser.
+func (n Network) SetCacheDisabled(ctx context.Context, disabled bool) error {
+	return n.c.Call(ctx, "Network.setCacheDisabled", map[string]interface{}{"cacheDisabled": disabled}, nil)
+}
+
+// ClearBrowserCookies deletes all the cookies of the browser.
+func (n Network) ClearBrowserCookies(ctx context.Context) error {
+	return n.c.Call(ctx, "Network.clearBrowserCookies", nil, nil)
+}
+
+// GetResponseBody returns the body of the response to the request with ID
+// requestID. The domain must have been enabled before the request was sent.
+func (n Network) GetResponseBody(ctx context.Context, requestID string) ([]byte, error) {
+	var result struct {
+		Body          string `json:"body"`
+		Base64Encoded bool   `json:"base64Encoded"`
+	}
+	if err := n.c.Call(ctx, "Network.getResponseBody", map[string]interface{}{"requestId": requestID}, &result); err != nil {
+		return nil, err
+	}
+	if result.Base64Encoded {
+		return base64.StdEncoding.DecodeString(result.Body)
+	}
+	return []byte(result.Body), nil
+}
+
+// Request is an HTTP request.
+type Request struct {
+	URL      string            `json:"url"`
+	Method   string            `json:"method"`
+	Headers  map[string]string `json:"headers"`
+	PostData string            `json:"postData,omitempty"`
+}
+
+// ResourceTiming is the timing of a request, in milliseconds relative to
+// RequestTime, which is in seconds. Phases that did not happen are -1.
+type ResourceTiming struct {
+	RequestTime       float64 `json:"requestTime"`
+	DNSStart          float64 `json:"dnsStart"`
+	DNSEnd            float64 `json:"dnsEnd"`
+	ConnectStart      float64 `json:"connectStart"`
+	ConnectEnd        float64 `json:"connectEnd"`
+	SSLStart          float64 `json:"sslStart"`
+	SSLEnd            float64 `json:"sslEnd"`
+	SendStart         float64 `json:"sendStart"`
+	SendEnd           float64 `json:"sendEnd"`
+	ReceiveHeadersEnd float64 `json:"receiveHeadersEnd"`
+}
+
+// Response is an HTTP response.
+type Response struct {
+	URL               string            `json:"url"`
+	Status            int               `json:"status"`
+	StatusText        string            `json:"statusText"`
+	Headers           map[string]string `json:"headers"`
+	RequestHeaders    map[string]string `json:"requestHeaders,omitempty"`
+	MimeType          string            `json:"mimeType"`
+	Protocol          string            `json:"protocol,omitempty"`
+	RemoteIPAddress   string            `json:"remoteIPAddress,omitempty"`
+	RemotePort        int               `json:"remotePort,omitempty"`
+	FromDiskCache     bool              `json:"fromDiskCache,omitempty"`
+	EncodedDataLength float64           `json:"encodedDataLength"`
+	Timing            *ResourceTiming   `json:"timing,omitempty"`
+}
+
+// RequestWillBeSent is sent when the page is about to send a request.
+type RequestWillBeSent struct {
+	RequestID   string  `json:"requestId"`
+	LoaderID    string  `json:"loaderId"`
+	FrameID     string  `json:"frameId,omitempty"`
+	DocumentURL string  `json:"documentURL"`
+	Request     Request `json:"request"`
+	// Timestamp is a monotonic time in seconds.
+	Timestamp float64 `json:"timestamp"`
+	// WallTime is the time in seconds since the Unix epoch.
+	WallTime float64 `json:"wallTime"`
+	Type     string  `json:"type"`
+	// RedirectResponse is the response that redirected to this request.
+	RedirectResponse *Response `json:"redirectResponse,omitempty"`
+}
+
+// ResponseReceived is sent when the headers of a response are received.
+type ResponseReceived struct {
+	RequestID string   `json:"requestId"`
+	LoaderID  string   `json:"loaderId"`
+	Timestamp float64  `json:"timestamp"`
+	Type      string   `json:"type"`
+	Response  Response `json:"response"`
+}
+
+// DataReceived is sent when a chunk of the body of a response is received.
+type DataReceived struct {
+	RequestID string  `json:"requestId"`
+	Timestamp float64 `json:"timestamp"`
+	// DataLength is the length of the chunk once decoded.
+	DataLength int `json:"dataLength"`
+	// EncodedDataLength is the number of bytes received for the chunk.
+	EncodedDataLength int `json:"encodedDataLength"`
+}
+
+// LoadingFinished is sent when a response is fully received.
+type LoadingFinished struct {
+	RequestID         string  `json:"requestId"`
+	Timestamp         float64 `json:"timestamp"`
+	EncodedDataLength float64 `json:"encodedDataLength"`
+}
+
+// LoadingFailed is sent when a request fails.
+type LoadingFailed struct {
+	RequestID     string  `json:"requestId"`
+	Timestamp     float64 `json:"timestamp"`
+	Type          string  `json:"type"`
+	ErrorText     string  `json:"errorText"`
+	Canceled      bool    `json:"canceled,omitempty"`
+	BlockedReason string  `json:"blockedReason,omitempty"`
+}
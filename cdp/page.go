@@ -0,0 +1,110 @@
+package cdp
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+)
+
+// The events of the Page domain.
+const (
+	EventPageLoadEventFired       = "Page.loadEventFired"
+	EventPageDOMContentEventFired = "Page.domContentEventFired"
+	EventPageFrameNavigated       = "Page.frameNavigated"
+)
+
+// Page exposes the commands of the Page domain.
+type Page struct {
+	c *Client
+}
+
+// Page returns the commands of the Page domain.
+func (c *Client) Page() Page {
+	return Page{c}
+}
+
+// Enable starts the delivery of page events.
+func (p Page) Enable(ctx context.Context) error {
+	return p.c.Call(ctx, "Page.enable", nil, nil)
+}
+
+// Disable stops the delivery of page events.
+func (p Page) Disable(ctx context.Context) error {
+	return p.c.Call(ctx, "Page.disable", nil, nil)
+}
+
+// Navigate loads url in the page and returns the ID of its frame. It does
+// not wait for the page to load.
+func (p Page) Navigate(ctx context.Context, url string) (string, error) {
+	var result struct {
+		FrameID   string `json:"frameId"`
+		ErrorText string `json:"errorText"`
+	}
+	if err := p.c.Call(ctx, "Page.navigate", map[string]interface{}{"url": url}, &result); err != nil {
+		return "", err
+	}
+	if result.ErrorText != "" {
+		return result.FrameID, errors.New("cdp: navigation failed: " + result.ErrorText)
+	}
+	return result.FrameID, nil
+}
+
+// Reload reloads the page, bypassing the cache if ignoreCache is set.
+func (p Page) Reload(ctx context.Context, ignoreCache bool) error {
+	return p.c.Call(ctx, "Page.reload", map[string]interface{}{"ignoreCache": ignoreCache}, nil)
+}
+
+// CaptureScreenshot returns a screenshot of the page in format, "png" or
+// "jpeg".
+func (p Page) CaptureScreenshot(ctx context.Context, format string) ([]byte, error) {
+	var result struct {
+		Data string `json:"data"`
+	}
+	if err := p.c.Call(ctx, "Page.captureScreenshot", map[string]interface{}{"format": format}, &result); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(result.Data)
+}
+
+// AddScriptToEvaluateOnNewDocument runs source in every new document before
+// its own scripts, and returns an identifier for
+// RemoveScriptToEvaluateOnNewDocument.
+func (p Page) AddScriptToEvaluateOnNewDocument(ctx context.Context, source string) (string, error) {
+	var result struct {
+		Identifier string `json:"identifier"`
+	}
+	err := p.c.Call(ctx, "Page.addScriptToEvaluateOnNewDocument", map[string]interface{}{"source": source}, &result)
+	return result.Identifier, err
+}
+
+// RemoveScriptToEvaluateOnNewDocument removes a script added with
+// AddScriptToEvaluateOnNewDocument.
+func (p Page) RemoveScriptToEvaluateOnNewDocument(ctx context.Context, identifier string) error {
+	return p.c.Call(ctx, "Page.removeScriptToEvaluateOnNewDocument", map[string]interface{}{"identifier": identifier}, nil)
+}
+
+// Frame is a frame of the page.
+type Frame struct {
+	ID       string `json:"id"`
+	ParentID string `json:"parentId,omitempty"`
+	LoaderID string `json:"loaderId"`
+	Name     string `json:"name,omitempty"`
+	URL      string `json:"url"`
+	MimeType string `json:"mimeType"`
+}
+
+// LoadEventFired is sent when the load event of the page fires.
+type LoadEventFired struct {
+	Timestamp float64 `json:"timestamp"`
+}
+
+// DOMContentEventFired is sent when the DOMContentLoaded event of the page
+// fires.
+type DOMContentEventFired struct {
+	Timestamp float64 `json:"timestamp"`
+}
+
+// FrameNavigated is sent when a frame has navigated to a new document.
+type FrameNavigated struct {
+	Frame Frame `json:"frame"`
+}
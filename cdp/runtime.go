@@ -0,0 +1,135 @@
+package cdp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// The events of the Runtime domain.
+const (
+	EventRuntimeConsoleAPICalled = "Runtime.consoleAPICalled"
+	EventRuntimeExceptionThrown  = "Runtime.exceptionThrown"
+)
+
+// Runtime exposes the commands of the Runtime domain.
+type Runtime struct {
+	c *Client
+}
+
+// Runtime returns the commands of the Runtime domain.
+func (c *Client) Runtime() Runtime {
+	return Runtime{c}
+}
+
+// Enable starts the delivery of runtime events. The console messages logged
+// before are sent as EventRuntimeConsoleAPICalled events.
+func (r Runtime) Enable(ctx context.Context) error {
+	return r.c.Call(ctx, "Runtime.enable", nil, nil)
+}
+
+// Disable stops the delivery of runtime events.
+func (r Runtime) Disable(ctx context.Context) error {
+	return r.c.Call(ctx, "Runtime.disable", nil, nil)
+}
+
+// Evaluate evaluates expression in the page, waits for the promise it
+// returns, if any, and returns its value. An uncaught exception is returned
+// as an *ExceptionDetails error.
+func (r Runtime) Evaluate(ctx context.Context, expression string) (*RemoteObject, error) {
+	var result struct {
+		Result           *RemoteObject     `json:"result"`
+		ExceptionDetails *ExceptionDetails `json:"exceptionDetails"`
+	}
+	params := map[string]interface{}{
+		"expression":    expression,
+		"returnByValue": true,
+		"awaitPromise":  true,
+	}
+	if err := r.c.Call(ctx, "Runtime.evaluate", params, &result); err != nil {
+		return nil, err
+	}
+	if result.ExceptionDetails != nil {
+		return nil, result.ExceptionDetails
+	}
+	return result.Result, nil
+}
+
+// RemoteObject is a JavaScript value.
+type RemoteObject struct {
+	// Type is "object", "function", "undefined", "string", "number",
+	// "boolean", "symbol" or "bigint".
+	Type      string `json:"type"`
+	Subtype   string `json:"subtype,omitempty"`
+	ClassName string `json:"className,omitempty"`
+	// Value is the JSON encoding of primitive values, and of objects
+	// returned by value.
+	Value       json.RawMessage `json:"value,omitempty"`
+	Description string          `json:"description,omitempty"`
+	ObjectID    string          `json:"objectId,omitempty"`
+}
+
+// Decode decodes the value of the object into v.
+func (o *RemoteObject) Decode(v interface{}) error {
+	if len(o.Value) == 0 {
+		return fmt.Errorf("cdp: the %s object has no value", o.Type)
+	}
+	return json.Unmarshal(o.Value, v)
+}
+
+// CallFrame is a frame of a JavaScript stack trace. Lines and columns are
+// 0-based.
+type CallFrame struct {
+	FunctionName string `json:"functionName"`
+	ScriptID     string `json:"scriptId"`
+	URL          string `json:"url"`
+	LineNumber   int    `json:"lineNumber"`
+	ColumnNumber int    `json:"columnNumber"`
+}
+
+// StackTrace is a JavaScript stack trace.
+type StackTrace struct {
+	Description string      `json:"description,omitempty"`
+	CallFrames  []CallFrame `json:"callFrames"`
+}
+
+// ExceptionDetails describes an uncaught exception. Lines and columns are
+// 0-based.
+type ExceptionDetails struct {
+	ExceptionID  int           `json:"exceptionId"`
+	Text         string        `json:"text"`
+	LineNumber   int           `json:"lineNumber"`
+	ColumnNumber int           `json:"columnNumber"`
+	ScriptID     string        `json:"scriptId,omitempty"`
+	URL          string        `json:"url,omitempty"`
+	StackTrace   *StackTrace   `json:"stackTrace,omitempty"`
+	Exception    *RemoteObject `json:"exception,omitempty"`
+}
+
+// Error implements the error interface.
+func (e *ExceptionDetails) Error() string {
+	msg := e.Text
+	if e.Exception != nil && e.Exception.Description != "" {
+		msg = e.Exception.Description
+	}
+	return fmt.Sprintf("cdp: %s at %s:%d:%d", msg, e.URL, e.LineNumber+1, e.ColumnNumber+1)
+}
+
+// ConsoleAPICalled is sent when the page calls a console method.
+type ConsoleAPICalled struct {
+	// Type is the console method, e.g. "log", "warning", "error" or
+	// "assert".
+	Type               string         `json:"type"`
+	Args               []RemoteObject `json:"args"`
+	ExecutionContextID int            `json:"executionContextId"`
+	// Timestamp is the time in milliseconds since the Unix epoch.
+	Timestamp  float64     `json:"timestamp"`
+	StackTrace *StackTrace `json:"stackTrace,omitempty"`
+}
+
+// ExceptionThrown is sent for exceptions that are not caught by the page.
+type ExceptionThrown struct {
+	// Timestamp is the time in milliseconds since the Unix epoch.
+	Timestamp        float64          `json:"timestamp"`
+	ExceptionDetails ExceptionDetails `json:"exceptionDetails"`
+}
@@ -25,7 +25,7 @@ const (
 )
 
 // HTTPClient is the default client to use to communicate with the WebDriver
-// server.
+// server. Use WithHTTPClient to configure the client of a single WebDriver.
 var HTTPClient = &http.Client{}
 
 func init() {
@@ -0,0 +1,346 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/injoyai/selenium/cdp"
+	"github.com/injoyai/selenium/log"
+)
+
+// ConsoleMessage is a console message or an uncaught exception of the page.
+type ConsoleMessage struct {
+	Timestamp time.Time
+	// Level is log.Severe, log.Warning, log.Info or log.Debug.
+	Level log.Level
+	// Type is the console method, e.g. "log", "warning", "error", "debug" or
+	// "assert", or "exception" for uncaught exceptions.
+	Type string
+	// Source is "console-api" for console messages, "javascript" for
+	// exceptions.
+	Source string
+	Text   string
+	// URL, Line and Column locate the message in the source of the page.
+	// Lines and columns are 1-based, and 0 if unknown.
+	URL    string
+	Line   int
+	Column int
+	// StackTrace is the JavaScript stack, innermost frame first, if known.
+	StackTrace []StackFrame
+}
+
+// Exception reports whether m is an uncaught exception.
+func (m *ConsoleMessage) Exception() bool {
+	return m.Type == "exception"
+}
+
+// StackFrame is a frame of a JavaScript stack trace. Lines and columns are
+// 1-based.
+type StackFrame struct {
+	Function string
+	URL      string
+	Line     int
+	Column   int
+}
+
+// ConsoleCapture delivers the console messages of the page, created by
+// WebDriver.CaptureConsole.
+type ConsoleCapture struct {
+	// C receives the messages. It is closed when the capture stops.
+	C <-chan ConsoleMessage
+
+	c      chan ConsoleMessage
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// consolePollInterval is the interval at which the browser log is read when
+// the browser has no DevTools endpoint.
+var consolePollInterval = time.Second
+
+// CaptureConsole starts delivering the console messages and the uncaught
+// exceptions of the page, as they happen, until Stop is called or the
+// context of wd is done. If levels are given, only the messages of these
+// levels are delivered. For example, to fail a test on any page error:
+//
+//	c, err := wd.CaptureConsole(log.Severe)
+//	...
+//	defer c.Stop()
+//	go func() {
+//		for m := range c.C {
+//			t.Errorf("%s:%d: %s", m.URL, m.Line, m.Text)
+//		}
+//	}()
+//
+// The messages are read from the Runtime domain of the Chrome DevTools
+// Protocol if the browser has a DevTools endpoint (see WebDriver.CDP).
+// If the capabilities of the session advertise no DevTools endpoint, the
+// browser log is polled instead, which requires logging to be enabled in the
+// capabilities of the session, and yields fewer details. No message is
+// dropped: the messages that are not received yet are queued.
+func (wd *WebDriver) CaptureConsole(levels ...log.Level) (*ConsoleCapture, error) {
+	ctx, cancel := context.WithCancel(wd.Context())
+	ch := make(chan ConsoleMessage, cdp.EventBufferSize)
+	c := &ConsoleCapture{C: ch, c: ch, cancel: cancel, done: make(chan struct{})}
+	accept := func(m *ConsoleMessage) bool {
+		if len(levels) == 0 {
+			return true
+		}
+		for _, l := range levels {
+			if l == m.Level {
+				return true
+			}
+		}
+		return false
+	}
+
+	client, err := wd.CDP(ctx)
+	if errors.Is(err, cdp.ErrNoEndpoint) {
+		// Fall back to the browser log, if available.
+		if _, err := wd.WithContext(ctx).Log(log.Browser); err != nil {
+			cancel()
+			return nil, err
+		}
+		go c.poll(ctx, wd.WithContext(ctx), accept)
+		return c, nil
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	sub := client.Subscribe(cdp.EventRuntimeConsoleAPICalled, cdp.EventRuntimeExceptionThrown)
+	if err := client.Runtime().Enable(ctx); err != nil {
+		client.Close()
+		cancel()
+		return nil, err
+	}
+	go c.listen(ctx, client, sub, accept)
+	return c, nil
+}
+
+func (c *ConsoleCapture) listen(ctx context.Context, client *cdp.Client, sub *cdp.Subscription, accept func(*ConsoleMessage) bool) {
+	defer close(c.done)
+	defer close(c.c)
+	defer client.Close()
+	for {
+		var ev cdp.Event
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				c.setErr(client.Err())
+				return
+			}
+			ev = e
+		}
+
+		var m *ConsoleMessage
+		if ev.Method == cdp.EventRuntimeExceptionThrown {
+			var p cdp.ExceptionThrown
+			if err := ev.Decode(&p); err != nil {
+				c.setErr(err)
+				continue
+			}
+			m = exceptionMessage(&p)
+		} else {
+			var p cdp.ConsoleAPICalled
+			if err := ev.Decode(&p); err != nil {
+				c.setErr(err)
+				continue
+			}
+			m = consoleAPIMessage(&p)
+		}
+		if !accept(m) {
+			continue
+		}
+		select {
+		case c.c <- *m:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *ConsoleCapture) poll(ctx context.Context, wd *WebDriver, accept func(*ConsoleMessage) bool) {
+	defer close(c.done)
+	defer close(c.c)
+	t := time.NewTicker(consolePollInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		msgs, err := wd.Log(log.Browser)
+		if err != nil {
+			if ctx.Err() == nil {
+				c.setErr(err)
+			}
+			return
+		}
+		for _, msg := range msgs {
+			m := browserLogMessage(msg)
+			if !accept(m) {
+				continue
+			}
+			select {
+			case c.c <- *m:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func consoleAPIMessage(p *cdp.ConsoleAPICalled) *ConsoleMessage {
+	args := make([]string, len(p.Args))
+	for i := range p.Args {
+		args[i] = remoteObjectText(&p.Args[i])
+	}
+	m := &ConsoleMessage{
+		Timestamp: time.Unix(0, int64(p.Timestamp*float64(time.Millisecond))),
+		Level:     consoleLevel(p.Type),
+		Type:      p.Type,
+		Source:    "console-api",
+		Text:      strings.Join(args, " "),
+	}
+	m.setStackTrace(p.StackTrace)
+	return m
+}
+
+func exceptionMessage(p *cdp.ExceptionThrown) *ConsoleMessage {
+	d := &p.ExceptionDetails
+	m := &ConsoleMessage{
+		Timestamp: time.Unix(0, int64(p.Timestamp*float64(time.Millisecond))),
+		Level:     log.Severe,
+		Type:      "exception",
+		Source:    "javascript",
+		Text:      d.Text,
+		URL:       d.URL,
+		Line:      d.LineNumber + 1,
+		Column:    d.ColumnNumber + 1,
+	}
+	if d.Exception != nil && d.Exception.Description != "" {
+		// The description includes the message of the error.
+		m.Text = d.Text + " " + strings.SplitN(d.Exception.Description, "\n", 2)[0]
+	}
+	m.setStackTrace(d.StackTrace)
+	return m
+}
+
+func (m *ConsoleMessage) setStackTrace(st *cdp.StackTrace) {
+	if st == nil {
+		return
+	}
+	for _, f := range st.CallFrames {
+		m.StackTrace = append(m.StackTrace, StackFrame{
+			Function: f.FunctionName,
+			URL:      f.URL,
+			Line:     f.LineNumber + 1,
+			Column:   f.ColumnNumber + 1,
+		})
+	}
+	if m.URL == "" && len(m.StackTrace) > 0 {
+		m.URL, m.Line, m.Column = m.StackTrace[0].URL, m.StackTrace[0].Line, m.StackTrace[0].Column
+	}
+}
+
+// remoteObjectText formats a console argument like the console of the
+// browser does, roughly.
+func remoteObjectText(o *cdp.RemoteObject) string {
+	if len(o.Value) > 0 {
+		var s string
+		if err := json.Unmarshal(o.Value, &s); err == nil {
+			return s
+		}
+		return string(o.Value)
+	}
+	if o.Description != "" {
+		return o.Description
+	}
+	return o.Type
+}
+
+func consoleLevel(typ string) log.Level {
+	switch typ {
+	case "error", "assert":
+		return log.Severe
+	case "warning":
+		return log.Warning
+	case "debug", "trace":
+		return log.Debug
+	}
+	return log.Info
+}
+
+// browserLogLine matches the messages of the browser log of ChromeDriver,
+// e.g. `http://example.com/app.js 12:7 "text"`.
+var browserLogLine = regexp.MustCompile(`^(\S+) (\d+):(\d+) (.*)$`)
+
+func browserLogMessage(msg log.Message) *ConsoleMessage {
+	m := &ConsoleMessage{
+		Timestamp: msg.Timestamp,
+		Level:     msg.Level,
+		Source:    "console-api",
+		Text:      msg.Message,
+	}
+	if sm := browserLogLine.FindStringSubmatch(msg.Message); sm != nil {
+		m.URL = sm[1]
+		m.Line, _ = strconv.Atoi(sm[2])
+		m.Column, _ = strconv.Atoi(sm[3])
+		m.Text = sm[4]
+	}
+	if s, err := strconv.Unquote(m.Text); err == nil {
+		m.Text = s
+	} else if strings.HasPrefix(m.Text, "Uncaught") {
+		m.Type = "exception"
+		m.Source = "javascript"
+	}
+	if m.Type == "" {
+		switch m.Level {
+		case log.Severe:
+			m.Type = "error"
+		case log.Warning:
+			m.Type = "warning"
+		case log.Debug:
+			m.Type = "debug"
+		default:
+			m.Type = "log"
+		}
+	}
+	return m
+}
+
+func (c *ConsoleCapture) setErr(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// Err returns the first error met by the capture, if any. A message that
+// cannot be decoded is skipped and its error recorded, but the capture goes
+// on; the other errors stop the capture and close C.
+func (c *ConsoleCapture) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Stop stops the capture and closes C.
+func (c *ConsoleCapture) Stop() {
+	c.cancel()
+	<-c.done
+}
@@ -0,0 +1,132 @@
+package selenium
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/injoyai/selenium/log"
+)
+
+func receiveConsole(t *testing.T, c *ConsoleCapture) ConsoleMessage {
+	t.Helper()
+	select {
+	case m, ok := <-c.C:
+		if !ok {
+			t.Fatalf("C closed, Err() = %v", c.Err())
+		}
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no console message received")
+	}
+	return ConsoleMessage{}
+}
+
+func TestCaptureConsole(t *testing.T) {
+	wd := newDevToolsSession(t, func(method string, _ map[string]interface{}, send func(string)) {
+		if method != "Runtime.enable" {
+			return
+		}
+		send(`{"method":"Runtime.consoleAPICalled","params":{"type":"log","timestamp":1600000000000,"args":[{"type":"string","value":"hello"},{"type":"number","value":42}]}}`)
+		send(`{"method":"Runtime.consoleAPICalled","params":{"type":"warning","timestamp":1600000000000,"args":[{"type":"string","value":"careful"}],"stackTrace":{"callFrames":[{"functionName":"f","url":"http://example.com/app.js","lineNumber":9,"columnNumber":4}]}}}`)
+		send(`{"method":"Runtime.exceptionThrown","params":{"timestamp":1600000000000,"exceptionDetails":{"exceptionId":1,"text":"Uncaught","lineNumber":0,"columnNumber":6,"url":"http://example.com/app.js","exception":{"type":"object","subtype":"error","description":"Error: boom\n    at http://example.com/app.js:1:7"}}}}`)
+	})
+	c, err := wd.CaptureConsole(log.Warning, log.Severe)
+	if err != nil {
+		t.Fatalf("CaptureConsole() returned error: %v", err)
+	}
+
+	// The log message is filtered out.
+	m := receiveConsole(t, c)
+	if m.Type != "warning" || m.Level != log.Warning || m.Text != "careful" || m.URL != "http://example.com/app.js" || m.Line != 10 || m.Column != 5 {
+		t.Errorf("first message = %+v, want the warning at app.js:10:5", m)
+	}
+	if len(m.StackTrace) != 1 || m.StackTrace[0].Function != "f" {
+		t.Errorf("first message StackTrace = %+v, want f", m.StackTrace)
+	}
+	m = receiveConsole(t, c)
+	if !m.Exception() || m.Level != log.Severe || m.Text != "Uncaught Error: boom" || m.Line != 1 || m.Column != 7 {
+		t.Errorf("second message = %+v, want the exception at app.js:1:7", m)
+	}
+
+	c.Stop()
+	if _, ok := <-c.C; ok {
+		t.Errorf("C not closed after Stop()")
+	}
+	if err := c.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
+
+func TestCaptureConsolePolling(t *testing.T) {
+	defer func(d time.Duration) { consolePollInterval = d }(consolePollInterval)
+	consolePollInterval = 10 * time.Millisecond
+
+	var logCalls int
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		if !strings.HasSuffix(r.URL.Path, "/log") {
+			fmt.Fprint(w, `{"value":{"sessionId":"1","capabilities":{"browserName":"chrome"}}}`)
+			return
+		}
+		logCalls++
+		if logCalls != 2 {
+			fmt.Fprint(w, `{"value":[]}`)
+			return
+		}
+		fmt.Fprint(w, `{"value":[
+			{"timestamp":1600000000000,"level":"INFO","message":"http://example.com/ 3:12 \"hello\""},
+			{"timestamp":1600000000000,"level":"SEVERE","message":"http://example.com/app.js 1:7 Uncaught Error: boom"}
+		]}`)
+	}))
+	defer hs.Close()
+
+	wd, err := NewRemote(nil, hs.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	c, err := wd.CaptureConsole()
+	if err != nil {
+		t.Fatalf("CaptureConsole() returned error: %v", err)
+	}
+	defer c.Stop()
+
+	m := receiveConsole(t, c)
+	if m.Type != "log" || m.Text != "hello" || m.URL != "http://example.com/" || m.Line != 3 || m.Column != 12 {
+		t.Errorf("first message = %+v, want hello at http://example.com/:3:12", m)
+	}
+	m = receiveConsole(t, c)
+	if !m.Exception() || m.Level != log.Severe || m.Text != "Uncaught Error: boom" {
+		t.Errorf("second message = %+v, want the uncaught exception", m)
+	}
+}
+
+func TestCaptureConsoleDevToolsError(t *testing.T) {
+	// The DevTools endpoint is advertised but unreachable.
+	devtools := httptest.NewServer(http.NotFoundHandler())
+	devtools.Close()
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/window"):
+			fmt.Fprint(w, `{"value":"1"}`)
+		case strings.HasSuffix(r.URL.Path, "/log"):
+			fmt.Fprint(w, `{"value":[]}`)
+		default:
+			fmt.Fprintf(w, `{"value":{"sessionId":"1","capabilities":{"browserName":"chrome","goog:chromeOptions":{"debuggerAddress":%q}}}}`, strings.TrimPrefix(devtools.URL, "http://"))
+		}
+	}))
+	defer hs.Close()
+
+	wd, err := NewRemote(nil, hs.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	if c, err := wd.CaptureConsole(); err == nil {
+		c.Stop()
+		t.Fatalf("CaptureConsole() with an unreachable DevTools endpoint returned no error")
+	}
+}
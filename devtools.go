@@ -0,0 +1,34 @@
+package selenium
+
+import (
+	"context"
+	"strings"
+
+	"github.com/injoyai/selenium/cdp"
+)
+
+// CDP connects to the Chrome DevTools Protocol endpoint of the browser of the
+// session, and attaches to the page of the current window. The endpoint is
+// found in the capabilities returned by the remote end: "goog:chromeOptions"
+// (or "ms:edgeOptions") "debuggerAddress" for ChromeDriver, or "se:cdp" for
+// Selenium Grid. The caller should close the client when finished; it stays
+// attached to the same page if another window is selected.
+func (wd *WebDriver) CDP(ctx context.Context) (*cdp.Client, error) {
+	caps := wd.sessionCapabilities
+	if caps == nil {
+		var err error
+		if caps, err = wd.WithContext(ctx).Capabilities(); err != nil {
+			return nil, err
+		}
+	}
+	if _, _, err := cdp.WebSocketURL(caps); err != nil {
+		return nil, err
+	}
+	handle, err := wd.WithContext(ctx).CurrentWindowHandle()
+	if err != nil {
+		return nil, err
+	}
+	// The window handles of ChromeDriver are the IDs of the page targets,
+	// with a "CDwindow-" prefix before version 75.
+	return cdp.ConnectSessionTarget(ctx, caps, strings.TrimPrefix(handle, "CDwindow-"))
+}
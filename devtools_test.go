@@ -0,0 +1,106 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/injoyai/selenium/internal/websocket"
+)
+
+// devToolsHandler is called by the fake DevTools server for each command,
+// once it is acknowledged. send sends a message, such as an event.
+type devToolsHandler func(method string, params map[string]interface{}, send func(msg string))
+
+// newDevToolsSession starts a fake DevTools server with two pages, the second
+// of which is the current window. It acknowledges every command and passes
+// it to handle, if not nil. It returns a session whose capabilities advertise
+// the server as the debugger address of ChromeDriver. The servers are closed
+// when the test ends.
+func newDevToolsSession(t *testing.T, handle devToolsHandler) *WebDriver {
+	t.Helper()
+	var devtools *httptest.Server
+	devtools = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/json/list" {
+			fmt.Fprintf(w, `[{"id":"other","type":"page","webSocketDebuggerUrl":"ws%[1]s/devtools/page/other"},{"id":"1","type":"page","webSocketDebuggerUrl":"ws%[1]s/devtools/page/1"}]`, strings.TrimPrefix(devtools.URL, "http"))
+			return
+		}
+		if r.URL.Path != "/devtools/page/1" {
+			t.Errorf("connected to %s, want the page of the current window", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		send := func(msg string) { conn.WriteMessage([]byte(msg)) }
+		for {
+			data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg struct {
+				ID     int64                  `json:"id"`
+				Method string                 `json:"method"`
+				Params map[string]interface{} `json:"params"`
+			}
+			json.Unmarshal(data, &msg)
+			send(fmt.Sprintf(`{"id":%d,"result":{}}`, msg.ID))
+			if handle != nil {
+				handle(msg.Method, msg.Params, send)
+			}
+		}
+	}))
+	t.Cleanup(devtools.Close)
+
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		if strings.HasSuffix(r.URL.Path, "/window") {
+			io.WriteString(w, `{"value":"CDwindow-1"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"value":{"sessionId":"1","capabilities":{"browserName":"chrome","goog:chromeOptions":{"debuggerAddress":%q}}}}`, strings.TrimPrefix(devtools.URL, "http://"))
+	}))
+	t.Cleanup(hs.Close)
+
+	wd, err := NewRemote(nil, hs.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	return wd
+}
+
+func TestCDP(t *testing.T) {
+	wd := newDevToolsSession(t, nil)
+	c, err := wd.CDP(context.Background())
+	if err != nil {
+		t.Fatalf("CDP() returned error: %v", err)
+	}
+	defer c.Close()
+	if err := c.Page().Enable(context.Background()); err != nil {
+		t.Fatalf("Page().Enable() returned error: %v", err)
+	}
+}
+
+func TestCDPWithoutEndpoint(t *testing.T) {
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		io.WriteString(w, `{"value":{"sessionId":"1","capabilities":{"browserName":"firefox"}}}`)
+	}))
+	defer hs.Close()
+
+	wd, err := NewRemote(nil, hs.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	if _, err := wd.CDP(context.Background()); err == nil {
+		t.Fatalf("CDP() for a session without a DevTools endpoint did not return an error")
+	}
+}
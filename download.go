@@ -0,0 +1,246 @@
+package selenium
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/injoyai/selenium/chrome"
+	"github.com/injoyai/selenium/firefox"
+)
+
+// downloadsEnabled is the capability that makes a Selenium Grid keep the
+// downloads of a session.
+const downloadsEnabled = "se:downloadsEnabled"
+
+// SetDownloadDir makes the browser save the downloads in dir, an absolute
+// path on the machine of the browser, without asking. It sets the
+// preferences of Firefox if the browser name is "firefox", and of Chrome
+// otherwise; call it after AddChrome or AddFirefox, whose other preferences
+// are kept.
+func (c Capabilities) SetDownloadDir(dir string) {
+	if c["browserName"] == "firefox" {
+		f, _ := c[firefox.CapabilitiesKey].(firefox.Capabilities)
+		f.Prefs = mergePrefs(f.Prefs, firefoxDownloadPrefs(dir))
+		c.AddFirefox(f)
+		return
+	}
+	f, _ := c[chrome.CapabilitiesKey].(chrome.Capabilities)
+	f.Prefs = mergePrefs(f.Prefs, chromeDownloadPrefs(dir))
+	c.AddChrome(f)
+}
+
+// EnableDownloads makes a Selenium Grid keep the files that the session
+// downloads, for WaitForDownload, DownloadableFiles and DownloadFile.
+func (c Capabilities) EnableDownloads() {
+	c[downloadsEnabled] = true
+}
+
+func chromeDownloadPrefs(dir string) map[string]interface{} {
+	return map[string]interface{}{
+		"download.default_directory":   dir,
+		"download.prompt_for_download": false,
+		"download.directory_upgrade":   true,
+	}
+}
+
+func firefoxDownloadPrefs(dir string) map[string]interface{} {
+	return map[string]interface{}{
+		"browser.download.dir":                                  dir,
+		"browser.download.folderList":                           2, // the directory of browser.download.dir
+		"browser.download.useDownloadDir":                       true,
+		"browser.download.always_ask_before_handling_new_types": false,
+		"browser.download.manager.showWhenStarting":             false,
+		"browser.helperApps.neverAsk.saveToDisk":                "application/octet-stream,application/pdf,application/zip,text/csv,application/vnd.ms-excel,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"pdfjs.disabled":                                        true, // save the PDF files instead of opening them
+	}
+}
+
+// mergePrefs returns a copy of prefs with the preferences of add.
+func mergePrefs(prefs, add map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(prefs)+len(add))
+	for k, v := range prefs {
+		merged[k] = v
+	}
+	for k, v := range add {
+		merged[k] = v
+	}
+	return merged
+}
+
+// WithDownloadDir sets the local directory in which WaitForDownload looks for
+// the downloads, e.g. a directory shared with the machine of the browser. It
+// defaults to the directory set by Capabilities.SetDownloadDir.
+func WithDownloadDir(dir string) RemoteOption {
+	return func(wd *WebDriver) error {
+		wd.downloadDir = dir
+		return nil
+	}
+}
+
+// WaitForDownload waits until the directory of the downloads has a complete
+// file whose name matches pattern, as defined by filepath.Match, and returns
+// the path of the file. The files being downloaded, such as the .crdownload
+// files of Chrome and the .part files of Firefox, are ignored, but the files
+// downloaded before are not: use an empty directory for each session.
+//
+// If the session was created by a Selenium Grid with EnableDownloads, the
+// file is fetched from the Grid into the directory set by WithDownloadDir, or
+// into a new temporary directory that the caller should remove.
+func (wd *WebDriver) WaitForDownload(ctx context.Context, pattern string) (string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return "", err
+	}
+	grid := wd.sessionCapabilities[downloadsEnabled] == true
+	dir := wd.downloadDir
+	if dir == "" && !grid {
+		if dir = downloadDirOf(wd.capabilities); dir == "" {
+			return "", errors.New("selenium: no download directory; use Capabilities.SetDownloadDir or WithDownloadDir")
+		}
+	}
+
+	for {
+		var names []string
+		if grid {
+			var err error
+			if names, err = wd.DownloadableFiles(); err != nil {
+				return "", err
+			}
+		} else {
+			entries, err := os.ReadDir(dir)
+			if err != nil && !os.IsNotExist(err) {
+				return "", err
+			}
+			for _, e := range entries {
+				if e.Type().IsRegular() {
+					names = append(names, e.Name())
+				}
+			}
+		}
+		if name, ok := completedDownload(names, pattern); ok {
+			if !grid {
+				return filepath.Join(dir, name), nil
+			}
+			return wd.saveDownload(dir, name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("selenium: waiting for a download matching %q: %w", pattern, ctx.Err())
+		case <-time.After(DefaultWaitInterval):
+		}
+	}
+}
+
+// completedDownload returns the first name, in lexical order, that matches
+// pattern and is not a file being downloaded.
+func completedDownload(names []string, pattern string) (string, bool) {
+	present := make(map[string]bool, len(names))
+	for _, name := range names {
+		present[name] = true
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch ext := strings.ToLower(filepath.Ext(name)); {
+		case ext == ".crdownload" || ext == ".part" || strings.HasPrefix(name, "."):
+			continue
+		case present[name+".part"]:
+			// Firefox creates the file when the download starts.
+			continue
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// downloadDirOf returns the download directory set in caps by
+// SetDownloadDir.
+func downloadDirOf(caps Capabilities) string {
+	var prefs map[string]interface{}
+	var key string
+	switch {
+	case caps[firefox.CapabilitiesKey] != nil:
+		f, _ := caps[firefox.CapabilitiesKey].(firefox.Capabilities)
+		prefs, key = f.Prefs, "browser.download.dir"
+	case caps[chrome.CapabilitiesKey] != nil:
+		f, _ := caps[chrome.CapabilitiesKey].(chrome.Capabilities)
+		prefs, key = f.Prefs, "download.default_directory"
+	}
+	dir, _ := prefs[key].(string)
+	return dir
+}
+
+// saveDownload fetches the file name from the Grid into dir, or into a new
+// temporary directory, and returns its path.
+func (wd *WebDriver) saveDownload(dir, name string) (string, error) {
+	content, err := wd.DownloadFile(name)
+	if err != nil {
+		return "", err
+	}
+	if dir == "" {
+		if dir, err = os.MkdirTemp("", "selenium-downloads-"); err != nil {
+			return "", err
+		}
+	}
+	path := filepath.Join(dir, filepath.Base(name))
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// DownloadableFiles returns the names of the files downloaded by the session
+// that a Selenium Grid keeps; see Capabilities.EnableDownloads.
+func (wd *WebDriver) DownloadableFiles() ([]string, error) {
+	var reply struct{ Names []string }
+	if err := wd.valueCommand(getDownloadableFiles, nil, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Names, nil
+}
+
+// DownloadFile returns the content of the file name downloaded by the
+// session, fetched from a Selenium Grid; see Capabilities.EnableDownloads.
+func (wd *WebDriver) DownloadFile(name string) ([]byte, error) {
+	var reply struct{ Contents string }
+	if err := wd.valueCommand(downloadFile, map[string]string{"name": name}, &reply); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(reply.Contents)
+	if err != nil {
+		return nil, err
+	}
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range r.File {
+		if filepath.Base(f.Name) != filepath.Base(name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("selenium: the archive of %q does not contain it", name)
+}
+
+// DeleteDownloadableFiles deletes the files downloaded by the session from a
+// Selenium Grid.
+func (wd *WebDriver) DeleteDownloadableFiles() error {
+	return wd.voidCommand(deleteDownloadableFiles, nil)
+}
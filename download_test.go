@@ -0,0 +1,130 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/injoyai/selenium/chrome"
+	"github.com/injoyai/selenium/firefox"
+)
+
+func TestSetDownloadDir(t *testing.T) {
+	caps := Capabilities{"browserName": "chrome"}
+	caps.AddChrome(chrome.Capabilities{Prefs: map[string]interface{}{"intl.accept_languages": "fr"}})
+	caps.SetDownloadDir("/tmp/downloads")
+	prefs := caps[chrome.CapabilitiesKey].(chrome.Capabilities).Prefs
+	if prefs["intl.accept_languages"] != "fr" || prefs["download.prompt_for_download"] != false {
+		t.Errorf("SetDownloadDir() set the Chrome preferences %v", prefs)
+	}
+	if got := downloadDirOf(caps); got != "/tmp/downloads" {
+		t.Errorf("downloadDirOf(Chrome) = %q, want %q", got, "/tmp/downloads")
+	}
+
+	caps = Capabilities{"browserName": "firefox"}
+	caps.SetDownloadDir("/tmp/downloads")
+	prefs = caps[firefox.CapabilitiesKey].(firefox.Capabilities).Prefs
+	if prefs["browser.download.folderList"] != 2 {
+		t.Errorf("SetDownloadDir() set the Firefox preferences %v", prefs)
+	}
+	if _, ok := caps[chrome.CapabilitiesKey]; ok {
+		t.Errorf("SetDownloadDir() set Chrome options for Firefox")
+	}
+	if got := downloadDirOf(caps); got != "/tmp/downloads" {
+		t.Errorf("downloadDirOf(Firefox) = %q, want %q", got, "/tmp/downloads")
+	}
+}
+
+func TestCompletedDownload(t *testing.T) {
+	for _, tc := range []struct {
+		names   []string
+		pattern string
+		want    string
+	}{
+		{[]string{"report.csv"}, "*.csv", "report.csv"},
+		{[]string{"Unconfirmed 1234.crdownload"}, "*", ""},
+		{[]string{"report.csv", "report.csv.part"}, "*.csv", ""},
+		{[]string{"report.csv.part"}, "*", ""},
+		{[]string{".com.google.Chrome.x1", "b.pdf", "a.pdf"}, "*", "a.pdf"},
+		{[]string{"report.csv"}, "*.pdf", ""},
+	} {
+		got, ok := completedDownload(tc.names, tc.pattern)
+		if got != tc.want || ok != (tc.want != "") {
+			t.Errorf("completedDownload(%q, %q) = %q, %t, want %q", tc.names, tc.pattern, got, ok, tc.want)
+		}
+	}
+}
+
+func TestWaitForDownload(t *testing.T) {
+	dir := t.TempDir()
+	caps := Capabilities{"browserName": "chrome"}
+	caps.SetDownloadDir(dir)
+	wd, _ := newFakeSession(t, caps, nil)
+
+	partial := filepath.Join(dir, "Unconfirmed 1.crdownload")
+	if err := os.WriteFile(partial, []byte("a,b"), 0644); err != nil {
+		t.Fatalf("WriteFile() returned error: %v", err)
+	}
+	go func() {
+		time.Sleep(3 * DefaultWaitInterval)
+		os.Rename(partial, filepath.Join(dir, "report.csv"))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	path, err := wd.WaitForDownload(ctx, "*.csv")
+	if err != nil {
+		t.Fatalf("WaitForDownload() returned error: %v", err)
+	}
+	if want := filepath.Join(dir, "report.csv"); path != want {
+		t.Errorf("WaitForDownload() = %q, want %q", path, want)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 2*DefaultWaitInterval)
+	defer cancel()
+	if _, err := wd.WaitForDownload(ctx, "*.pdf"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForDownload() of a missing file returned %v, want %v", err, context.DeadlineExceeded)
+	}
+	if _, err := wd.WaitForDownload(context.Background(), "["); err == nil {
+		t.Errorf("WaitForDownload() of an invalid pattern returned no error")
+	}
+}
+
+func TestWaitForDownloadGrid(t *testing.T) {
+	caps := Capabilities{"browserName": "chrome"}
+	caps.EnableDownloads()
+	dir := t.TempDir()
+	wd, s := newFakeSession(t, caps, nil, WithDownloadDir(dir))
+
+	s.SetDownload("report.csv.crdownload", []byte("a,"))
+	go func() {
+		time.Sleep(3 * DefaultWaitInterval)
+		s.RemoveDownload("report.csv.crdownload")
+		s.SetDownload("report.csv", []byte("a,b"))
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	path, err := wd.WaitForDownload(ctx, "report.*")
+	if err != nil {
+		t.Fatalf("WaitForDownload() returned error: %v", err)
+	}
+	if want := filepath.Join(dir, "report.csv"); path != want {
+		t.Errorf("WaitForDownload() = %q, want %q", path, want)
+	}
+	if content, err := os.ReadFile(path); err != nil || string(content) != "a,b" {
+		t.Errorf("the downloaded file has %q, %v, want %q", content, err, "a,b")
+	}
+
+	if err := wd.DeleteDownloadableFiles(); err != nil {
+		t.Fatalf("DeleteDownloadableFiles() returned error: %v", err)
+	}
+	if names, err := wd.DownloadableFiles(); err != nil || !reflect.DeepEqual(names, []string{}) {
+		t.Errorf("DownloadableFiles() = %q, %v, want none", names, err)
+	}
+	if _, err := wd.DownloadFile("report.csv"); err == nil {
+		t.Errorf("DownloadFile() of a deleted file returned no error")
+	}
+}
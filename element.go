@@ -2,10 +2,11 @@ package selenium
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/injoyai/conv"
 	"github.com/injoyai/goutil/oss"
 	"io"
 )
@@ -19,16 +20,74 @@ type WebElement struct {
 	// that the value is called a "reference". For ease of transition, we store
 	// the "reference" in this now misnamed field.
 	id string
+	// lazy, if set, finds the element instead of id: the elements bound by
+	// Bind are found when first used, and found again when they are stale.
+	lazy *lazyElement
+}
+
+// Context returns the context that bounds the commands issued for this
+// element, which is the context of the WebDriver it was found through.
+func (elem *WebElement) Context() context.Context {
+	return elem.parent.Context()
+}
+
+// WithContext returns a copy of elem whose commands are bound to ctx.
+func (elem *WebElement) WithContext(ctx context.Context) *WebElement {
+	x := *elem
+	x.parent = elem.parent.WithContext(ctx)
+	return &x
+}
+
+// do runs f with the reference of elem. It finds a bound element first, and
+// finds it again if f fails because the element is stale.
+func (elem *WebElement) do(f func(id string) error) error {
+	if elem.lazy == nil {
+		return f(elem.id)
+	}
+	id, err := elem.lazy.ref("")
+	if err != nil {
+		return err
+	}
+	if err = f(id); errors.Is(err, ErrStaleElementReference) {
+		if id, err = elem.lazy.ref(id); err != nil {
+			return err
+		}
+		err = f(id)
+	}
+	return err
+}
+
+// valueCommand, voidCommand and stringCommand perform the command
+// registered under key for elem, whose reference is the first argument.
+func (elem *WebElement) valueCommand(key string, body, value interface{}, args ...string) error {
+	return elem.do(func(id string) error {
+		return elem.parent.valueCommand(key, body, value, append([]string{id}, args...)...)
+	})
+}
+
+func (elem *WebElement) voidCommand(key string, body interface{}) error {
+	return elem.do(func(id string) error {
+		return elem.parent.voidCommand(key, body, id)
+	})
+}
+
+func (elem *WebElement) stringCommand(key string, args ...string) (string, error) {
+	var value *string
+	if err := elem.valueCommand(key, nil, &value, args...); err != nil {
+		return "", err
+	}
+	if value == nil {
+		return "", fmt.Errorf("nil return value")
+	}
+	return *value, nil
 }
 
 func (elem *WebElement) Click() error {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/click", elem.id)
-	return elem.parent.voidCommand(urlTemplate, nil)
+	return elem.voidCommand(isElementClicked, nil)
 }
 
 func (elem *WebElement) SendKeys(keys string) error {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/value", elem.id)
-	return elem.parent.voidCommand(urlTemplate, elem.parent.processKeyString(keys))
+	return elem.voidCommand(setElementValue, elem.parent.processKeyString(keys))
 }
 
 func (wd *WebDriver) processKeyString(keys string) interface{} {
@@ -43,36 +102,55 @@ func (wd *WebDriver) processKeyString(keys string) interface{} {
 }
 
 func (elem *WebElement) TagName() (string, error) {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/name", elem.id)
-	return elem.parent.stringCommand(urlTemplate)
+	return elem.stringCommand(getElementName)
 }
 
 func (elem *WebElement) Text() (string, error) {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/text", elem.id)
-	return elem.parent.stringCommand(urlTemplate)
+	return elem.stringCommand(getElementText)
 }
 
+// Submit submits the form that contains elem, or elem itself if it is a
+// form. The W3C specification has no such command, so a script dispatches
+// the submit event and submits the form on W3C servers.
 func (elem *WebElement) Submit() error {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/submit", elem.id)
-	return elem.parent.voidCommand(urlTemplate, nil)
+	if !elem.parent.w3cCompatible {
+		return elem.voidCommand(legacySubmitElement, nil)
+	}
+	return elem.do(func(id string) error {
+		_, err := elem.parent.ExecuteScript(submitScript, []interface{}{&WebElement{parent: elem.parent, id: id}})
+		return err
+	})
 }
 
+// submitScript submits the form of arguments[0] unless a listener of the
+// submit event cancels it, like Selenium does.
+const submitScript = `var form = arguments[0];
+while (form && form.nodeName.toLowerCase() !== 'form') form = form.parentElement;
+if (!form) throw new Error('the element is not in a form');
+var e = form.ownerDocument.createEvent('Event');
+e.initEvent('submit', true, true);
+if (form.dispatchEvent(e)) HTMLFormElement.prototype.submit.call(form);`
+
 func (elem *WebElement) Clear() error {
-	urlTemplate := fmt.Sprintf("/session/%%s/element/%s/clear", elem.id)
-	return elem.parent.voidCommand(urlTemplate, nil)
+	return elem.voidCommand(setElementClear, nil)
 }
 
 func (elem *WebElement) MoveTo(xOffset, yOffset int) error {
-	return elem.parent.voidCommand("/session/%s/moveto", map[string]interface{}{
-		"element": elem.id,
-		"xoffset": xOffset,
-		"yoffset": yOffset,
+	return elem.do(func(id string) error {
+		return elem.parent.voidCommand(legacyMoveTo, map[string]interface{}{
+			"element": id,
+			"xoffset": xOffset,
+			"yoffset": yOffset,
+		})
 	})
 }
 
 func (elem *WebElement) FindElement(by, value string) (*WebElement, error) {
-	url := fmt.Sprintf("/session/%%s/element/%s/element", elem.id)
-	response, err := elem.parent.find(by, value, "", url)
+	var response []byte
+	err := elem.do(func(id string) (err error) {
+		response, err = elem.parent.find(findElementFromElement, id, by, value)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -81,8 +159,11 @@ func (elem *WebElement) FindElement(by, value string) (*WebElement, error) {
 }
 
 func (elem *WebElement) FindElements(by, value string) ([]*WebElement, error) {
-	url := fmt.Sprintf("/session/%%s/element/%s/element", elem.id)
-	response, err := elem.parent.find(by, value, "s", url)
+	var response []byte
+	err := elem.do(func(id string) (err error) {
+		response, err = elem.parent.find(findElementsFromElement, id, by, value)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -90,34 +171,32 @@ func (elem *WebElement) FindElements(by, value string) ([]*WebElement, error) {
 	return elem.parent.DecodeElements(response)
 }
 
-func (elem *WebElement) boolQuery(urlTemplate string) (bool, error) {
-	return elem.parent.boolCommand(fmt.Sprintf(urlTemplate, elem.id))
+func (elem *WebElement) boolQuery(key string) (bool, error) {
+	var value bool
+	if err := elem.valueCommand(key, nil, &value); err != nil {
+		return false, err
+	}
+	return value, nil
 }
 
 func (elem *WebElement) IsSelected() (bool, error) {
-	return elem.boolQuery("/session/%%s/element/%s/selected")
+	return elem.boolQuery(isElementSelected)
 }
 
 func (elem *WebElement) IsEnabled() (bool, error) {
-	return elem.boolQuery("/session/%%s/element/%s/enabled")
+	return elem.boolQuery(getElementEnabled)
 }
 
 func (elem *WebElement) IsDisplayed() (bool, error) {
-	return elem.boolQuery("/session/%%s/element/%s/displayed")
+	return elem.boolQuery(isElementDisplayed)
 }
 
 func (elem *WebElement) GetProperty(name string) (string, error) {
-	template := "/session/%%s/element/%s/property/%s"
-	urlTemplate := fmt.Sprintf(template, elem.id, name)
-
-	return elem.parent.stringCommand(urlTemplate)
+	return elem.stringCommand(getElementProperty, name)
 }
 
 func (elem *WebElement) GetAttribute(name string) (string, error) {
-	template := "/session/%%s/element/%s/attribute/%s"
-	urlTemplate := fmt.Sprintf(template, elem.id, name)
-
-	return elem.parent.stringCommand(urlTemplate)
+	return elem.stringCommand(getElementAttribute, name)
 }
 
 func round(f float64) int {
@@ -130,20 +209,15 @@ func round(f float64) int {
 	return 0
 }
 
-func (elem *WebElement) location(suffix string) (*Point, error) {
+// location returns the location of the element. legacyKey is the command used
+// by servers that do not implement the W3C specification.
+func (elem *WebElement) location(legacyKey string) (*Point, error) {
 	if !elem.parent.w3cCompatible {
-		wd := elem.parent
-		path := "/session/%s/element/%s/location" + suffix
-		url := wd.requestURL(path, wd.id, elem.id)
-		response, err := wd.execute("GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		reply := new(struct{ Value rect })
-		if err := json.Unmarshal(response, reply); err != nil {
+		reply := new(rect)
+		if err := elem.valueCommand(legacyKey, nil, reply); err != nil {
 			return nil, err
 		}
-		return &Point{round(reply.Value.X), round(reply.Value.Y)}, nil
+		return &Point{round(reply.X), round(reply.Y)}, nil
 	}
 
 	rect, err := elem.rect()
@@ -154,21 +228,17 @@ func (elem *WebElement) location(suffix string) (*Point, error) {
 }
 
 func (elem *WebElement) Location() (*Point, error) {
-	return elem.location("")
+	return elem.location(legacyGetElementLocation)
 }
 
 func (elem *WebElement) LocationInView() (*Point, error) {
-	return elem.location("_in_view")
+	return elem.location(legacyGetElementLocationInView)
 }
 
 func (elem *WebElement) Size() (*Size, error) {
 	if !elem.parent.w3cCompatible {
-		v, err := elem.parent.request(getElementSize, elem.id, "", "", nil)
-		if err != nil {
-			return nil, err
-		}
 		reply := new(rect)
-		if err := json.Unmarshal(conv.Bytes(v), reply); err != nil {
+		if err := elem.valueCommand(getElementSize, nil, reply); err != nil {
 			return nil, err
 		}
 		return &Size{round(reply.Width), round(reply.Height)}, nil
@@ -191,31 +261,33 @@ type rect struct {
 
 // rect implements the "Get Element Rect" method of the W3C standard.
 func (elem *WebElement) rect() (*rect, error) {
-	v, err := elem.parent.request(getElementRect, elem.id, "", "", nil)
-	if err != nil {
-		return nil, err
-	}
 	r := new(rect)
-	if err := json.Unmarshal(conv.Bytes(v), r); err != nil {
+	if err := elem.valueCommand(getElementRect, nil, r); err != nil {
 		return nil, err
 	}
 	return r, nil
 }
 
 func (elem *WebElement) CSSProperty(name string) (string, error) {
-	wd := elem.parent
-	return wd.stringCommand(fmt.Sprintf("/session/%%s/element/%s/css/%s", elem.id, name))
+	return elem.stringCommand(getElementCSSValue, name)
 }
 
 func (elem *WebElement) MarshalJSON() ([]byte, error) {
+	id := elem.id
+	if elem.lazy != nil {
+		var err error
+		if id, err = elem.lazy.ref(""); err != nil {
+			return nil, err
+		}
+	}
 	return json.Marshal(map[string]string{
-		"ELEMENT":            elem.id,
-		webElementIdentifier: elem.id,
+		"ELEMENT":            id,
+		webElementIdentifier: id,
 	})
 }
 
 func (elem *WebElement) Screenshot() ([]byte, error) {
-	data, err := elem.parent.stringCommand(fmt.Sprintf("/session/%%s/element/%s/screenshot", elem.id))
+	data, err := elem.stringCommand(elementScreenshot)
 	if err != nil {
 		return nil, err
 	}
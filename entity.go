@@ -58,6 +58,9 @@ type Entity struct {
 }
 
 func (this *Entity) SetPref(key string, value interface{}) *Entity {
+	if this.Prefs == nil {
+		this.Prefs = make(map[string]interface{})
+	}
 	this.Prefs[key] = value
 	return this
 }
@@ -98,6 +101,14 @@ func (this *Entity) ShowImg(b ...bool) *Entity {
 	return this.SetPref("profile.managed_default_content_settings.images", arg)
 }
 
+// SetDownloadDir 设置下载目录,下载时不再询问,见 WebDriver.WaitForDownload
+func (this *Entity) SetDownloadDir(dir string) *Entity {
+	for k, v := range chromeDownloadPrefs(dir) {
+		this.SetPref(k, v)
+	}
+	return this
+}
+
 // SetBrowser 设置浏览器,目前只测试了chrome
 func (this *Entity) SetBrowser(b string) *Entity {
 	this.browserName = b
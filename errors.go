@@ -0,0 +1,143 @@
+package selenium
+
+// ErrorCode is an error code defined by the W3C WebDriver specification. See
+// https://www.w3.org/TR/webdriver/#errors .
+//
+// The codes are also errors themselves, so that the errors returned by
+// WebDriver commands can be matched with errors.Is:
+//
+//	if errors.Is(err, selenium.ErrNoSuchElement) {
+//		...
+//	}
+type ErrorCode string
+
+// Error implements the error interface.
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+// The error codes defined by the W3C specification.
+const (
+	ErrElementClickIntercepted ErrorCode = "element click intercepted"
+	ErrElementNotInteractable  ErrorCode = "element not interactable"
+	ErrInsecureCertificate     ErrorCode = "insecure certificate"
+	ErrInvalidArgument         ErrorCode = "invalid argument"
+	ErrInvalidCookieDomain     ErrorCode = "invalid cookie domain"
+	ErrInvalidElementState     ErrorCode = "invalid element state"
+	ErrInvalidSelector         ErrorCode = "invalid selector"
+	ErrInvalidSessionID        ErrorCode = "invalid session id"
+	ErrJavascriptError         ErrorCode = "javascript error"
+	ErrMoveTargetOutOfBounds   ErrorCode = "move target out of bounds"
+	ErrNoSuchAlert             ErrorCode = "no such alert"
+	ErrNoSuchCookie            ErrorCode = "no such cookie"
+	ErrNoSuchElement           ErrorCode = "no such element"
+	ErrNoSuchFrame             ErrorCode = "no such frame"
+	ErrNoSuchWindow            ErrorCode = "no such window"
+	ErrNoSuchShadowRoot        ErrorCode = "no such shadow root"
+	ErrScriptTimeout           ErrorCode = "script timeout"
+	ErrSessionNotCreated       ErrorCode = "session not created"
+	ErrStaleElementReference   ErrorCode = "stale element reference"
+	ErrDetachedShadowRoot      ErrorCode = "detached shadow root"
+	ErrTimeout                 ErrorCode = "timeout"
+	ErrUnableToSetCookie       ErrorCode = "unable to set cookie"
+	ErrUnableToCaptureScreen   ErrorCode = "unable to capture screen"
+	ErrUnexpectedAlertOpen     ErrorCode = "unexpected alert open"
+	ErrUnknownCommand          ErrorCode = "unknown command"
+	ErrUnknownError            ErrorCode = "unknown error"
+	ErrUnknownMethod           ErrorCode = "unknown method"
+	ErrUnsupportedOperation    ErrorCode = "unsupported operation"
+)
+
+var w3cErrorCodes = map[ErrorCode]bool{
+	ErrElementClickIntercepted: true,
+	ErrElementNotInteractable:  true,
+	ErrInsecureCertificate:     true,
+	ErrInvalidArgument:         true,
+	ErrInvalidCookieDomain:     true,
+	ErrInvalidElementState:     true,
+	ErrInvalidSelector:         true,
+	ErrInvalidSessionID:        true,
+	ErrJavascriptError:         true,
+	ErrMoveTargetOutOfBounds:   true,
+	ErrNoSuchAlert:             true,
+	ErrNoSuchCookie:            true,
+	ErrNoSuchElement:           true,
+	ErrNoSuchFrame:             true,
+	ErrNoSuchWindow:            true,
+	ErrNoSuchShadowRoot:        true,
+	ErrScriptTimeout:           true,
+	ErrSessionNotCreated:       true,
+	ErrStaleElementReference:   true,
+	ErrDetachedShadowRoot:      true,
+	ErrTimeout:                 true,
+	ErrUnableToSetCookie:       true,
+	ErrUnableToCaptureScreen:   true,
+	ErrUnexpectedAlertOpen:     true,
+	ErrUnknownCommand:          true,
+	ErrUnknownError:            true,
+	ErrUnknownMethod:           true,
+	ErrUnsupportedOperation:    true,
+}
+
+// legacyErrorCodes maps the "Response Status Codes" of the legacy Selenium
+// JSON wire protocol onto the W3C error codes.
+var legacyErrorCodes = map[int]ErrorCode{
+	6:  ErrInvalidSessionID,
+	7:  ErrNoSuchElement,
+	8:  ErrNoSuchFrame,
+	9:  ErrUnknownCommand,
+	10: ErrStaleElementReference,
+	11: ErrElementNotInteractable, // element not visible
+	12: ErrInvalidElementState,
+	13: ErrUnknownError,
+	15: ErrInvalidElementState, // element is not selectable
+	17: ErrJavascriptError,
+	19: ErrInvalidSelector, // xpath lookup error
+	21: ErrTimeout,
+	23: ErrNoSuchWindow,
+	24: ErrInvalidCookieDomain,
+	25: ErrUnableToSetCookie,
+	26: ErrUnexpectedAlertOpen,
+	27: ErrNoSuchAlert,
+	28: ErrScriptTimeout,
+	29: ErrInvalidArgument, // invalid element coordinates
+	32: ErrInvalidSelector,
+	33: ErrSessionNotCreated,
+	34: ErrMoveTargetOutOfBounds,
+	51: ErrInvalidSelector,
+	52: ErrInvalidSelector,
+	60: ErrElementNotInteractable,
+	61: ErrInvalidArgument,
+	62: ErrNoSuchCookie,
+	63: ErrUnableToCaptureScreen,
+	64: ErrElementClickIntercepted,
+	// ChromeDriver reports that the browser has been closed or crashed with
+	// this code ("chrome not reachable").
+	100: ErrUnknownError,
+}
+
+// Code returns the W3C error code of the error. Errors reported with the
+// legacy protocol are mapped onto the equivalent W3C code. ErrUnknownError is
+// returned for codes that are not defined by the specification.
+func (e *Error) Code() ErrorCode {
+	if c := ErrorCode(e.Err); w3cErrorCodes[c] {
+		return c
+	}
+	if c, ok := legacyErrorCodes[e.LegacyCode]; ok {
+		return c
+	}
+	return ErrUnknownError
+}
+
+// Is reports whether target is the ErrorCode of e, or an *Error with the same
+// code. It makes errors.Is(err, ErrNoSuchElement) work for the errors of both
+// protocol dialects.
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case ErrorCode:
+		return e.Code() == t
+	case *Error:
+		return e.Code() == t.Code()
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		desc string
+		body string
+		want ErrorCode
+	}{
+		{"W3C", `{"value":{"error":"no such element","message":""}}`, ErrNoSuchElement},
+		{"legacy", `{"status":7,"value":{"message":""}}`, ErrNoSuchElement},
+		{"legacy element not visible", `{"status":11,"value":{"message":""}}`, ErrElementNotInteractable},
+		{"legacy xpath lookup error", `{"status":19,"value":{"message":""}}`, ErrInvalidSelector},
+		{"legacy unknown status", `{"status":99,"value":{"message":""}}`, ErrUnknownError},
+		{"non-standard code", `{"value":{"error":"chrome not reachable","message":""}}`, ErrUnknownError},
+	}
+	for _, test := range tests {
+		hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", jsonContentType)
+			io.WriteString(w, test.body)
+		}))
+		wd := &WebDriver{id: "1", urlPrefix: hs.URL}
+		_, err := wd.FindElement(ByID, "x")
+		hs.Close()
+
+		if !errors.Is(err, test.want) {
+			t.Errorf("%s: errors.Is(%v, %q) = false, want true", test.desc, err, test.want)
+		}
+		if wrapped := fmt.Errorf("wrapped: %w", err); !errors.Is(wrapped, test.want) {
+			t.Errorf("%s: errors.Is(%v, %q) = false, want true", test.desc, wrapped, test.want)
+		}
+		if errors.Is(err, ErrStaleElementReference) {
+			t.Errorf("%s: errors.Is(%v, %q) = true, want false", test.desc, err, ErrStaleElementReference)
+		}
+		var e *Error
+		if !errors.As(err, &e) || e.Code() != test.want {
+			t.Errorf("%s: Code() of %v is not %q", test.desc, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package fakedriver
+
+import "strings"
+
+// implicitRoles maps tags to their implicit WAI-ARIA role. See
+// https://www.w3.org/TR/html-aam-1.0/ for the elements whose role depends on
+// their attributes.
+var implicitRoles = map[string]string{
+	"article": "article", "aside": "complementary", "button": "button",
+	"dialog": "dialog", "footer": "contentinfo", "form": "form",
+	"h1": "heading", "h2": "heading", "h3": "heading", "h4": "heading",
+	"h5": "heading", "h6": "heading", "header": "banner", "hr": "separator",
+	"li": "listitem", "main": "main", "menu": "list", "nav": "navigation",
+	"ol": "list", "option": "option", "p": "paragraph", "progress": "progressbar",
+	"section": "region", "summary": "button", "table": "table", "td": "cell",
+	"textarea": "textbox", "th": "columnheader", "tr": "row", "ul": "list",
+}
+
+var inputRoles = map[string]string{
+	"button": "button", "checkbox": "checkbox", "email": "textbox",
+	"image": "button", "number": "spinbutton", "radio": "radio",
+	"range": "slider", "reset": "button", "search": "searchbox",
+	"submit": "button", "tel": "textbox", "text": "textbox", "url": "textbox",
+}
+
+// nameFromContent lists the roles whose accessible name is computed from
+// their content.
+var nameFromContent = map[string]bool{
+	"button": true, "cell": true, "checkbox": true, "columnheader": true,
+	"heading": true, "link": true, "listitem": true, "menuitem": true,
+	"option": true, "radio": true, "row": true, "rowheader": true,
+	"switch": true, "tab": true, "tooltip": true, "treeitem": true,
+}
+
+// role returns the WAI-ARIA role of n.
+func role(n *node) string {
+	if r := strings.Fields(attrValue(n, "role")); len(r) > 0 {
+		return r[0]
+	}
+	switch n.tag {
+	case "a", "area":
+		if _, ok := n.attr("href"); ok {
+			return "link"
+		}
+		return ""
+	case "img":
+		if alt, ok := n.attr("alt"); ok && alt == "" {
+			return "presentation"
+		}
+		return "img"
+	case "select":
+		_, multiple := n.attr("multiple")
+		if size := attrValue(n, "size"); multiple || size != "" && size != "0" && size != "1" {
+			return "listbox"
+		}
+		return "combobox"
+	case "input":
+		if _, ok := n.attr("list"); ok {
+			return "combobox"
+		}
+		typ := strings.ToLower(attrValue(n, "type"))
+		if typ == "" {
+			typ = "text"
+		}
+		return inputRoles[typ]
+	}
+	return implicitRoles[n.tag]
+}
+
+// accessibleName returns the accessible name of n, approximating
+// https://www.w3.org/TR/accname-1.1/. nested is set while computing the name
+// of a descendant of an element named from its content.
+func accessibleName(n *node, nested bool) string {
+	if ids := attrValue(n, "aria-labelledby"); ids != "" && !nested {
+		var parts []string
+		for _, id := range strings.Fields(ids) {
+			if l := elementByID(n.root(), id); l != nil {
+				parts = append(parts, nameText(l))
+			}
+		}
+		return normalizeSpace(strings.Join(parts, " "))
+	}
+	if l := normalizeSpace(attrValue(n, "aria-label")); l != "" {
+		return l
+	}
+	typ := strings.ToLower(attrValue(n, "type"))
+	if n.tag == "input" {
+		switch typ {
+		case "button", "submit", "reset":
+			v, ok := n.attr("value")
+			if !ok && typ == "submit" {
+				v = "Submit"
+			} else if !ok && typ == "reset" {
+				v = "Reset"
+			}
+			return normalizeSpace(v)
+		case "image":
+			return normalizeSpace(attrValue(n, "alt"))
+		}
+	}
+	if !nested {
+		if labels := labelsOf(n); len(labels) > 0 {
+			var parts []string
+			for _, l := range labels {
+				parts = append(parts, nameText(l))
+			}
+			return normalizeSpace(strings.Join(parts, " "))
+		}
+	}
+	if n.tag == "img" {
+		if alt := attrValue(n, "alt"); alt != "" {
+			return normalizeSpace(alt)
+		}
+		return normalizeSpace(attrValue(n, "title"))
+	}
+	if nested || nameFromContent[role(n)] {
+		if t := normalizeSpace(nameText(n)); t != "" {
+			return t
+		}
+	}
+	if t := attrValue(n, "title"); t != "" || n.tag != "input" && n.tag != "textarea" {
+		return normalizeSpace(t)
+	}
+	return normalizeSpace(attrValue(n, "placeholder"))
+}
+
+// nameText returns the text of the content of n that contributes to the
+// accessible names: hidden elements and form fields are left out.
+func nameText(n *node) string {
+	var b strings.Builder
+	for _, c := range n.children {
+		switch {
+		case c.typ == textNode:
+			b.WriteString(c.text)
+		case c.typ != elementNode:
+		case c.tag == "input" || c.tag == "select" || c.tag == "textarea" || c.tag == "script" || c.tag == "style" || c.tag == "template":
+		case attrValue(c, "aria-hidden") == "true":
+		default:
+			if _, hidden := c.attr("hidden"); hidden {
+				continue
+			}
+			name := accessibleName(c, true)
+			if c.tag == "img" {
+				name = attrValue(c, "alt")
+			}
+			b.WriteString(" " + name + " ")
+		}
+	}
+	return b.String()
+}
+
+// labelsOf returns the label elements of the form field n: those that
+// contain it, and those that refer to its ID.
+func labelsOf(n *node) []*node {
+	switch n.tag {
+	case "input", "select", "textarea", "button", "meter", "output", "progress":
+	default:
+		return nil
+	}
+	if strings.EqualFold(attrValue(n, "type"), "hidden") {
+		return nil
+	}
+	var labels []*node
+	id, hasID := n.attr("id")
+	n.root().walk(func(l *node) {
+		if l.tag != "label" {
+			return
+		}
+		if hasID && id != "" && attrValue(l, "for") == id {
+			labels = append(labels, l)
+			return
+		}
+		if _, ok := l.attr("for"); ok {
+			return
+		}
+		for p := n.parent; p != nil; p = p.parent {
+			if p == l {
+				labels = append(labels, l)
+				return
+			}
+		}
+	})
+	return labels
+}
+
+func elementByID(root *node, id string) *node {
+	var found *node
+	root.walk(func(n *node) {
+		if found == nil && attrValue(n, "id") == id {
+			found = n
+		}
+	})
+	return found
+}
+
+func attrValue(n *node, key string) string {
+	v, _ := n.attr(key)
+	return v
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func (sess *session) computedRole(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return role(n), nil
+}
+
+func (sess *session) computedLabel(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return accessibleName(n, false), nil
+}
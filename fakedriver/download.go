@@ -0,0 +1,90 @@
+package fakedriver
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"sort"
+)
+
+// downloadsEnabled is the capability that makes a Selenium Grid keep the
+// downloads of a session.
+const downloadsEnabled = "se:downloadsEnabled"
+
+// SetDownload adds the file name, or replaces its content, in the downloads
+// that the sessions created with the capability se:downloadsEnabled list
+// and fetch. Names ending in .crdownload or .part stand for the files being
+// downloaded.
+func (s *Server) SetDownload(name string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.downloads[name] = content
+}
+
+// RemoveDownload removes the file name from the downloads.
+func (s *Server) RemoveDownload(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.downloads, name)
+}
+
+func (sess *session) checkDownloads() error {
+	if !sess.downloads {
+		return newError(errInvalidArgument, "the session was not created with the capability %s", downloadsEnabled)
+	}
+	return nil
+}
+
+func (sess *session) downloadableFiles([]string, []byte) (interface{}, error) {
+	if err := sess.checkDownloads(); err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for name := range sess.server.downloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return map[string]interface{}{"names": names}, nil
+}
+
+// downloadFile returns the file in a base64-encoded Zip file, as Selenium
+// does.
+func (sess *session) downloadFile(_ []string, body []byte) (interface{}, error) {
+	if err := sess.checkDownloads(); err != nil {
+		return nil, err
+	}
+	var params struct{ Name string }
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	content, ok := sess.server.downloads[params.Name]
+	if !ok {
+		return nil, newError(errInvalidArgument, "cannot find file %q in the downloads", params.Name)
+	}
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	f, err := w.Create(params.Name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(content); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"filename": params.Name,
+		"contents": base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+func (sess *session) deleteDownloadableFiles([]string, []byte) (interface{}, error) {
+	if err := sess.checkDownloads(); err != nil {
+		return nil, err
+	}
+	for name := range sess.server.downloads {
+		delete(sess.server.downloads, name)
+	}
+	return nil, nil
+}
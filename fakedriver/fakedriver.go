@@ -0,0 +1,1577 @@
+// Package fakedriver provides an in-process WebDriver remote end for tests
+// that cannot run a browser.
+//
+// The server implements the W3C WebDriver endpoints on top of static HTML
+// documents: navigation and history, finding elements by CSS selector,
+// XPath, link text and tag name, element text, attributes and properties,
+// clicks on links, checkboxes, options and submit buttons, typing into form
+// fields, computed roles and labels, declarative shadow roots, cookies,
+// windows, timeouts, printing and the file commands of Selenium. No
+// JavaScript is run; scripts are answered by functions registered with
+// HandleScript, or submit a form if registered with HandleSubmitScript.
+//
+//	s := fakedriver.New(map[string]string{
+//		"http://example.com/": `<h1 id="title">Hello</h1>`,
+//	})
+//	defer s.Close()
+//	wd, err := selenium.NewRemote(nil, s.URL)
+package fakedriver
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Server is a fake WebDriver remote end. It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	pages     map[string]string
+	scripts   map[string]scriptFunc
+	sessions  map[string]*session
+	uploads   map[string][]byte
+	downloads map[string][]byte
+	lastID    int
+}
+
+// ScriptFunc answers a script sent with ExecuteScript or ExecuteScriptAsync.
+// args are the JSON-decoded arguments of the script; element references are
+// passed as maps, as they appear on the wire.
+type ScriptFunc func(args []interface{}) (interface{}, error)
+
+// scriptFunc answers a script in a session.
+type scriptFunc func(sess *session, args []interface{}) (interface{}, error)
+
+// New starts a server that serves pages, which maps URLs to HTML documents.
+// The caller should call Close when finished.
+func New(pages map[string]string) *Server {
+	s := &Server{
+		pages:     make(map[string]string),
+		scripts:   make(map[string]scriptFunc),
+		sessions:  make(map[string]*session),
+		uploads:   make(map[string][]byte),
+		downloads: make(map[string][]byte),
+	}
+	for u, doc := range pages {
+		s.pages[u] = doc
+	}
+	s.HandleScript("return document.readyState", func([]interface{}) (interface{}, error) {
+		return "complete", nil
+	})
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// SetPage adds or replaces the document served for url. Windows that show
+// the page keep the old document until they navigate again.
+func (s *Server) SetPage(url, html string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pages[url] = html
+}
+
+// HandleScript registers f to answer script. Scripts that are not registered
+// return null.
+func (s *Server) HandleScript(script string, f ScriptFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts[strings.TrimSpace(script)] = func(_ *session, args []interface{}) (interface{}, error) {
+		v, err := f(args)
+		if err != nil {
+			return nil, newError(errJavascriptError, "%v", err)
+		}
+		return v, nil
+	}
+}
+
+// HandleSubmitScript registers script as a script that submits the form of
+// the element passed as its first argument, such as the script sent by
+// WebElement.Submit to W3C servers.
+func (s *Server) HandleSubmitScript(script string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts[strings.TrimSpace(script)] = func(sess *session, args []interface{}) (interface{}, error) {
+		return nil, sess.submitElement(args)
+	}
+}
+
+// load returns the parsed document for rawURL.
+func (s *Server) load(rawURL string) (*node, error) {
+	if rawURL == "about:blank" {
+		return parseHTML(""), nil
+	}
+	if doc, ok := s.pages[rawURL]; ok {
+		return parseHTML(doc), nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, newError(errInvalidArgument, "invalid URL %q: %v", rawURL, err)
+	}
+	u.Fragment = ""
+	if doc, ok := s.pages[u.String()]; ok {
+		return parseHTML(doc), nil
+	}
+	u.RawQuery = ""
+	if doc, ok := s.pages[u.String()]; ok {
+		return parseHTML(doc), nil
+	}
+	if doc, ok := s.pages[u.Path]; ok && u.Host == strings.TrimPrefix(s.URL, "http://") {
+		return parseHTML(doc), nil
+	}
+	return nil, newError(errUnknownError, "fakedriver: no page for %s", rawURL)
+}
+
+func (s *Server) newID(kind string) string {
+	s.lastID++
+	return fmt.Sprintf("fake-%s-%d", kind, s.lastID)
+}
+
+// The error codes of the W3C specification used by the server.
+const (
+	errElementNotInteractable = "element not interactable"
+	errInvalidArgument        = "invalid argument"
+	errInvalidSelector        = "invalid selector"
+	errInvalidSessionID       = "invalid session id"
+	errJavascriptError        = "javascript error"
+	errDetachedShadowRoot     = "detached shadow root"
+	errNoSuchAlert            = "no such alert"
+	errNoSuchCookie           = "no such cookie"
+	errNoSuchElement          = "no such element"
+	errNoSuchFrame            = "no such frame"
+	errNoSuchShadowRoot       = "no such shadow root"
+	errNoSuchWindow           = "no such window"
+	errStaleElementReference  = "stale element reference"
+	errUnknownCommand         = "unknown command"
+	errUnknownError           = "unknown error"
+)
+
+var errorStatus = map[string]int{
+	errElementNotInteractable: http.StatusBadRequest,
+	errInvalidArgument:        http.StatusBadRequest,
+	errInvalidSelector:        http.StatusBadRequest,
+	errInvalidSessionID:       http.StatusNotFound,
+	errJavascriptError:        http.StatusInternalServerError,
+	errDetachedShadowRoot:     http.StatusNotFound,
+	errNoSuchAlert:            http.StatusNotFound,
+	errNoSuchCookie:           http.StatusNotFound,
+	errNoSuchElement:          http.StatusNotFound,
+	errNoSuchFrame:            http.StatusNotFound,
+	errNoSuchShadowRoot:       http.StatusNotFound,
+	errNoSuchWindow:           http.StatusNotFound,
+	errStaleElementReference:  http.StatusNotFound,
+	errUnknownCommand:         http.StatusNotFound,
+	errUnknownError:           http.StatusInternalServerError,
+}
+
+// wdError is an error reply of the server.
+type wdError struct {
+	code    string
+	message string
+}
+
+func (e *wdError) Error() string {
+	return e.code + ": " + e.message
+}
+
+func newError(code, format string, args ...interface{}) *wdError {
+	return &wdError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, newError(errInvalidArgument, "%v", err))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, err := s.handle(r.Method, strings.TrimPrefix(r.URL.EscapedPath(), "/wd/hub"), body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"value": value})
+}
+
+func readBody(r *http.Request) ([]byte, error) {
+	var b bytes.Buffer
+	if _, err := b.ReadFrom(r.Body); err != nil {
+		return nil, err
+	}
+	if b.Len() > 0 && !json.Valid(b.Bytes()) {
+		return nil, fmt.Errorf("the payload is not valid JSON")
+	}
+	return b.Bytes(), nil
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*wdError)
+	if !ok {
+		e = newError(errUnknownError, "%v", err)
+	}
+	status, ok := errorStatus[e.code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	writeJSON(w, status, map[string]interface{}{
+		"value": map[string]string{
+			"error":      e.code,
+			"message":    e.message,
+			"stacktrace": "",
+		},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data = []byte(`{"value":{"error":"unknown error","message":"cannot encode the reply"}}`)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+// handler implements a session command. args are the path parameters that
+// follow the session ID.
+type handler func(sess *session, args []string, body []byte) (interface{}, error)
+
+type route struct {
+	method string
+	path   string
+	handle handler
+}
+
+// routes lists the session commands, with the paths of the W3C
+// specification and of Selenium.
+var routes = []route{
+	{http.MethodDelete, "/session/{session id}", (*session).delete},
+	{http.MethodGet, "/session/{session id}/timeouts", (*session).getTimeouts},
+	{http.MethodPost, "/session/{session id}/timeouts", (*session).setTimeouts},
+	{http.MethodPost, "/session/{session id}/url", (*session).navigateTo},
+	{http.MethodGet, "/session/{session id}/url", (*session).currentURL},
+	{http.MethodPost, "/session/{session id}/back", (*session).back},
+	{http.MethodPost, "/session/{session id}/forward", (*session).forward},
+	{http.MethodPost, "/session/{session id}/refresh", (*session).refresh},
+	{http.MethodGet, "/session/{session id}/title", (*session).title},
+	{http.MethodGet, "/session/{session id}/window", (*session).windowHandle},
+	{http.MethodDelete, "/session/{session id}/window", (*session).closeWindow},
+	{http.MethodPost, "/session/{session id}/window", (*session).switchToWindow},
+	{http.MethodGet, "/session/{session id}/window/handles", (*session).windowHandles},
+	{http.MethodPost, "/session/{session id}/window/new", (*session).newWindow},
+	{http.MethodPost, "/session/{session id}/frame", (*session).switchToFrame},
+	{http.MethodPost, "/session/{session id}/frame/parent", (*session).switchToParentFrame},
+	{http.MethodGet, "/session/{session id}/window/rect", (*session).windowRect},
+	{http.MethodPost, "/session/{session id}/window/rect", (*session).setWindowRect},
+	{http.MethodPost, "/session/{session id}/window/maximize", (*session).maximizeWindow},
+	{http.MethodPost, "/session/{session id}/window/minimize", (*session).windowRect},
+	{http.MethodPost, "/session/{session id}/window/fullscreen", (*session).maximizeWindow},
+	{http.MethodGet, "/session/{session id}/element/active", (*session).activeElement},
+	{http.MethodPost, "/session/{session id}/element", (*session).findElement},
+	{http.MethodPost, "/session/{session id}/elements", (*session).findElements},
+	{http.MethodPost, "/session/{session id}/element/{element id}/element", (*session).findElement},
+	{http.MethodPost, "/session/{session id}/element/{element id}/elements", (*session).findElements},
+	{http.MethodGet, "/session/{session id}/element/{element id}/shadow", (*session).shadowRoot},
+	{http.MethodPost, "/session/{session id}/shadow/{shadow id}/element", (*session).findElementFromShadow},
+	{http.MethodPost, "/session/{session id}/shadow/{shadow id}/elements", (*session).findElementsFromShadow},
+	{http.MethodGet, "/session/{session id}/element/{element id}/selected", (*session).elementSelected},
+	{http.MethodGet, "/session/{session id}/element/{element id}/attribute/{name}", (*session).elementAttribute},
+	{http.MethodGet, "/session/{session id}/element/{element id}/property/{name}", (*session).elementProperty},
+	{http.MethodGet, "/session/{session id}/element/{element id}/css/{property name}", (*session).elementCSSValue},
+	{http.MethodGet, "/session/{session id}/element/{element id}/text", (*session).elementText},
+	{http.MethodGet, "/session/{session id}/element/{element id}/name", (*session).elementTagName},
+	{http.MethodGet, "/session/{session id}/element/{element id}/rect", (*session).elementRect},
+	{http.MethodGet, "/session/{session id}/element/{element id}/computedrole", (*session).computedRole},
+	{http.MethodGet, "/session/{session id}/element/{element id}/computedlabel", (*session).computedLabel},
+	{http.MethodGet, "/session/{session id}/element/{element id}/enabled", (*session).elementEnabled},
+	{http.MethodGet, "/session/{session id}/element/{element id}/displayed", (*session).elementDisplayed},
+	{http.MethodPost, "/session/{session id}/element/{element id}/click", (*session).elementClick},
+	{http.MethodPost, "/session/{session id}/element/{element id}/clear", (*session).elementClear},
+	{http.MethodPost, "/session/{session id}/element/{element id}/value", (*session).elementSendKeys},
+	{http.MethodGet, "/session/{session id}/element/{element id}/screenshot", (*session).elementScreenshot},
+	{http.MethodGet, "/session/{session id}/source", (*session).pageSource},
+	{http.MethodPost, "/session/{session id}/execute/sync", (*session).executeScript},
+	{http.MethodPost, "/session/{session id}/execute/async", (*session).executeScript},
+	{http.MethodGet, "/session/{session id}/cookie", (*session).getCookies},
+	{http.MethodGet, "/session/{session id}/cookie/{name}", (*session).getCookie},
+	{http.MethodPost, "/session/{session id}/cookie", (*session).addCookie},
+	{http.MethodDelete, "/session/{session id}/cookie/{name}", (*session).deleteCookie},
+	{http.MethodDelete, "/session/{session id}/cookie", (*session).deleteCookies},
+	{http.MethodPost, "/session/{session id}/actions", (*session).performActions},
+	{http.MethodDelete, "/session/{session id}/actions", (*session).performActions},
+	{http.MethodPost, "/session/{session id}/alert/dismiss", (*session).alert},
+	{http.MethodPost, "/session/{session id}/alert/accept", (*session).alert},
+	{http.MethodGet, "/session/{session id}/alert/text", (*session).alert},
+	{http.MethodPost, "/session/{session id}/alert/text", (*session).alert},
+	{http.MethodGet, "/session/{session id}/screenshot", (*session).screenshot},
+	{http.MethodPost, "/session/{session id}/print", (*session).print},
+	{http.MethodPost, "/session/{session id}/se/file", (*session).uploadFile},
+	{http.MethodGet, "/session/{session id}/se/files", (*session).downloadableFiles},
+	{http.MethodPost, "/session/{session id}/se/files", (*session).downloadFile},
+	{http.MethodDelete, "/session/{session id}/se/files", (*session).deleteDownloadableFiles},
+}
+
+func (s *Server) handle(method, path string, body []byte) (interface{}, error) {
+	switch {
+	case method == http.MethodGet && path == "/status":
+		return map[string]interface{}{"ready": true, "message": "fakedriver is ready"}, nil
+	case method == http.MethodPost && path == "/session":
+		return s.newSession(body)
+	}
+
+	known := false
+	for _, r := range routes {
+		args, ok := matchPath(r.path, path)
+		if !ok {
+			continue
+		}
+		known = true
+		if r.method != method {
+			continue
+		}
+		sess, ok := s.sessions[args[0]]
+		if !ok {
+			return nil, newError(errInvalidSessionID, "no active session with ID %s", args[0])
+		}
+		return r.handle(sess, args[1:], body)
+	}
+	if known {
+		return nil, newError(errUnknownCommand, "unsupported method %s for %s", method, path)
+	}
+	return nil, newError(errUnknownCommand, "unknown command %s %s", method, path)
+}
+
+// matchPath matches path against a pattern with placeholders, and returns
+// the unescaped values of the placeholders.
+func matchPath(pattern, path string) ([]string, bool) {
+	ps := strings.Split(strings.Trim(pattern, "/"), "/")
+	xs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(ps) != len(xs) {
+		return nil, false
+	}
+	var args []string
+	for i, p := range ps {
+		if strings.HasPrefix(p, "{") {
+			v, err := url.PathUnescape(xs[i])
+			if err != nil {
+				return nil, false
+			}
+			args = append(args, v)
+			continue
+		}
+		if p != xs[i] {
+			return nil, false
+		}
+	}
+	return args, true
+}
+
+func (s *Server) newSession(body []byte) (interface{}, error) {
+	var params struct {
+		Capabilities struct {
+			AlwaysMatch map[string]interface{}
+		}
+	}
+	if len(body) > 0 {
+		if err := decode(body, &params); err != nil {
+			return nil, err
+		}
+	}
+	sess := &session{
+		server:   s,
+		id:       s.newID("session"),
+		timeouts: map[string]int{"implicit": 0, "pageLoad": 300000, "script": 30000},
+		elements: make(map[string]*node),
+		ids:      make(map[*node]string),
+	}
+	w := sess.openWindow()
+	sess.current = w
+	s.sessions[sess.id] = sess
+	caps := map[string]interface{}{
+		"browserName":         "fakedriver",
+		"browserVersion":      "1.0.0",
+		"platformName":        runtime.GOOS,
+		"acceptInsecureCerts": false,
+		"pageLoadStrategy":    "normal",
+		"setWindowRect":       true,
+		"timeouts":            sess.timeouts,
+	}
+	if params.Capabilities.AlwaysMatch[downloadsEnabled] == true {
+		sess.downloads = true
+		caps[downloadsEnabled] = true
+	}
+	return map[string]interface{}{
+		"sessionId":    sess.id,
+		"capabilities": caps,
+	}, nil
+}
+
+type session struct {
+	server   *Server
+	id       string
+	windows  []*window
+	current  *window
+	cookies  []cookie
+	timeouts map[string]int
+	elements map[string]*node
+	ids      map[*node]string
+	// downloads is set if the session keeps the downloads, as a Selenium
+	// Grid does with the capability se:downloadsEnabled.
+	downloads bool
+}
+
+type window struct {
+	handle  string
+	history []string
+	index   int
+	doc     *node
+	active  *node
+	rect    rect
+}
+
+type rect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+type cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path"`
+	Domain   string `json:"domain"`
+	Secure   bool   `json:"secure"`
+	HTTPOnly bool   `json:"httpOnly"`
+	Expiry   uint   `json:"expiry,omitempty"`
+	SameSite string `json:"sameSite,omitempty"`
+}
+
+func (sess *session) openWindow() *window {
+	w := &window{
+		handle:  sess.server.newID("window"),
+		history: []string{"about:blank"},
+		doc:     parseHTML(""),
+		rect:    rect{Width: 1280, Height: 800},
+	}
+	sess.windows = append(sess.windows, w)
+	return w
+}
+
+// window returns the current window.
+func (sess *session) window() (*window, error) {
+	if sess.current == nil {
+		return nil, newError(errNoSuchWindow, "the current window was closed")
+	}
+	return sess.current, nil
+}
+
+func (w *window) url() string {
+	return w.history[w.index]
+}
+
+// navigate loads rawURL in the current window and adds it to the history.
+func (sess *session) navigate(rawURL string) error {
+	w, err := sess.window()
+	if err != nil {
+		return err
+	}
+	doc, err := sess.server.load(rawURL)
+	if err != nil {
+		return err
+	}
+	w.history = append(w.history[:w.index+1], rawURL)
+	w.index++
+	w.doc = doc
+	w.active = nil
+	return nil
+}
+
+func (sess *session) reload(w *window) error {
+	doc, err := sess.server.load(w.url())
+	if err != nil {
+		return err
+	}
+	w.doc = doc
+	w.active = nil
+	return nil
+}
+
+// reference returns the JSON reference of the element or shadow root n.
+func (sess *session) reference(n *node) map[string]string {
+	kind, key := "element", webElementIdentifier
+	if n.typ == shadowRootNode {
+		kind, key = "shadow", shadowRootIdentifier
+	}
+	id, ok := sess.ids[n]
+	if !ok {
+		id = sess.server.newID(kind)
+		sess.ids[n] = id
+		sess.elements[id] = n
+	}
+	return map[string]string{key: id}
+}
+
+// element returns the element with the given ID in the current document.
+func (sess *session) element(id string) (*node, error) {
+	n, ok := sess.elements[id]
+	if !ok || n.typ == shadowRootNode {
+		return nil, newError(errNoSuchElement, "no element with ID %s", id)
+	}
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	if n.root() != w.doc {
+		return nil, newError(errStaleElementReference, "element %s is not attached to the current document", id)
+	}
+	return n, nil
+}
+
+// shadow returns the shadow root with the given ID in the current document.
+func (sess *session) shadow(id string) (*node, error) {
+	n, ok := sess.elements[id]
+	if !ok || n.typ != shadowRootNode {
+		return nil, newError(errNoSuchShadowRoot, "no shadow root with ID %s", id)
+	}
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	if n.root() != w.doc {
+		return nil, newError(errDetachedShadowRoot, "shadow root %s is not attached to the current document", id)
+	}
+	return n, nil
+}
+
+const (
+	webElementIdentifier = "element-6066-11e4-a52e-4f735466cecf"
+	shadowRootIdentifier = "shadow-6066-11e4-a52e-4f735466cecf"
+)
+
+func decode(body []byte, v interface{}) error {
+	if len(body) == 0 {
+		return newError(errInvalidArgument, "missing payload")
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return newError(errInvalidArgument, "%v", err)
+	}
+	return nil
+}
+
+func (sess *session) delete([]string, []byte) (interface{}, error) {
+	delete(sess.server.sessions, sess.id)
+	return nil, nil
+}
+
+func (sess *session) getTimeouts([]string, []byte) (interface{}, error) {
+	return sess.timeouts, nil
+}
+
+func (sess *session) setTimeouts(_ []string, body []byte) (interface{}, error) {
+	var params map[string]interface{}
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{"implicit", "pageLoad", "script"} {
+		v, ok := params[key]
+		if !ok {
+			continue
+		}
+		ms, ok := v.(float64)
+		if !ok || ms < 0 {
+			return nil, newError(errInvalidArgument, "invalid %s timeout %v", key, v)
+		}
+		sess.timeouts[key] = int(ms)
+	}
+	return nil, nil
+}
+
+func (sess *session) navigateTo(_ []string, body []byte) (interface{}, error) {
+	var params struct{ URL string }
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	return nil, sess.navigate(params.URL)
+}
+
+func (sess *session) currentURL([]string, []byte) (interface{}, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	return w.url(), nil
+}
+
+func (sess *session) back([]string, []byte) (interface{}, error) {
+	return nil, sess.traverse(-1)
+}
+
+func (sess *session) forward([]string, []byte) (interface{}, error) {
+	return nil, sess.traverse(1)
+}
+
+func (sess *session) traverse(delta int) error {
+	w, err := sess.window()
+	if err != nil {
+		return err
+	}
+	i := w.index + delta
+	if i < 0 || i >= len(w.history) {
+		return nil
+	}
+	w.index = i
+	return sess.reload(w)
+}
+
+func (sess *session) refresh([]string, []byte) (interface{}, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	return nil, sess.reload(w)
+}
+
+func (sess *session) title([]string, []byte) (interface{}, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	if t := w.doc.find("title"); t != nil {
+		return strings.Join(strings.Fields(t.textContent()), " "), nil
+	}
+	return "", nil
+}
+
+func (sess *session) windowHandle([]string, []byte) (interface{}, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	return w.handle, nil
+}
+
+func (sess *session) windowHandles([]string, []byte) (interface{}, error) {
+	handles := make([]string, len(sess.windows))
+	for i, w := range sess.windows {
+		handles[i] = w.handle
+	}
+	return handles, nil
+}
+
+func (sess *session) closeWindow([]string, []byte) (interface{}, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	for i, o := range sess.windows {
+		if o == w {
+			sess.windows = append(sess.windows[:i], sess.windows[i+1:]...)
+			break
+		}
+	}
+	sess.current = nil
+	if len(sess.windows) == 0 {
+		delete(sess.server.sessions, sess.id)
+	}
+	return sess.windowHandles(nil, nil)
+}
+
+func (sess *session) switchToWindow(_ []string, body []byte) (interface{}, error) {
+	var params struct{ Handle, Name string }
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	if params.Handle == "" {
+		params.Handle = params.Name
+	}
+	for _, w := range sess.windows {
+		if w.handle == params.Handle {
+			sess.current = w
+			return nil, nil
+		}
+	}
+	return nil, newError(errNoSuchWindow, "no window with handle %s", params.Handle)
+}
+
+func (sess *session) newWindow(_ []string, body []byte) (interface{}, error) {
+	var params struct{ Type string }
+	if len(body) > 0 {
+		if err := decode(body, &params); err != nil {
+			return nil, err
+		}
+	}
+	if params.Type != "window" {
+		params.Type = "tab"
+	}
+	w := sess.openWindow()
+	return map[string]string{"handle": w.handle, "type": params.Type}, nil
+}
+
+func (sess *session) switchToFrame(_ []string, body []byte) (interface{}, error) {
+	var params struct{ ID interface{} }
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	if params.ID != nil {
+		return nil, newError(errNoSuchFrame, "frames are not supported")
+	}
+	return nil, nil
+}
+
+func (sess *session) switchToParentFrame([]string, []byte) (interface{}, error) {
+	_, err := sess.window()
+	return nil, err
+}
+
+func (sess *session) windowRect([]string, []byte) (interface{}, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	return w.rect, nil
+}
+
+func (sess *session) setWindowRect(_ []string, body []byte) (interface{}, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	var params struct {
+		X, Y          *float64
+		Width, Height *float64
+	}
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	for _, f := range []struct {
+		dst *float64
+		src *float64
+	}{
+		{&w.rect.X, params.X},
+		{&w.rect.Y, params.Y},
+		{&w.rect.Width, params.Width},
+		{&w.rect.Height, params.Height},
+	} {
+		if f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+	return w.rect, nil
+}
+
+func (sess *session) maximizeWindow([]string, []byte) (interface{}, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	w.rect = rect{Width: 1920, Height: 1080}
+	return w.rect, nil
+}
+
+func (sess *session) activeElement([]string, []byte) (interface{}, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	n := w.active
+	if n == nil || n.root() != w.doc {
+		n = w.doc.find("body")
+	}
+	return sess.reference(n), nil
+}
+
+// find returns the elements below the element given by args, or below the
+// document if args is empty.
+func (sess *session) find(args []string, body []byte) ([]*node, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	scope := w.doc
+	if len(args) > 0 {
+		if scope, err = sess.element(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	return findIn(scope, body)
+}
+
+// findIn returns the elements below scope that match the locator in body.
+func findIn(scope *node, body []byte) ([]*node, error) {
+	var params struct{ Using, Value string }
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	if scope.typ == shadowRootNode && params.Using == "xpath" {
+		return nil, newError(errInvalidArgument, "XPath is not supported in shadow roots")
+	}
+
+	var css string
+	switch params.Using {
+	case "css selector":
+		css = params.Value
+	case "id":
+		css = "#" + cssEscape(params.Value)
+	case "name":
+		css = `[name="` + strings.ReplaceAll(params.Value, `"`, `\"`) + `"]`
+	case "class name":
+		css = "." + cssEscape(params.Value)
+	case "tag name":
+		css = cssEscape(params.Value)
+	case "xpath":
+		nodes, err := evalXPath(params.Value, scope)
+		if err != nil {
+			return nil, newError(errInvalidSelector, "%v", err)
+		}
+		return nodes, nil
+	case "link text", "partial link text":
+		var nodes []*node
+		scope.walk(func(n *node) {
+			if n.tag != "a" {
+				return
+			}
+			text := strings.TrimSpace(renderedText(n))
+			if text == params.Value || params.Using == "partial link text" && strings.Contains(text, params.Value) {
+				nodes = append(nodes, n)
+			}
+		})
+		return nodes, nil
+	default:
+		return nil, newError(errInvalidArgument, "unknown locator strategy %q", params.Using)
+	}
+	sel, err := parseSelector(css)
+	if err != nil {
+		return nil, newError(errInvalidSelector, "%v", err)
+	}
+	return sel.selectAll(scope), nil
+}
+
+func cssEscape(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c >= 0x80) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+func (sess *session) findElement(args []string, body []byte) (interface{}, error) {
+	nodes, err := sess.find(args, body)
+	if err != nil {
+		return nil, err
+	}
+	return sess.first(nodes, body)
+}
+
+func (sess *session) findElements(args []string, body []byte) (interface{}, error) {
+	nodes, err := sess.find(args, body)
+	if err != nil {
+		return nil, err
+	}
+	return sess.references(nodes), nil
+}
+
+func (sess *session) findElementFromShadow(args []string, body []byte) (interface{}, error) {
+	nodes, err := sess.findFromShadow(args, body)
+	if err != nil {
+		return nil, err
+	}
+	return sess.first(nodes, body)
+}
+
+func (sess *session) findElementsFromShadow(args []string, body []byte) (interface{}, error) {
+	nodes, err := sess.findFromShadow(args, body)
+	if err != nil {
+		return nil, err
+	}
+	return sess.references(nodes), nil
+}
+
+// findFromShadow returns the elements of the shadow root given by args.
+func (sess *session) findFromShadow(args []string, body []byte) ([]*node, error) {
+	root, err := sess.shadow(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return findIn(root, body)
+}
+
+func (sess *session) first(nodes []*node, body []byte) (interface{}, error) {
+	if len(nodes) == 0 {
+		return nil, newError(errNoSuchElement, "no element matches %s", body)
+	}
+	return sess.reference(nodes[0]), nil
+}
+
+func (sess *session) references(nodes []*node) []map[string]string {
+	refs := make([]map[string]string, len(nodes))
+	for i, n := range nodes {
+		refs[i] = sess.reference(n)
+	}
+	return refs
+}
+
+func (sess *session) shadowRoot(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if n.shadow == nil {
+		return nil, newError(errNoSuchShadowRoot, "element %s has no shadow root", args[0])
+	}
+	return sess.reference(n.shadow), nil
+}
+
+func (sess *session) elementSelected(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return isSelected(n), nil
+}
+
+func (sess *session) elementAttribute(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := n.attr(strings.ToLower(args[1])); ok {
+		return v, nil
+	}
+	return nil, nil
+}
+
+func (sess *session) elementProperty(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	switch name := args[1]; name {
+	case "value":
+		return value(n), nil
+	case "checked", "selected":
+		return isSelected(n), nil
+	case "disabled":
+		return !isEnabled(n), nil
+	case "tagName", "nodeName":
+		return strings.ToUpper(n.tag), nil
+	case "className":
+		v, _ := n.attr("class")
+		return v, nil
+	case "href", "src", "action":
+		v, ok := n.attr(name)
+		if !ok {
+			return "", nil
+		}
+		return sess.resolve(v), nil
+	case "innerHTML":
+		return innerHTML(n), nil
+	case "outerHTML":
+		return outerHTML(n), nil
+	case "textContent":
+		return n.textContent(), nil
+	case "innerText":
+		return renderedText(n), nil
+	default:
+		if v, ok := n.attr(strings.ToLower(name)); ok {
+			return v, nil
+		}
+		return nil, nil
+	}
+}
+
+func (sess *session) elementCSSValue(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return inlineStyle(n, args[1]), nil
+}
+
+func (sess *session) elementText(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return renderedText(n), nil
+}
+
+func (sess *session) elementTagName(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return n.tag, nil
+}
+
+// elementRect returns a made-up rectangle: elements are stacked in document
+// order, 20 pixels apart.
+func (sess *session) elementRect(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	r := rect{Width: 100, Height: 20}
+	i := 0
+	n.root().walk(func(c *node) {
+		if c == n {
+			r.Y = float64(20 * i)
+		}
+		i++
+	})
+	if !isDisplayed(n) {
+		r = rect{}
+	}
+	return r, nil
+}
+
+func (sess *session) elementEnabled(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return isEnabled(n), nil
+}
+
+func (sess *session) elementDisplayed(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return isDisplayed(n), nil
+}
+
+func (sess *session) elementClick(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !isDisplayed(n) {
+		return nil, newError(errElementNotInteractable, "element %s is not displayed", args[0])
+	}
+	sess.current.active = n
+	if !isEnabled(n) {
+		return nil, nil
+	}
+
+	typ, _ := n.attr("type")
+	typ = strings.ToLower(typ)
+	switch {
+	case n.tag == "input" && typ == "checkbox":
+		if isSelected(n) {
+			n.removeAttr("checked")
+		} else {
+			n.setAttr("checked", "")
+		}
+	case n.tag == "input" && typ == "radio":
+		name, _ := n.attr("name")
+		scope := closest(n, "form")
+		if scope == nil {
+			scope = n.root()
+		}
+		scope.walk(func(o *node) {
+			if t, _ := o.attr("type"); o.tag == "input" && strings.EqualFold(t, "radio") {
+				if other, _ := o.attr("name"); other == name {
+					o.removeAttr("checked")
+				}
+			}
+		})
+		n.setAttr("checked", "")
+	case n.tag == "option":
+		sel := closest(n, "select")
+		if sel == nil {
+			n.setAttr("selected", "")
+			break
+		}
+		if _, multiple := sel.attr("multiple"); multiple {
+			if isSelected(n) {
+				n.removeAttr("selected")
+			} else {
+				n.setAttr("selected", "")
+			}
+			break
+		}
+		sel.walk(func(o *node) { o.removeAttr("selected") })
+		n.setAttr("selected", "")
+	case n.tag == "button" && (typ == "" || typ == "submit"), n.tag == "input" && (typ == "submit" || typ == "image"):
+		if form := closest(n, "form"); form != nil {
+			return nil, sess.submit(form)
+		}
+	default:
+		if a := closest(n, "a"); a != nil {
+			if href, ok := a.attr("href"); ok && !strings.HasPrefix(strings.ToLower(href), "javascript:") {
+				return nil, sess.follow(href)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// follow navigates to href, which is relative to the current URL. Links to a
+// fragment of the current document do not reload it.
+func (sess *session) follow(href string) error {
+	w := sess.current
+	target := sess.resolve(href)
+	if strings.HasPrefix(href, "#") {
+		w.history = append(w.history[:w.index+1], target)
+		w.index++
+		return nil
+	}
+	return sess.navigate(target)
+}
+
+func (sess *session) resolve(ref string) string {
+	base, err := url.Parse(sess.current.url())
+	if err != nil {
+		return ref
+	}
+	u, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return u.String()
+}
+
+// submit navigates to the action of form. The fields of GET forms are sent
+// in the query string; POST forms are loaded without their payload.
+func (sess *session) submit(form *node) error {
+	action, ok := form.attr("action")
+	if !ok {
+		action = ""
+	}
+	target, err := url.Parse(sess.resolve(action))
+	if err != nil {
+		return newError(errInvalidArgument, "invalid form action %q", action)
+	}
+	if method, _ := form.attr("method"); !strings.EqualFold(method, "post") {
+		target.RawQuery = formValues(form).Encode()
+	}
+	target.Fragment = ""
+	return sess.navigate(target.String())
+}
+
+// formValues returns the values of the successful controls of form, except
+// for the submit buttons.
+func formValues(form *node) url.Values {
+	values := make(url.Values)
+	form.walk(func(n *node) {
+		name, ok := n.attr("name")
+		if !ok || name == "" || !isEnabled(n) {
+			return
+		}
+		typ, _ := n.attr("type")
+		switch n.tag {
+		case "input":
+			switch strings.ToLower(typ) {
+			case "submit", "image", "button", "reset", "file":
+				return
+			case "checkbox", "radio":
+				if !isSelected(n) {
+					return
+				}
+				v, ok := n.attr("value")
+				if !ok {
+					v = "on"
+				}
+				values.Add(name, v)
+				return
+			}
+			values.Add(name, value(n).(string))
+		case "textarea":
+			values.Add(name, n.textContent())
+		case "select":
+			n.walk(func(o *node) {
+				if o.tag == "option" && isSelected(o) {
+					values.Add(name, value(o).(string))
+				}
+			})
+		}
+	})
+	return values
+}
+
+// keys maps the code points of the WebDriver keys that edit text.
+const (
+	keyBackspace = '\ue003'
+	keyReturn    = '\ue006'
+	keyEnter     = '\ue007'
+)
+
+func (sess *session) elementSendKeys(args []string, body []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	var params struct {
+		Text  string
+		Value []string
+	}
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	if params.Text == "" {
+		params.Text = strings.Join(params.Value, "")
+	}
+	if !isEditable(n) {
+		return nil, newError(errElementNotInteractable, "element %s is not editable", args[0])
+	}
+	sess.current.active = n
+	if typ, _ := n.attr("type"); n.tag == "input" && strings.EqualFold(typ, "file") {
+		return nil, sess.setFiles(n, params.Text)
+	}
+
+	text := []rune(value(n).(string))
+	submit := false
+	for _, r := range params.Text {
+		switch {
+		case r == keyBackspace:
+			if len(text) > 0 {
+				text = text[:len(text)-1]
+			}
+		case r == keyReturn || r == keyEnter:
+			if n.tag == "textarea" {
+				text = append(text, '\n')
+			} else {
+				submit = true
+			}
+		case r >= '\ue000' && r <= '\uf8ff':
+			// Other special keys do not edit the text.
+		default:
+			text = append(text, r)
+		}
+	}
+	setValue(n, string(text))
+	if form := closest(n, "form"); submit && form != nil {
+		return nil, sess.submit(form)
+	}
+	return nil, nil
+}
+
+func (sess *session) elementClear(args []string, _ []byte) (interface{}, error) {
+	n, err := sess.element(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if !isEditable(n) {
+		return nil, newError(errElementNotInteractable, "element %s is not editable", args[0])
+	}
+	setValue(n, "")
+	return nil, nil
+}
+
+func (sess *session) elementScreenshot(args []string, _ []byte) (interface{}, error) {
+	if _, err := sess.element(args[0]); err != nil {
+		return nil, err
+	}
+	return screenshotPNG, nil
+}
+
+func (sess *session) pageSource([]string, []byte) (interface{}, error) {
+	w, err := sess.window()
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	renderHTML(&b, w.doc)
+	return b.String(), nil
+}
+
+func (sess *session) executeScript(_ []string, body []byte) (interface{}, error) {
+	var params struct {
+		Script string
+		Args   []interface{}
+	}
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	if _, err := sess.window(); err != nil {
+		return nil, err
+	}
+	f, ok := sess.server.scripts[strings.TrimSpace(params.Script)]
+	if !ok {
+		return nil, nil
+	}
+	return f(sess, params.Args)
+}
+
+// submitElement submits the form of the element passed to a script
+// registered with HandleSubmitScript.
+func (sess *session) submitElement(args []interface{}) error {
+	var ref map[string]interface{}
+	if len(args) > 0 {
+		ref, _ = args[0].(map[string]interface{})
+	}
+	id, _ := ref[webElementIdentifier].(string)
+	n, err := sess.element(id)
+	if err != nil {
+		return err
+	}
+	form := closest(n, "form")
+	if form == nil {
+		return newError(errJavascriptError, "the element is not in a form")
+	}
+	return sess.submit(form)
+}
+
+func (sess *session) getCookies([]string, []byte) (interface{}, error) {
+	if _, err := sess.window(); err != nil {
+		return nil, err
+	}
+	cookies := make([]cookie, len(sess.cookies))
+	copy(cookies, sess.cookies)
+	return cookies, nil
+}
+
+func (sess *session) getCookie(args []string, _ []byte) (interface{}, error) {
+	for _, c := range sess.cookies {
+		if c.Name == args[0] {
+			return c, nil
+		}
+	}
+	return nil, newError(errNoSuchCookie, "no cookie named %q", args[0])
+}
+
+func (sess *session) addCookie(_ []string, body []byte) (interface{}, error) {
+	var params struct{ Cookie *cookie }
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	if _, err := sess.window(); err != nil {
+		return nil, err
+	}
+	c := params.Cookie
+	if c == nil || c.Name == "" {
+		return nil, newError(errInvalidArgument, "missing cookie name")
+	}
+	if c.Path == "" {
+		c.Path = "/"
+	}
+	if c.Domain == "" {
+		if u, err := url.Parse(sess.current.url()); err == nil {
+			c.Domain = u.Hostname()
+		}
+	}
+	sess.deleteCookie([]string{c.Name}, nil)
+	sess.cookies = append(sess.cookies, *c)
+	return nil, nil
+}
+
+func (sess *session) deleteCookie(args []string, _ []byte) (interface{}, error) {
+	for i, c := range sess.cookies {
+		if c.Name == args[0] {
+			sess.cookies = append(sess.cookies[:i], sess.cookies[i+1:]...)
+			break
+		}
+	}
+	return nil, nil
+}
+
+func (sess *session) deleteCookies([]string, []byte) (interface{}, error) {
+	sess.cookies = nil
+	return nil, nil
+}
+
+// performActions accepts input actions without simulating them.
+func (sess *session) performActions([]string, []byte) (interface{}, error) {
+	_, err := sess.window()
+	return nil, err
+}
+
+func (sess *session) alert([]string, []byte) (interface{}, error) {
+	return nil, newError(errNoSuchAlert, "no alert is open")
+}
+
+func (sess *session) screenshot([]string, []byte) (interface{}, error) {
+	if _, err := sess.window(); err != nil {
+		return nil, err
+	}
+	return screenshotPNG, nil
+}
+
+// print returns a one-page PDF document whose media box has the requested
+// page size and orientation. The content of the page is not rendered.
+func (sess *session) print(_ []string, body []byte) (interface{}, error) {
+	if _, err := sess.window(); err != nil {
+		return nil, err
+	}
+	params := struct {
+		Orientation string
+		Scale       float64
+		Page        struct{ Width, Height float64 }
+		Margin      struct{ Top, Bottom, Left, Right float64 }
+		PageRanges  []interface{}
+	}{Scale: 1}
+	params.Page.Width, params.Page.Height = 21.59, 27.94
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	if params.Orientation != "" && params.Orientation != "portrait" && params.Orientation != "landscape" {
+		return nil, newError(errInvalidArgument, "invalid orientation %q", params.Orientation)
+	}
+	if params.Scale < 0.1 || params.Scale > 2 {
+		return nil, newError(errInvalidArgument, "invalid scale %v", params.Scale)
+	}
+	// The minimum page size is one point.
+	const minSize = 2.54 / 72
+	if params.Page.Width < minSize || params.Page.Height < minSize {
+		return nil, newError(errInvalidArgument, "invalid page size %vx%v", params.Page.Width, params.Page.Height)
+	}
+	m := params.Margin
+	if m.Top < 0 || m.Bottom < 0 || m.Left < 0 || m.Right < 0 {
+		return nil, newError(errInvalidArgument, "negative margin")
+	}
+	for _, r := range params.PageRanges {
+		switch r := r.(type) {
+		case float64:
+		case string:
+			if strings.Trim(r, "0123456789-") != "" || strings.Count(r, "-") > 1 {
+				return nil, newError(errInvalidArgument, "invalid page range %q", r)
+			}
+		default:
+			return nil, newError(errInvalidArgument, "invalid page range %v", r)
+		}
+	}
+
+	width, height := params.Page.Width*72/2.54, params.Page.Height*72/2.54
+	if params.Orientation == "landscape" {
+		width, height = height, width
+	}
+	pdf := fmt.Sprintf("%%PDF-1.4\n"+
+		"1 0 obj <</Type /Catalog /Pages 2 0 R>> endobj\n"+
+		"2 0 obj <</Type /Pages /Kids [3 0 R] /Count 1>> endobj\n"+
+		"3 0 obj <</Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f]>> endobj\n"+
+		"trailer <</Root 1 0 R>>\n%%%%EOF\n", width, height)
+	return base64.StdEncoding.EncodeToString([]byte(pdf)), nil
+}
+
+// screenshotPNG is the base64 encoding of the 1x1 PNG image returned for all
+// screenshots.
+var screenshotPNG = func() string {
+	var b bytes.Buffer
+	if err := png.Encode(&b, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(b.Bytes())
+}()
+
+// closest returns n or its nearest ancestor named tag.
+func closest(n *node, tag string) *node {
+	for ; n != nil; n = n.parent {
+		if n.typ == elementNode && n.tag == tag {
+			return n
+		}
+	}
+	return nil
+}
+
+func isSelected(n *node) bool {
+	var ok bool
+	switch n.tag {
+	case "option":
+		_, ok = n.attr("selected")
+	case "input":
+		_, ok = n.attr("checked")
+	}
+	return ok
+}
+
+var formControls = map[string]bool{
+	"button": true, "fieldset": true, "input": true, "optgroup": true,
+	"option": true, "select": true, "textarea": true,
+}
+
+// isEnabled reports whether n is not a disabled form control, nor within a
+// disabled fieldset or select.
+func isEnabled(n *node) bool {
+	if !formControls[n.tag] {
+		return true
+	}
+	for p := n; p != nil && p.typ == elementNode; p = p.parent {
+		if _, ok := p.attr("disabled"); ok && formControls[p.tag] {
+			return false
+		}
+	}
+	return true
+}
+
+func isEditable(n *node) bool {
+	if !isEnabled(n) {
+		return false
+	}
+	if _, ok := n.attr("readonly"); ok {
+		return false
+	}
+	switch n.tag {
+	case "textarea":
+		return true
+	case "input":
+		typ, _ := n.attr("type")
+		switch strings.ToLower(typ) {
+		case "checkbox", "radio", "submit", "button", "reset", "image", "hidden":
+			return false
+		}
+		return true
+	}
+	v, ok := n.attr("contenteditable")
+	return ok && v != "false"
+}
+
+// value returns the value of a form control, as the DOM property.
+func value(n *node) interface{} {
+	switch n.tag {
+	case "textarea":
+		return n.textContent()
+	case "select":
+		var v interface{} = ""
+		n.walk(func(o *node) {
+			if o.tag == "option" && isSelected(o) && v == "" {
+				v = value(o)
+			}
+		})
+		return v
+	case "option":
+		if v, ok := n.attr("value"); ok {
+			return v
+		}
+		return strings.Join(strings.Fields(n.textContent()), " ")
+	case "input":
+		v, _ := n.attr("value")
+		return v
+	}
+	if _, ok := n.attr("contenteditable"); ok {
+		return n.textContent()
+	}
+	if v, ok := n.attr("value"); ok {
+		return v
+	}
+	return nil
+}
+
+func setValue(n *node, v string) {
+	if n.tag == "input" {
+		n.setAttr("value", v)
+		return
+	}
+	n.children = nil
+	if v != "" {
+		n.appendChild(&node{typ: textNode, text: v})
+	}
+}
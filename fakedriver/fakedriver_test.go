@@ -0,0 +1,175 @@
+package fakedriver_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/injoyai/selenium"
+	"github.com/injoyai/selenium/fakedriver"
+)
+
+const (
+	homePage = `<html><head><title>Home</title></head><body>
+<h1 id="greeting">Hello, <em>world</em></h1>
+<a href="/login">Sign in</a>
+</body></html>`
+	loginPage = `<title>Login</title>
+<form action="/search">
+  <input name="user" id="user">
+  <label><input type="checkbox" name="remember"> Remember me</label>
+  <button type="submit">Go</button>
+</form>`
+)
+
+func newDriver(t *testing.T) (*fakedriver.Server, *selenium.WebDriver) {
+	s := fakedriver.New(map[string]string{
+		"http://example.com/":       homePage,
+		"http://example.com/login":  loginPage,
+		"http://example.com/search": `<title>Results</title>`,
+	})
+	t.Cleanup(s.Close)
+	wd, err := selenium.NewRemote(nil, s.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	t.Cleanup(func() { wd.Quit() })
+	return s, wd
+}
+
+func TestNavigationAndFind(t *testing.T) {
+	_, wd := newDriver(t)
+	if err := wd.Get("http://example.com/"); err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	if title, err := wd.Title(); err != nil || title != "Home" {
+		t.Fatalf("Title() = %q, %v, want %q", title, err, "Home")
+	}
+
+	for _, by := range []struct{ by, value string }{
+		{selenium.ByID, "greeting"},
+		{selenium.ByCSSSelector, "h1#greeting"},
+		{selenium.ByXPATH, "//h1[em='world']"},
+		{selenium.ByTagName, "h1"},
+	} {
+		elem, err := wd.FindElement(by.by, by.value)
+		if err != nil {
+			t.Errorf("FindElement(%q, %q) returned error: %v", by.by, by.value, err)
+			continue
+		}
+		if text, err := elem.Text(); err != nil || text != "Hello, world" {
+			t.Errorf("Text() = %q, %v, want %q", text, err, "Hello, world")
+		}
+	}
+	if _, err := wd.FindElement(selenium.ByCSSSelector, "table"); !errors.Is(err, selenium.ErrNoSuchElement) {
+		t.Errorf("FindElement() of a missing element returned error %v, want %v", err, selenium.ErrNoSuchElement)
+	}
+
+	link, err := wd.FindElement(selenium.ByLinkText, "Sign in")
+	if err != nil {
+		t.Fatalf("FindElement(ByLinkText) returned error: %v", err)
+	}
+	if err := link.Click(); err != nil {
+		t.Fatalf("Click() returned error: %v", err)
+	}
+	if u, err := wd.CurrentURL(); err != nil || u != "http://example.com/login" {
+		t.Errorf("CurrentURL() = %q, %v, want %q", u, err, "http://example.com/login")
+	}
+	if _, err := link.Text(); !errors.Is(err, selenium.ErrStaleElementReference) {
+		t.Errorf("Text() of an element of the previous page returned error %v, want %v", err, selenium.ErrStaleElementReference)
+	}
+
+	if err := wd.Back(); err != nil {
+		t.Fatalf("Back() returned error: %v", err)
+	}
+	if title, err := wd.Title(); err != nil || title != "Home" {
+		t.Errorf("Title() after Back() = %q, %v, want %q", title, err, "Home")
+	}
+}
+
+func TestForms(t *testing.T) {
+	_, wd := newDriver(t)
+	if err := wd.Get("http://example.com/login"); err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	user, err := wd.FindElement(selenium.ByName, "user")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	if err := user.SendKeys("alice"); err != nil {
+		t.Fatalf("SendKeys() returned error: %v", err)
+	}
+	if v, err := user.GetProperty("value"); err != nil || v != "alice" {
+		t.Errorf("GetProperty(value) = %q, %v, want %q", v, err, "alice")
+	}
+	remember, err := wd.FindElement(selenium.ByCSSSelector, "input[type=checkbox]")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	if err := remember.Click(); err != nil {
+		t.Fatalf("Click() returned error: %v", err)
+	}
+	if ok, err := remember.IsSelected(); err != nil || !ok {
+		t.Errorf("IsSelected() = %t, %v, want true", ok, err)
+	}
+
+	button, err := wd.FindElement(selenium.ByXPATH, "//button")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	if err := button.Click(); err != nil {
+		t.Fatalf("Click() returned error: %v", err)
+	}
+	want := "http://example.com/search?remember=on&user=alice"
+	if u, err := wd.CurrentURL(); err != nil || u != want {
+		t.Errorf("CurrentURL() = %q, %v, want %q", u, err, want)
+	}
+}
+
+func TestCookiesWindowsAndScripts(t *testing.T) {
+	s, wd := newDriver(t)
+	if err := wd.Get("http://example.com/"); err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+
+	if err := wd.AddCookie(&selenium.Cookie{Name: "a", Value: "1"}); err != nil {
+		t.Fatalf("AddCookie() returned error: %v", err)
+	}
+	cookies, err := wd.GetCookies()
+	if err != nil || len(cookies) != 1 || cookies[0].Value != "1" || cookies[0].Domain != "example.com" {
+		t.Errorf("GetCookies() = %+v, %v, want cookie a=1 for example.com", cookies, err)
+	}
+	if err := wd.DeleteCookie("a"); err != nil {
+		t.Fatalf("DeleteCookie() returned error: %v", err)
+	}
+	if _, err := wd.GetCookie("a"); !errors.Is(err, selenium.ErrNoSuchCookie) {
+		t.Errorf("GetCookie() of a deleted cookie returned error %v, want %v", err, selenium.ErrNoSuchCookie)
+	}
+
+	first, err := wd.CurrentWindowHandle()
+	if err != nil {
+		t.Fatalf("CurrentWindowHandle() returned error: %v", err)
+	}
+	if err := wd.ResizeWindow("", 640, 480); err != nil {
+		t.Fatalf("ResizeWindow() returned error: %v", err)
+	}
+	if err := wd.CloseWindow(first); err != nil {
+		t.Fatalf("CloseWindow() returned error: %v", err)
+	}
+	// Closing the last window ends the session.
+	if _, err := wd.Title(); !errors.Is(err, selenium.ErrInvalidSessionID) {
+		t.Errorf("Title() after CloseWindow() returned error %v, want %v", err, selenium.ErrInvalidSessionID)
+	}
+
+	if wd, err = selenium.NewRemote(nil, s.URL); err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	s.HandleScript("return arguments[0] + 1", func(args []interface{}) (interface{}, error) {
+		return args[0].(float64) + 1, nil
+	})
+	if v, err := wd.ExecuteScript("return arguments[0] + 1", []interface{}{41}); err != nil || v != 42.0 {
+		t.Errorf("ExecuteScript() = %v, %v, want 42", v, err)
+	}
+	if v, err := wd.ExecuteScript("return window.unknown", nil); err != nil || v != nil {
+		t.Errorf("ExecuteScript() of an unknown script = %v, %v, want nil", v, err)
+	}
+}
@@ -0,0 +1,577 @@
+package fakedriver
+
+import (
+	"html"
+	"strings"
+)
+
+type nodeType int
+
+const (
+	documentNode nodeType = iota
+	elementNode
+	textNode
+	commentNode
+	attributeNode
+	shadowRootNode
+)
+
+type attribute struct {
+	key, val string
+}
+
+// node is a node of a parsed document. Attributes are only represented as
+// nodes while evaluating XPath expressions.
+type node struct {
+	typ      nodeType
+	tag      string
+	attrs    []attribute
+	text     string
+	parent   *node
+	children []*node
+	// shadow is the shadow root of an element, if any. host is the element
+	// of a shadow root.
+	shadow, host *node
+}
+
+func (n *node) attr(key string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.key == key {
+			return a.val, true
+		}
+	}
+	return "", false
+}
+
+func (n *node) setAttr(key, val string) {
+	for i, a := range n.attrs {
+		if a.key == key {
+			n.attrs[i].val = val
+			return
+		}
+	}
+	n.attrs = append(n.attrs, attribute{key, val})
+}
+
+func (n *node) removeAttr(key string) {
+	for i, a := range n.attrs {
+		if a.key == key {
+			n.attrs = append(n.attrs[:i], n.attrs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (n *node) hasClass(class string) bool {
+	v, _ := n.attr("class")
+	for _, c := range strings.Fields(v) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *node) appendChild(c *node) {
+	c.parent = n
+	n.children = append(n.children, c)
+}
+
+// root returns the document that contains n, through the hosts of the
+// shadow roots that contain it.
+func (n *node) root() *node {
+	for {
+		switch {
+		case n.parent != nil:
+			n = n.parent
+		case n.host != nil:
+			n = n.host
+		default:
+			return n
+		}
+	}
+}
+
+// elements returns the element children of n.
+func (n *node) elements() []*node {
+	var elems []*node
+	for _, c := range n.children {
+		if c.typ == elementNode {
+			elems = append(elems, c)
+		}
+	}
+	return elems
+}
+
+// walk calls f for every descendant element of n in document order.
+func (n *node) walk(f func(*node)) {
+	for _, c := range n.children {
+		if c.typ == elementNode {
+			f(c)
+			c.walk(f)
+		}
+	}
+}
+
+// find returns the first descendant element named tag.
+func (n *node) find(tag string) *node {
+	var found *node
+	n.walk(func(c *node) {
+		if found == nil && c.tag == tag {
+			found = c
+		}
+	})
+	return found
+}
+
+// textContent returns the concatenated text of n and its descendants, as the
+// DOM property of the same name.
+func (n *node) textContent() string {
+	switch n.typ {
+	case textNode, attributeNode:
+		return n.text
+	case commentNode:
+		return ""
+	}
+	var b strings.Builder
+	for _, c := range n.children {
+		b.WriteString(c.textContent())
+	}
+	return b.String()
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+var rawTextElements = map[string]bool{
+	"script": true, "style": true, "textarea": true, "title": true,
+}
+
+// impliedEnd lists, for a start tag, the open elements that the tag closes
+// and the elements that bound the search.
+var impliedEnd = map[string]struct{ closes, scope []string }{
+	"li":     {[]string{"li"}, []string{"ul", "ol"}},
+	"option": {[]string{"option"}, []string{"select", "datalist", "optgroup"}},
+	"dt":     {[]string{"dt", "dd"}, []string{"dl"}},
+	"dd":     {[]string{"dt", "dd"}, []string{"dl"}},
+	"tr":     {[]string{"tr", "td", "th"}, []string{"table", "thead", "tbody", "tfoot"}},
+	"td":     {[]string{"td", "th"}, []string{"tr", "table"}},
+	"th":     {[]string{"td", "th"}, []string{"tr", "table"}},
+}
+
+var closesParagraph = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"div": true, "dl": true, "fieldset": true, "footer": true, "form": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "ul": true,
+}
+
+// parseHTML parses a document leniently. It does not implement the HTML5
+// tree construction algorithm, but it handles the common omissions of end
+// tags, and always returns a document with html, head and body elements.
+func parseHTML(s string) *node {
+	doc := &node{typ: documentNode}
+	stack := []*node{doc}
+	top := func() *node { return stack[len(stack)-1] }
+	closeTo := func(i int) { stack = stack[:i] }
+	// openIndex returns the position of the innermost open element named one
+	// of tags, not searching past an element named one of scope.
+	openIndex := func(tags, scope []string) int {
+		for i := len(stack) - 1; i > 0; i-- {
+			for _, t := range tags {
+				if stack[i].tag == t {
+					return i
+				}
+			}
+			for _, t := range scope {
+				if stack[i].tag == t {
+					return -1
+				}
+			}
+		}
+		return -1
+	}
+
+	for len(s) > 0 {
+		i := strings.IndexByte(s, '<')
+		if i < 0 {
+			i = len(s)
+		}
+		if i > 0 {
+			top().appendChild(&node{typ: textNode, text: html.UnescapeString(s[:i])})
+			s = s[i:]
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(s, "<!--"):
+			end := strings.Index(s[4:], "-->")
+			if end < 0 {
+				end = len(s) - 4
+			}
+			top().appendChild(&node{typ: commentNode, text: s[4 : 4+end]})
+			s = s[min(len(s), 4+end+3):]
+		case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				end = len(s) - 1
+			}
+			s = s[end+1:]
+		case strings.HasPrefix(s, "</"):
+			end := strings.IndexByte(s, '>')
+			if end < 0 {
+				end = len(s) - 1
+			}
+			tag := strings.ToLower(strings.TrimSpace(s[2:end]))
+			s = s[end+1:]
+			if i := openIndex([]string{tag}, nil); i > 0 {
+				closeTo(i)
+			}
+		default:
+			n, rest, selfClosing, ok := parseStartTag(s)
+			if !ok {
+				top().appendChild(&node{typ: textNode, text: "<"})
+				s = s[1:]
+				continue
+			}
+			s = rest
+			if rule, ok := impliedEnd[n.tag]; ok {
+				if i := openIndex(rule.closes, rule.scope); i > 0 {
+					closeTo(i)
+				}
+			}
+			if closesParagraph[n.tag] {
+				if i := openIndex([]string{"p"}, []string{"button", "div", "section", "article", "form"}); i > 0 {
+					closeTo(i)
+				}
+			}
+			top().appendChild(n)
+			if selfClosing || voidElements[n.tag] {
+				continue
+			}
+			if rawTextElements[n.tag] {
+				end := strings.Index(strings.ToLower(s), "</"+n.tag)
+				if end < 0 {
+					end = len(s)
+				}
+				if end > 0 {
+					text := s[:end]
+					if n.tag != "script" && n.tag != "style" {
+						text = html.UnescapeString(text)
+					}
+					n.appendChild(&node{typ: textNode, text: text})
+				}
+				s = s[end:]
+				if gt := strings.IndexByte(s, '>'); gt >= 0 {
+					s = s[gt+1:]
+				}
+				continue
+			}
+			stack = append(stack, n)
+		}
+	}
+	attachShadowRoots(doc)
+	return normalizeDocument(doc)
+}
+
+// attachShadowRoots turns the declarative shadow roots below n, template
+// elements with a shadowrootmode attribute, into the shadow roots of their
+// parent elements.
+func attachShadowRoots(n *node) {
+	children := n.children[:0]
+	for _, c := range n.children {
+		if _, ok := c.attr("shadowrootmode"); ok && c.tag == "template" && n.typ == elementNode && n.shadow == nil {
+			root := &node{typ: shadowRootNode, host: n}
+			for _, gc := range c.children {
+				root.appendChild(gc)
+			}
+			n.shadow = root
+			attachShadowRoots(root)
+			continue
+		}
+		attachShadowRoots(c)
+		children = append(children, c)
+	}
+	n.children = children
+}
+
+// parseStartTag parses the start tag at the beginning of s.
+func parseStartTag(s string) (n *node, rest string, selfClosing, ok bool) {
+	i := 1
+	for i < len(s) && isNameByte(s[i]) {
+		i++
+	}
+	if i == 1 {
+		return nil, s, false, false
+	}
+	n = &node{typ: elementNode, tag: strings.ToLower(s[1:i])}
+	for {
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			return n, "", false, true
+		}
+		switch s[i] {
+		case '>':
+			return n, s[i+1:], false, true
+		case '/':
+			i++
+			if i < len(s) && s[i] == '>' {
+				return n, s[i+1:], true, true
+			}
+			continue
+		}
+
+		start := i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+		key := strings.ToLower(s[start:i])
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) || s[i] != '=' {
+			n.attrs = append(n.attrs, attribute{key, ""})
+			continue
+		}
+		i++
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		var val string
+		if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+			q := s[i]
+			end := strings.IndexByte(s[i+1:], q)
+			if end < 0 {
+				end = len(s) - i - 1
+			}
+			val = s[i+1 : i+1+end]
+			i = min(len(s), i+end+2)
+		} else {
+			start := i
+			for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+				i++
+			}
+			val = s[start:i]
+		}
+		if _, dup := n.attr(key); !dup {
+			n.attrs = append(n.attrs, attribute{key, html.UnescapeString(val)})
+		}
+	}
+}
+
+// normalizeDocument makes sure that the document has an html element with
+// a head and a body.
+func normalizeDocument(doc *node) *node {
+	root := doc.find("html")
+	if root == nil || root.parent != doc {
+		root = &node{typ: elementNode, tag: "html"}
+		children := doc.children
+		doc.children = nil
+		for _, c := range children {
+			if c.typ == commentNode {
+				doc.appendChild(c)
+				continue
+			}
+			root.appendChild(c)
+		}
+		doc.appendChild(root)
+	}
+
+	var head, body *node
+	for _, c := range root.elements() {
+		switch c.tag {
+		case "head":
+			head = c
+		case "body":
+			body = c
+		}
+	}
+	if head == nil {
+		head = &node{typ: elementNode, tag: "head", parent: root}
+		root.children = append([]*node{head}, root.children...)
+	}
+	if body == nil {
+		body = &node{typ: elementNode, tag: "body"}
+		children := root.children
+		root.children = nil
+		for _, c := range children {
+			switch {
+			case c == head:
+				root.appendChild(c)
+			case c.typ == elementNode && (c.tag == "title" || c.tag == "meta" || c.tag == "link" || c.tag == "base"):
+				head.appendChild(c)
+			default:
+				body.appendChild(c)
+			}
+		}
+		root.appendChild(body)
+	}
+	return doc
+}
+
+// renderHTML serializes n and its descendants.
+func renderHTML(b *strings.Builder, n *node) {
+	switch n.typ {
+	case documentNode:
+		b.WriteString("<!DOCTYPE html>")
+		for _, c := range n.children {
+			renderHTML(b, c)
+		}
+	case textNode:
+		if n.parent != nil && (n.parent.tag == "script" || n.parent.tag == "style") {
+			b.WriteString(n.text)
+		} else {
+			b.WriteString(html.EscapeString(n.text))
+		}
+	case commentNode:
+		b.WriteString("<!--" + n.text + "-->")
+	case elementNode:
+		b.WriteString("<" + n.tag)
+		for _, a := range n.attrs {
+			b.WriteString(" " + a.key + `="` + html.EscapeString(a.val) + `"`)
+		}
+		b.WriteString(">")
+		if voidElements[n.tag] {
+			return
+		}
+		for _, c := range n.children {
+			renderHTML(b, c)
+		}
+		b.WriteString("</" + n.tag + ">")
+	}
+}
+
+func innerHTML(n *node) string {
+	var b strings.Builder
+	for _, c := range n.children {
+		renderHTML(&b, c)
+	}
+	return b.String()
+}
+
+func outerHTML(n *node) string {
+	var b strings.Builder
+	renderHTML(&b, n)
+	return b.String()
+}
+
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "fieldset": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true,
+	"h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true,
+	"li": true, "main": true, "nav": true, "ol": true, "option": true, "p": true,
+	"pre": true, "section": true, "table": true, "tr": true, "ul": true,
+}
+
+// renderedText approximates the text that a browser renders for n: hidden
+// elements are skipped, white space is collapsed and block elements are
+// separated by new lines.
+func renderedText(n *node) string {
+	var lines []string
+	var line strings.Builder
+	flush := func() {
+		if s := strings.Join(strings.Fields(line.String()), " "); s != "" {
+			lines = append(lines, s)
+		}
+		line.Reset()
+	}
+	var visit func(*node)
+	visit = func(n *node) {
+		switch n.typ {
+		case textNode:
+			line.WriteString(n.text)
+			return
+		case elementNode:
+			if !isDisplayed(n) {
+				return
+			}
+			if n.tag == "br" {
+				flush()
+				return
+			}
+		default:
+			return
+		}
+		block := blockElements[n.tag]
+		if block {
+			flush()
+		}
+		if n.tag == "td" || n.tag == "th" {
+			line.WriteString(" ")
+		}
+		for _, c := range n.children {
+			visit(c)
+		}
+		if block {
+			flush()
+		}
+	}
+	visit(n)
+	flush()
+	return strings.Join(lines, "\n")
+}
+
+var hiddenElements = map[string]bool{
+	"head": true, "script": true, "style": true, "template": true,
+	"title": true, "meta": true, "link": true, "noscript": true,
+}
+
+// isDisplayed reports whether n and its ancestors are rendered, based on the
+// hidden attribute, the type of inputs and inline display styles.
+func isDisplayed(n *node) bool {
+	for ; n != nil && n.typ == elementNode; n = n.parent {
+		if hiddenElements[n.tag] {
+			return false
+		}
+		if _, ok := n.attr("hidden"); ok {
+			return false
+		}
+		if t, _ := n.attr("type"); n.tag == "input" && strings.EqualFold(t, "hidden") {
+			return false
+		}
+		if d := inlineStyle(n, "display"); d == "none" {
+			return false
+		}
+		if v := inlineStyle(n, "visibility"); v == "hidden" {
+			return false
+		}
+	}
+	return true
+}
+
+// inlineStyle returns the value of a property set by the style attribute.
+func inlineStyle(n *node, property string) string {
+	style, _ := n.attr("style")
+	for _, decl := range strings.Split(style, ";") {
+		i := strings.IndexByte(decl, ':')
+		if i < 0 {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSpace(decl[:i]), property) {
+			return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(decl[i+1:]), "!important"))
+		}
+	}
+	return ""
+}
+
+func isNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == ':'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
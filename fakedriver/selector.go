@@ -0,0 +1,416 @@
+package fakedriver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// selector is a parsed group of CSS selectors. The supported syntax covers
+// type, universal, ID, class and attribute selectors, the descendant, child
+// and sibling combinators and a few structural pseudo-classes.
+type selector []complexSelector
+
+// complexSelector is a sequence of compound selectors, stored from right to
+// left, each with the combinator that joins it to the next one.
+type complexSelector []struct {
+	compound   compoundSelector
+	combinator byte // ' ', '>', '+' or '~'; 0 for the leftmost.
+}
+
+type compoundSelector []func(*node) bool
+
+func parseSelector(s string) (selector, error) {
+	p := &selectorParser{s: s}
+	sel, err := p.parseGroup()
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector %q: %v", s, err)
+	}
+	return sel, nil
+}
+
+// match reports whether n matches any selector of the group.
+func (sel selector) match(n *node) bool {
+	for _, c := range sel {
+		if c.match(n, 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectAll returns the descendants of scope that match sel.
+func (sel selector) selectAll(scope *node) []*node {
+	var found []*node
+	scope.walk(func(n *node) {
+		if sel.match(n) {
+			found = append(found, n)
+		}
+	})
+	return found
+}
+
+func (c complexSelector) match(n *node, i int) bool {
+	if !c[i].compound.match(n) {
+		return false
+	}
+	if i == len(c)-1 {
+		return true
+	}
+	switch c[i].combinator {
+	case '>':
+		return isElement(n.parent) && c.match(n.parent, i+1)
+	case ' ':
+		for p := n.parent; isElement(p); p = p.parent {
+			if c.match(p, i+1) {
+				return true
+			}
+		}
+	case '+':
+		if prev := previousSibling(n); prev != nil {
+			return c.match(prev, i+1)
+		}
+	case '~':
+		for prev := previousSibling(n); prev != nil; prev = previousSibling(prev) {
+			if c.match(prev, i+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c compoundSelector) match(n *node) bool {
+	for _, f := range c {
+		if !f(n) {
+			return false
+		}
+	}
+	return true
+}
+
+func isElement(n *node) bool {
+	return n != nil && n.typ == elementNode
+}
+
+func previousSibling(n *node) *node {
+	if n.parent == nil {
+		return nil
+	}
+	var prev *node
+	for _, c := range n.parent.elements() {
+		if c == n {
+			return prev
+		}
+		prev = c
+	}
+	return nil
+}
+
+// elementIndex returns the 1-based position of n among its element siblings,
+// and the number of siblings.
+func elementIndex(n *node) (int, int) {
+	if n.parent == nil {
+		return 1, 1
+	}
+	siblings := n.parent.elements()
+	for i, c := range siblings {
+		if c == n {
+			return i + 1, len(siblings)
+		}
+	}
+	return 0, len(siblings)
+}
+
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+func (p *selectorParser) parseGroup() (selector, error) {
+	var sel selector
+	for {
+		c, err := p.parseComplex()
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, c)
+		p.skipSpace()
+		if p.pos == len(p.s) {
+			return sel, nil
+		}
+		if p.s[p.pos] != ',' {
+			return nil, fmt.Errorf("unexpected %q", p.s[p.pos:])
+		}
+		p.pos++
+	}
+}
+
+func (p *selectorParser) parseComplex() (complexSelector, error) {
+	var c complexSelector
+	p.skipSpace()
+	var combinator byte
+	for {
+		compound, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		c = append(c, struct {
+			compound   compoundSelector
+			combinator byte
+		}{compound, 0})
+		if len(c) > 1 {
+			c[len(c)-2].combinator = combinator
+		}
+
+		hadSpace := p.skipSpace()
+		if p.pos == len(p.s) || p.s[p.pos] == ',' || p.s[p.pos] == ')' {
+			break
+		}
+		switch p.s[p.pos] {
+		case '>', '+', '~':
+			combinator = p.s[p.pos]
+			p.pos++
+			p.skipSpace()
+		default:
+			if !hadSpace {
+				return nil, fmt.Errorf("unexpected %q", p.s[p.pos:])
+			}
+			combinator = ' '
+		}
+	}
+	// Store from right to left, each compound with the combinator to its
+	// left neighbour.
+	r := make(complexSelector, len(c))
+	for i := range c {
+		r[i].compound = c[len(c)-1-i].compound
+		if i < len(c)-1 {
+			r[i].combinator = c[len(c)-2-i].combinator
+		}
+	}
+	return r, nil
+}
+
+func (p *selectorParser) parseCompound() (compoundSelector, error) {
+	var c compoundSelector
+	if p.pos < len(p.s) && p.s[p.pos] == '*' {
+		p.pos++
+		c = append(c, isElement)
+	} else if name := p.parseIdent(); name != "" {
+		name = strings.ToLower(name)
+		c = append(c, func(n *node) bool { return n.tag == name })
+	}
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case '#':
+			p.pos++
+			id := p.parseIdent()
+			if id == "" {
+				return nil, fmt.Errorf("expected an ID at %d", p.pos)
+			}
+			c = append(c, func(n *node) bool {
+				v, ok := n.attr("id")
+				return ok && v == id
+			})
+		case '.':
+			p.pos++
+			class := p.parseIdent()
+			if class == "" {
+				return nil, fmt.Errorf("expected a class name at %d", p.pos)
+			}
+			c = append(c, func(n *node) bool { return n.hasClass(class) })
+		case '[':
+			f, err := p.parseAttribute()
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, f)
+		case ':':
+			f, err := p.parsePseudo()
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, f)
+		default:
+			if len(c) == 0 {
+				return nil, fmt.Errorf("expected a selector at %q", p.s[p.pos:])
+			}
+			return c, nil
+		}
+	}
+	if len(c) == 0 {
+		return nil, fmt.Errorf("empty selector")
+	}
+	return c, nil
+}
+
+func (p *selectorParser) parseAttribute() (func(*node) bool, error) {
+	p.pos++ // '['
+	p.skipSpace()
+	key := strings.ToLower(p.parseIdent())
+	if key == "" {
+		return nil, fmt.Errorf("expected an attribute name at %d", p.pos)
+	}
+	p.skipSpace()
+	if p.pos < len(p.s) && p.s[p.pos] == ']' {
+		p.pos++
+		return func(n *node) bool {
+			_, ok := n.attr(key)
+			return ok
+		}, nil
+	}
+
+	var op string
+	for _, o := range []string{"=", "~=", "|=", "^=", "$=", "*="} {
+		if strings.HasPrefix(p.s[p.pos:], o) {
+			op = o
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("expected an attribute operator at %d", p.pos)
+	}
+	p.pos += len(op)
+	p.skipSpace()
+	val, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos >= len(p.s) || p.s[p.pos] != ']' {
+		return nil, fmt.Errorf("expected ] at %d", p.pos)
+	}
+	p.pos++
+
+	test := map[string]func(string) bool{
+		"=": func(v string) bool { return v == val },
+		"~=": func(v string) bool {
+			for _, f := range strings.Fields(v) {
+				if f == val {
+					return true
+				}
+			}
+			return false
+		},
+		"|=": func(v string) bool { return v == val || strings.HasPrefix(v, val+"-") },
+		"^=": func(v string) bool { return val != "" && strings.HasPrefix(v, val) },
+		"$=": func(v string) bool { return val != "" && strings.HasSuffix(v, val) },
+		"*=": func(v string) bool { return val != "" && strings.Contains(v, val) },
+	}[op]
+	return func(n *node) bool {
+		v, ok := n.attr(key)
+		return ok && test(v)
+	}, nil
+}
+
+func (p *selectorParser) parsePseudo() (func(*node) bool, error) {
+	p.pos++ // ':'
+	name := strings.ToLower(p.parseIdent())
+	switch name {
+	case "first-child":
+		return func(n *node) bool { i, _ := elementIndex(n); return i == 1 }, nil
+	case "last-child":
+		return func(n *node) bool { i, count := elementIndex(n); return i == count }, nil
+	case "only-child":
+		return func(n *node) bool { _, count := elementIndex(n); return count == 1 }, nil
+	case "checked":
+		return func(n *node) bool { return isSelected(n) }, nil
+	case "disabled":
+		return func(n *node) bool { return !isEnabled(n) }, nil
+	case "enabled":
+		return isEnabled, nil
+	case "nth-child", "not":
+	default:
+		return nil, fmt.Errorf("unsupported pseudo-class %q", name)
+	}
+
+	if p.pos >= len(p.s) || p.s[p.pos] != '(' {
+		return nil, fmt.Errorf("expected ( after :%s", name)
+	}
+	p.pos++
+	p.skipSpace()
+	var f func(*node) bool
+	if name == "not" {
+		c, err := p.parseCompound()
+		if err != nil {
+			return nil, err
+		}
+		f = func(n *node) bool { return !c.match(n) }
+	} else {
+		start := p.pos
+		for p.pos < len(p.s) && p.s[p.pos] != ')' {
+			p.pos++
+		}
+		arg := strings.TrimSpace(p.s[start:p.pos])
+		switch arg {
+		case "odd":
+			f = func(n *node) bool { i, _ := elementIndex(n); return i%2 == 1 }
+		case "even":
+			f = func(n *node) bool { i, _ := elementIndex(n); return i%2 == 0 }
+		default:
+			want, err := strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("unsupported argument of :nth-child: %q", arg)
+			}
+			f = func(n *node) bool { i, _ := elementIndex(n); return i == want }
+		}
+	}
+	p.skipSpace()
+	if p.pos >= len(p.s) || p.s[p.pos] != ')' {
+		return nil, fmt.Errorf("expected ) at %d", p.pos)
+	}
+	p.pos++
+	return f, nil
+}
+
+func (p *selectorParser) parseValue() (string, error) {
+	if p.pos < len(p.s) && (p.s[p.pos] == '"' || p.s[p.pos] == '\'') {
+		q := p.s[p.pos]
+		var b strings.Builder
+		for p.pos++; p.pos < len(p.s); p.pos++ {
+			switch c := p.s[p.pos]; c {
+			case q:
+				p.pos++
+				return b.String(), nil
+			case '\\':
+				if p.pos+1 < len(p.s) {
+					p.pos++
+					b.WriteByte(p.s[p.pos])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated string")
+	}
+	v := p.parseIdent()
+	if v == "" {
+		return "", fmt.Errorf("expected a value at %d", p.pos)
+	}
+	return v, nil
+}
+
+func (p *selectorParser) parseIdent() string {
+	var b strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.s):
+			b.WriteByte(p.s[p.pos+1])
+			p.pos += 2
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c >= 0x80:
+			b.WriteByte(c)
+			p.pos++
+		default:
+			return b.String()
+		}
+	}
+	return b.String()
+}
+
+func (p *selectorParser) skipSpace() bool {
+	start := p.pos
+	for p.pos < len(p.s) && isSpace(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.pos > start
+}
@@ -0,0 +1,147 @@
+package fakedriver
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDocument = `<!DOCTYPE html>
+<html>
+<head><title>Test page</title></head>
+<body>
+  <div id="main" class="content wide">
+    <h1>Title</h1>
+    <p class="intro">First <b>bold</b> paragraph
+    <p>Second paragraph
+    <ul>
+      <li>one
+      <li class="x">two
+      <li data-n="3">three
+    </ul>
+    <a href="/next" id="next">Next page</a>
+    <input type="hidden" name="token" value="t">
+  </div>
+  <form id="f" action="/search">
+    <input name="q" value="go">
+    <select name="lang"><option value="en">English<option value="fr" selected>French</select>
+  </form>
+</body>
+</html>`
+
+// describe returns a short description of nodes, for comparisons.
+func describe(nodes []*node) string {
+	var parts []string
+	for _, n := range nodes {
+		s := n.tag
+		if id, ok := n.attr("id"); ok {
+			s += "#" + id
+		}
+		if t := strings.TrimSpace(n.textContent()); t != "" && len(n.elements()) == 0 {
+			s += "(" + strings.Join(strings.Fields(t), " ") + ")"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestSelector(t *testing.T) {
+	doc := parseHTML(testDocument)
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{"h1", "h1(Title)"},
+		{"#main > h1", "h1(Title)"},
+		{"div.content.wide h1", "h1(Title)"},
+		{"li", "li(one) li(two) li(three)"},
+		{"ul > li.x", "li(two)"},
+		{"li[data-n='3']", "li(three)"},
+		{"li[data-n]", "li(three)"},
+		{"li:first-child, li:last-child", "li(one) li(three)"},
+		{"li:nth-child(2)", "li(two)"},
+		{"li:not(.x)", "li(one) li(three)"},
+		{"li.x + li", "li(three)"},
+		{"h1 ~ ul", "ul"},
+		{"a[href^='/n']", "a#next(Next page)"},
+		{"input[name=q]", "input"},
+		{"option:checked", "option(French)"},
+		{"p", "p p(Second paragraph)"},
+		{"#nothing", ""},
+	}
+	for _, test := range tests {
+		sel, err := parseSelector(test.selector)
+		if err != nil {
+			t.Errorf("parseSelector(%q) returned error: %v", test.selector, err)
+			continue
+		}
+		if got := describe(sel.selectAll(doc)); got != test.want {
+			t.Errorf("selector %q selected %q, want %q", test.selector, got, test.want)
+		}
+	}
+
+	for _, s := range []string{"", "div >", "[", "a[href", "li:hover", "#"} {
+		if _, err := parseSelector(s); err == nil {
+			t.Errorf("parseSelector(%q) did not return an error", s)
+		}
+	}
+}
+
+func TestXPath(t *testing.T) {
+	doc := parseHTML(testDocument)
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"/html/head/title", "title(Test page)"},
+		{"//h1", "h1(Title)"},
+		{"//li[2]", "li(two)"},
+		{"//li[last()]", "li(three)"},
+		{"(//li)[1]", "li(one)"},
+		{"//li[@class='x']", "li(two)"},
+		{"//li[@data-n]", "li(three)"},
+		{"//li[contains(text(), 'hre')]", "li(three)"},
+		{"//li[normalize-space()='two']", "li(two)"},
+		{"//a[text()='Next page']", "a#next(Next page)"},
+		{"//a[starts-with(@href, '/')]/@href/..", "a#next(Next page)"},
+		{"//b/parent::p/following-sibling::p", "p(Second paragraph)"},
+		{"//li[@class='x']/preceding-sibling::li", "li(one)"},
+		{"//div[@id='main']//li[position() > 1 and not(@class)]", "li(three)"},
+		{"//h1 | //a", "h1(Title) a#next(Next page)"},
+		{"//*[@id='f']/select/option[@selected]", "option(French)"},
+		{"//ul[count(li) = 3]", "ul"},
+		{"//li[normalize-space(.) = 'one' or normalize-space(.) = 'two']", "li(one) li(two)"},
+		{"//table", ""},
+	}
+	for _, test := range tests {
+		nodes, err := evalXPath(test.expr, doc)
+		if err != nil {
+			t.Errorf("evalXPath(%q) returned error: %v", test.expr, err)
+			continue
+		}
+		if got := describe(nodes); got != test.want {
+			t.Errorf("XPath %q selected %q, want %q", test.expr, got, test.want)
+		}
+	}
+
+	ul, _ := evalXPath("//ul", doc)
+	nodes, err := evalXPath("./li[1]", ul[0])
+	if err != nil || describe(nodes) != "li(one)" {
+		t.Errorf("relative XPath selected %q, %v, want %q", describe(nodes), err, "li(one)")
+	}
+
+	for _, expr := range []string{"//", "//li[", "//li[@x='1'", "foo::li", "count(//li)", "//li[unknown()]"} {
+		if _, err := evalXPath(expr, doc); err == nil {
+			t.Errorf("evalXPath(%q) did not return an error", expr)
+		}
+	}
+}
+
+func TestParseHTML(t *testing.T) {
+	doc := parseHTML(`<title>T</title><p>a &amp; b<br>c<script>if (a < b) {}</script>`)
+	if got, want := outerHTML(doc.find("html")), `<html><head><title>T</title></head><body><p>a &amp; b<br>c<script>if (a < b) {}</script></p></body></html>`; got != want {
+		t.Errorf("parseHTML() = %s, want %s", got, want)
+	}
+	if got, want := renderedText(doc.find("body")), "a & b\nc"; got != want {
+		t.Errorf("renderedText() = %q, want %q", got, want)
+	}
+}
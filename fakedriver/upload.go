@@ -0,0 +1,77 @@
+package fakedriver
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// UploadedFile returns the content of the file uploaded to the server under
+// path, the path returned to the client, and whether there is such a file.
+func (s *Server) UploadedFile(path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.uploads[path]
+	return content, ok
+}
+
+// uploadFile implements the file upload command of Selenium: the file is a
+// base64-encoded Zip file that contains a single file, which is kept in
+// memory.
+func (sess *session) uploadFile(_ []string, body []byte) (interface{}, error) {
+	var params struct{ File string }
+	if err := decode(body, &params); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(params.File)
+	if err != nil {
+		return nil, newError(errInvalidArgument, "the file is not base64-encoded: %v", err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, newError(errInvalidArgument, "the file is not a Zip file: %v", err)
+	}
+	if len(r.File) != 1 {
+		return nil, newError(errInvalidArgument, "expected a single file, got %d", len(r.File))
+	}
+	f, err := r.File[0].Open()
+	if err != nil {
+		return nil, newError(errInvalidArgument, "%v", err)
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, newError(errInvalidArgument, "%v", err)
+	}
+	name := "/fakedriver/upload/" + sess.server.newID("file") + "/" + path.Base(r.File[0].Name)
+	sess.server.uploads[name] = content
+	return name, nil
+}
+
+// setFiles sets the files of the file input n to the newline-separated
+// paths, which are uploaded files or files of the local file system. As in
+// the browsers, the value of n is a fake path to the first file.
+func (sess *session) setFiles(n *node, paths string) error {
+	files := strings.Split(paths, "\n")
+	if _, multiple := n.attr("multiple"); !multiple && len(files) > 1 {
+		return newError(errInvalidArgument, "the file input does not accept multiple files")
+	}
+	for _, f := range files {
+		if _, ok := sess.server.uploads[f]; ok {
+			continue
+		}
+		if fi, err := os.Stat(f); err != nil || !fi.Mode().IsRegular() {
+			return newError(errInvalidArgument, "file not found: %s", f)
+		}
+	}
+	base := files[0]
+	if i := strings.LastIndexAny(base, `/\`); i >= 0 {
+		base = base[i+1:]
+	}
+	setValue(n, `C:\fakepath\`+base)
+	return nil
+}
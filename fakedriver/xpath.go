@@ -0,0 +1,866 @@
+package fakedriver
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The XPath support covers XPath 1.0 location paths with the common axes,
+// predicates with positions, comparisons, "and", "or", unions and the
+// functions most often used in locators: text(), contains(), starts-with(),
+// normalize-space(), not(), last(), position(), string() and count().
+// Arithmetic is not supported.
+
+type xpathExpr interface {
+	eval(ctx xpathContext) (interface{}, error)
+}
+
+// xpathContext is the evaluation context of an expression. Values are one of
+// []*node, string, float64 and bool.
+type xpathContext struct {
+	node     *node
+	position int
+	size     int
+}
+
+func evalXPath(expr string, context *node) ([]*node, error) {
+	e, err := parseXPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	v, err := e.eval(xpathContext{node: context, position: 1, size: 1})
+	if err != nil {
+		return nil, err
+	}
+	nodes, ok := v.([]*node)
+	if !ok {
+		return nil, fmt.Errorf("expression %q does not select nodes", expr)
+	}
+	var elems []*node
+	for _, n := range nodes {
+		if n.typ == elementNode {
+			elems = append(elems, n)
+		}
+	}
+	return elems, nil
+}
+
+func parseXPath(s string) (xpathExpr, error) {
+	tokens, err := tokenizeXPath(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid XPath %q: %v", s, err)
+	}
+	p := &xpathParser{tokens: tokens}
+	e, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid XPath %q: %v", s, err)
+	}
+	return e, nil
+}
+
+func tokenizeXPath(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case isSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, s[i:i+end+2])
+			i += end + 2
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			start := i
+			for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, s[start:i])
+		default:
+			matched := false
+			for _, op := range []string{"//", "::", "..", "!=", "<=", ">=", "/", "(", ")", "[", "]", "@", ",", "|", "=", "<", ">", ".", "*"} {
+				if strings.HasPrefix(s[i:], op) {
+					tokens = append(tokens, op)
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+			start := i
+			for i < len(s) && (isNameByte(s[i]) || s[i] == '.' || s[i] >= 0x80) {
+				if s[i] == ':' && i+1 < len(s) && s[i+1] == ':' {
+					break
+				}
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("unexpected %q", s[i:])
+			}
+			tokens = append(tokens, s[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+type xpathParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *xpathParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *xpathParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *xpathParser) expect(t string) error {
+	if got := p.next(); got != t {
+		return fmt.Errorf("expected %q, got %q", t, got)
+	}
+	return nil
+}
+
+type binaryExpr struct {
+	op          string
+	left, right xpathExpr
+}
+
+func (p *xpathParser) parseOr() (xpathExpr, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.peek() == "or" {
+		p.next()
+		var right xpathExpr
+		right, err = p.parseAnd()
+		left = binaryExpr{"or", left, right}
+	}
+	return left, err
+}
+
+func (p *xpathParser) parseAnd() (xpathExpr, error) {
+	left, err := p.parseComparison()
+	for err == nil && p.peek() == "and" {
+		p.next()
+		var right xpathExpr
+		right, err = p.parseComparison()
+		left = binaryExpr{"and", left, right}
+	}
+	return left, err
+}
+
+func (p *xpathParser) parseComparison() (xpathExpr, error) {
+	left, err := p.parseUnion()
+	for err == nil {
+		switch op := p.peek(); op {
+		case "=", "!=", "<", "<=", ">", ">=":
+			p.next()
+			var right xpathExpr
+			right, err = p.parseUnion()
+			left = binaryExpr{op, left, right}
+			continue
+		}
+		break
+	}
+	return left, err
+}
+
+func (p *xpathParser) parseUnion() (xpathExpr, error) {
+	left, err := p.parsePath()
+	for err == nil && p.peek() == "|" {
+		p.next()
+		var right xpathExpr
+		right, err = p.parsePath()
+		left = binaryExpr{"|", left, right}
+	}
+	return left, err
+}
+
+type literalExpr struct {
+	value interface{}
+}
+
+type functionExpr struct {
+	name string
+	args []xpathExpr
+}
+
+// pathExpr is a location path, optionally applied to the result of a
+// filter expression such as a function call or a parenthesized expression.
+type pathExpr struct {
+	filter     xpathExpr
+	predicates []xpathExpr // Applied to the result of filter.
+	absolute   bool
+	steps      []step
+}
+
+type step struct {
+	axis       string
+	test       string // A name, "*", "text()", "node()" or "comment()".
+	predicates []xpathExpr
+}
+
+var xpathAxes = map[string]bool{
+	"ancestor": true, "ancestor-or-self": true, "attribute": true,
+	"child": true, "descendant": true, "descendant-or-self": true,
+	"following": true, "following-sibling": true, "parent": true,
+	"preceding": true, "preceding-sibling": true, "self": true,
+}
+
+func (p *xpathParser) parsePath() (xpathExpr, error) {
+	path := pathExpr{}
+	t := p.peek()
+	switch {
+	case t == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case t[0] == '"' || t[0] == '\'':
+		p.next()
+		return literalExpr{t[1 : len(t)-1]}, nil
+	case t[0] >= '0' && t[0] <= '9' || t[0] == '.' && len(t) > 1 && t[1] != '.':
+		p.next()
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return nil, err
+		}
+		return literalExpr{f}, nil
+	case t == "(":
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		path.filter = e
+	case t == "/":
+		p.next()
+		path.absolute = true
+		if !p.startsStep() {
+			return path, nil
+		}
+	case t == "//":
+		p.next()
+		path.absolute = true
+		path.steps = append(path.steps, step{axis: "descendant-or-self", test: "node()"})
+	case p.pos+1 < len(p.tokens) && p.tokens[p.pos+1] == "(" && isFunction(t):
+		p.next()
+		p.next()
+		f := functionExpr{name: t}
+		for p.peek() != ")" {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			f.args = append(f.args, arg)
+			if p.peek() == "," {
+				p.next()
+			}
+		}
+		p.next()
+		path.filter = f
+	}
+
+	if path.filter != nil {
+		var err error
+		if path.predicates, err = p.parsePredicates(); err != nil {
+			return nil, err
+		}
+		if p.peek() != "/" && p.peek() != "//" {
+			if len(path.predicates) == 0 {
+				return path.filter, nil
+			}
+			return path, nil
+		}
+		if p.next() == "//" {
+			path.steps = append(path.steps, step{axis: "descendant-or-self", test: "node()"})
+		}
+	}
+
+	for {
+		s, err := p.parseStep()
+		if err != nil {
+			return nil, err
+		}
+		path.steps = append(path.steps, s)
+		switch p.peek() {
+		case "/":
+			p.next()
+		case "//":
+			p.next()
+			path.steps = append(path.steps, step{axis: "descendant-or-self", test: "node()"})
+		default:
+			return path, nil
+		}
+	}
+}
+
+func isFunction(name string) bool {
+	switch name {
+	case "text", "node", "comment":
+		return false
+	}
+	return true
+}
+
+func (p *xpathParser) startsStep() bool {
+	switch t := p.peek(); t {
+	case "", ")", "]", ",", "|", "=", "!=", "<", "<=", ">", ">=", "and", "or":
+		return false
+	}
+	return true
+}
+
+func (p *xpathParser) parseStep() (step, error) {
+	switch p.peek() {
+	case ".":
+		p.next()
+		return step{axis: "self", test: "node()"}, nil
+	case "..":
+		p.next()
+		return step{axis: "parent", test: "node()"}, nil
+	}
+
+	s := step{axis: "child"}
+	if p.peek() == "@" {
+		p.next()
+		s.axis = "attribute"
+	} else if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1] == "::" {
+		s.axis = p.next()
+		p.next()
+		if !xpathAxes[s.axis] {
+			return s, fmt.Errorf("unsupported axis %q", s.axis)
+		}
+	}
+
+	t := p.next()
+	switch {
+	case t == "*":
+		s.test = t
+	case t == "text" || t == "node" || t == "comment":
+		if err := p.expect("("); err != nil {
+			return s, err
+		}
+		if err := p.expect(")"); err != nil {
+			return s, err
+		}
+		s.test = t + "()"
+	case t != "" && (isNameByte(t[0]) || t[0] >= 0x80):
+		s.test = strings.ToLower(t)
+	default:
+		return s, fmt.Errorf("expected a node test, got %q", t)
+	}
+
+	var err error
+	s.predicates, err = p.parsePredicates()
+	return s, err
+}
+
+func (p *xpathParser) parsePredicates() ([]xpathExpr, error) {
+	var predicates []xpathExpr
+	for p.peek() == "[" {
+		p.next()
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, e)
+	}
+	return predicates, nil
+}
+
+func (e literalExpr) eval(xpathContext) (interface{}, error) {
+	return e.value, nil
+}
+
+func (e pathExpr) eval(ctx xpathContext) (interface{}, error) {
+	var nodes []*node
+	switch {
+	case e.filter != nil:
+		v, err := e.filter.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if nodes, ok = v.([]*node); !ok {
+			return nil, fmt.Errorf("a path can only be applied to nodes")
+		}
+		if nodes, err = filterNodes(nodes, e.predicates); err != nil {
+			return nil, err
+		}
+	case e.absolute:
+		nodes = []*node{ctx.node.root()}
+	default:
+		nodes = []*node{ctx.node}
+	}
+
+	for _, s := range e.steps {
+		var next []*node
+		seen := make(map[*node]bool)
+		for _, n := range nodes {
+			selected, err := s.apply(n)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range selected {
+				if !seen[m] {
+					seen[m] = true
+					next = append(next, m)
+				}
+			}
+		}
+		if s.axis != "attribute" && len(nodes) > 1 {
+			next = documentOrder(next)
+		}
+		nodes = next
+	}
+	return nodes, nil
+}
+
+// apply returns the nodes selected by s from the context node n.
+func (s step) apply(n *node) ([]*node, error) {
+	var candidates []*node
+	switch s.axis {
+	case "self":
+		candidates = []*node{n}
+	case "child":
+		candidates = n.children
+	case "parent":
+		if n.parent != nil {
+			candidates = []*node{n.parent}
+		}
+	case "attribute":
+		for _, a := range n.attrs {
+			candidates = append(candidates, &node{typ: attributeNode, tag: a.key, text: a.val, parent: n})
+		}
+	case "descendant", "descendant-or-self":
+		if s.axis == "descendant-or-self" {
+			candidates = append(candidates, n)
+		}
+		var visit func(*node)
+		visit = func(n *node) {
+			for _, c := range n.children {
+				candidates = append(candidates, c)
+				visit(c)
+			}
+		}
+		visit(n)
+	case "ancestor", "ancestor-or-self":
+		if s.axis == "ancestor-or-self" {
+			candidates = append(candidates, n)
+		}
+		for p := n.parent; p != nil; p = p.parent {
+			candidates = append(candidates, p)
+		}
+	case "following-sibling", "preceding-sibling":
+		if n.parent == nil || n.typ == attributeNode {
+			break
+		}
+		siblings := n.parent.children
+		for i, c := range siblings {
+			if c != n {
+				continue
+			}
+			if s.axis == "following-sibling" {
+				candidates = siblings[i+1:]
+			} else {
+				for j := i - 1; j >= 0; j-- {
+					candidates = append(candidates, siblings[j])
+				}
+			}
+			break
+		}
+	case "following", "preceding":
+		all := []*node{}
+		var visit func(*node)
+		visit = func(n *node) {
+			all = append(all, n)
+			for _, c := range n.children {
+				visit(c)
+			}
+		}
+		visit(n.root())
+		ancestors := make(map[*node]bool)
+		for p := n; p != nil; p = p.parent {
+			ancestors[p] = true
+		}
+		for i, c := range all {
+			if c != n {
+				continue
+			}
+			if s.axis == "following" {
+				for _, f := range all[i+1:] {
+					if !isDescendant(f, n) {
+						candidates = append(candidates, f)
+					}
+				}
+			} else {
+				for j := i - 1; j >= 0; j-- {
+					if !ancestors[all[j]] {
+						candidates = append(candidates, all[j])
+					}
+				}
+			}
+			break
+		}
+	}
+
+	var matched []*node
+	for _, c := range candidates {
+		if s.matches(c) {
+			matched = append(matched, c)
+		}
+	}
+	return filterNodes(matched, s.predicates)
+}
+
+// filterNodes keeps the nodes for which all predicates hold. The position of
+// a node is its position in nodes.
+func filterNodes(nodes []*node, predicates []xpathExpr) ([]*node, error) {
+	matched := nodes
+	for _, pred := range predicates {
+		var kept []*node
+		for i, c := range matched {
+			v, err := pred.eval(xpathContext{node: c, position: i + 1, size: len(matched)})
+			if err != nil {
+				return nil, err
+			}
+			if f, ok := v.(float64); ok {
+				if int(f) == i+1 {
+					kept = append(kept, c)
+				}
+				continue
+			}
+			if toBool(v) {
+				kept = append(kept, c)
+			}
+		}
+		matched = kept
+	}
+	return matched, nil
+}
+
+func (s step) matches(n *node) bool {
+	switch s.test {
+	case "node()":
+		return true
+	case "text()":
+		return n.typ == textNode
+	case "comment()":
+		return n.typ == commentNode
+	case "*":
+		if s.axis == "attribute" {
+			return n.typ == attributeNode
+		}
+		return n.typ == elementNode
+	}
+	if s.axis == "attribute" {
+		return n.typ == attributeNode && n.tag == s.test
+	}
+	return n.typ == elementNode && n.tag == s.test
+}
+
+func isDescendant(n, ancestor *node) bool {
+	for p := n.parent; p != nil; p = p.parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// documentOrder sorts nodes of the same document in document order.
+func documentOrder(nodes []*node) []*node {
+	if len(nodes) < 2 {
+		return nodes
+	}
+	in := make(map[*node]bool, len(nodes))
+	for _, n := range nodes {
+		in[n] = true
+	}
+	var sorted []*node
+	var visit func(*node)
+	visit = func(n *node) {
+		if in[n] {
+			sorted = append(sorted, n)
+			delete(in, n)
+		}
+		for _, c := range n.children {
+			visit(c)
+		}
+	}
+	visit(nodes[0].root())
+	// Attribute nodes are not part of the tree; keep them in their order.
+	for _, n := range nodes {
+		if in[n] {
+			sorted = append(sorted, n)
+		}
+	}
+	return sorted
+}
+
+func (e binaryExpr) eval(ctx xpathContext) (interface{}, error) {
+	left, err := e.left.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "and":
+		if !toBool(left) {
+			return false, nil
+		}
+	case "or":
+		if toBool(left) {
+			return true, nil
+		}
+	}
+	right, err := e.right.eval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "and", "or":
+		return toBool(right), nil
+	case "|":
+		l, lok := left.([]*node)
+		r, rok := right.([]*node)
+		if !lok || !rok {
+			return nil, fmt.Errorf("the operands of | must be node sets")
+		}
+		seen := make(map[*node]bool)
+		var union []*node
+		for _, n := range append(l, r...) {
+			if !seen[n] {
+				seen[n] = true
+				union = append(union, n)
+			}
+		}
+		return documentOrder(union), nil
+	}
+	return compare(e.op, left, right), nil
+}
+
+// compare implements the XPath comparison of two values, where a node set
+// compares true if any of its nodes does.
+func compare(op string, left, right interface{}) bool {
+	if l, ok := left.([]*node); ok {
+		for _, n := range l {
+			if compare(op, n.textContent(), right) {
+				return true
+			}
+		}
+		return false
+	}
+	if r, ok := right.([]*node); ok {
+		for _, n := range r {
+			if compare(op, left, n.textContent()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch op {
+	case "=", "!=":
+		var equal bool
+		switch {
+		case isBool(left) || isBool(right):
+			equal = toBool(left) == toBool(right)
+		case isNumber(left) || isNumber(right):
+			equal = toNumber(left) == toNumber(right)
+		default:
+			equal = toString(left) == toString(right)
+		}
+		return equal == (op == "=")
+	}
+	l, r := toNumber(left), toNumber(right)
+	switch op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+func (e functionExpr) eval(ctx xpathContext) (interface{}, error) {
+	args := make([]interface{}, len(e.args))
+	for i, a := range e.args {
+		v, err := a.eval(ctx)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	arity := func(min, max int) error {
+		if len(args) < min || len(args) > max {
+			return fmt.Errorf("wrong number of arguments to %s()", e.name)
+		}
+		return nil
+	}
+	// stringArg returns the i-th argument as a string, defaulting to the
+	// string value of the context node.
+	stringArg := func(i int) string {
+		if i < len(args) {
+			return toString(args[i])
+		}
+		return ctx.node.textContent()
+	}
+
+	switch e.name {
+	case "last":
+		return float64(ctx.size), arity(0, 0)
+	case "position":
+		return float64(ctx.position), arity(0, 0)
+	case "count":
+		if len(args) != 1 {
+			return nil, arity(1, 1)
+		}
+		nodes, ok := args[0].([]*node)
+		if !ok {
+			return nil, fmt.Errorf("count() takes a node set")
+		}
+		return float64(len(nodes)), nil
+	case "not":
+		return !toBool(args[0]), arity(1, 1)
+	case "true", "false":
+		return e.name == "true", arity(0, 0)
+	case "boolean":
+		return toBool(args[0]), arity(1, 1)
+	case "number":
+		if len(args) == 0 {
+			return toNumber(ctx.node.textContent()), nil
+		}
+		return toNumber(args[0]), arity(0, 1)
+	case "string":
+		return stringArg(0), arity(0, 1)
+	case "normalize-space":
+		return strings.Join(strings.Fields(stringArg(0)), " "), arity(0, 1)
+	case "string-length":
+		return float64(len([]rune(stringArg(0)))), arity(0, 1)
+	case "name", "local-name":
+		if len(args) == 0 {
+			return ctx.node.tag, nil
+		}
+		if nodes, ok := args[0].([]*node); ok && len(nodes) > 0 {
+			return nodes[0].tag, nil
+		}
+		return "", nil
+	case "contains":
+		return strings.Contains(toString(args[0]), toString(args[1])), arity(2, 2)
+	case "starts-with":
+		return strings.HasPrefix(toString(args[0]), toString(args[1])), arity(2, 2)
+	case "ends-with":
+		return strings.HasSuffix(toString(args[0]), toString(args[1])), arity(2, 2)
+	case "concat":
+		var b strings.Builder
+		for _, a := range args {
+			b.WriteString(toString(a))
+		}
+		return b.String(), arity(2, math.MaxInt32)
+	case "translate":
+		if err := arity(3, 3); err != nil {
+			return nil, err
+		}
+		from, to := []rune(toString(args[1])), []rune(toString(args[2]))
+		var b strings.Builder
+		for _, r := range toString(args[0]) {
+			i := strings.IndexRune(string(from), r)
+			if i < 0 {
+				b.WriteRune(r)
+				continue
+			}
+			if i = len([]rune(string(from)[:i])); i < len(to) {
+				b.WriteRune(to[i])
+			}
+		}
+		return b.String(), nil
+	case "lower-case":
+		return strings.ToLower(toString(args[0])), arity(1, 1)
+	case "upper-case":
+		return strings.ToUpper(toString(args[0])), arity(1, 1)
+	}
+	return nil, fmt.Errorf("unsupported function %s()", e.name)
+}
+
+func isBool(v interface{}) bool {
+	_, ok := v.(bool)
+	return ok
+}
+
+func isNumber(v interface{}) bool {
+	_, ok := v.(float64)
+	return ok
+}
+
+func toBool(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0 && !math.IsNaN(v)
+	case string:
+		return v != ""
+	case []*node:
+		return len(v) > 0
+	}
+	return false
+}
+
+func toNumber(v interface{}) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(toString(v)), 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return f
+}
+
+func toString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []*node:
+		if len(v) == 0 {
+			return ""
+		}
+		return v[0].textContent()
+	}
+	return ""
+}
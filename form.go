@@ -0,0 +1,266 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FillOption configures FillForm.
+type FillOption func(*fillOptions)
+
+type fillOptions struct {
+	submit bool
+}
+
+// SubmitForm makes FillForm submit the form, with WebElement.Submit, once
+// it is filled.
+func SubmitForm() FillOption {
+	return func(o *fillOptions) {
+		o.submit = true
+	}
+}
+
+// FillForm fills the fields of form with values, a struct or a
+// map[string]interface{}.
+//
+// The fields of a struct are located by their selenium tag: "css=selector",
+// "xpath=expression", "id=id" or "name=name". The fields without a tag are
+// located by name, the name of the field. The ",omitempty" option skips the
+// field if it has its zero value, and the tag "-" skips it always:
+//
+//	type Login struct {
+//		Email    string `selenium:"css=#email"`
+//		Password string `selenium:"name=password"`
+//		Remember bool   `selenium:"name=remember"`
+//		Plan     string `selenium:"name=plan,omitempty"`
+//	}
+//	err := selenium.FillForm(form, Login{...}, selenium.SubmitForm())
+//
+// The keys of a map are located the same way, in sorted order.
+//
+// Text fields are cleared and typed into. Checkboxes are checked or unchecked
+// according to a bool. The radio button of a group whose value is a string
+// is checked, or the radio button located if the value is true. Select
+// elements select the options by value, or else by visible text; multiple
+// selects take a []string and lose their previous selection. File inputs
+// take the path, or a []string of paths, of local files, set with UploadFile.
+func FillForm(form *WebElement, values interface{}, opts ...FillOption) error {
+	var o fillOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	fields, err := formFields(values)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if err := fillField(form, f.locator, f.value); err != nil {
+			return fmt.Errorf("selenium: filling %q: %w", f.locator, err)
+		}
+	}
+	if o.submit {
+		return form.Submit()
+	}
+	return nil
+}
+
+type formField struct {
+	locator string
+	value   interface{}
+}
+
+func formFields(values interface{}) ([]formField, error) {
+	v := reflect.ValueOf(values)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	var fields []formField
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("selenium: cannot fill a form from a %s", v.Type())
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			fields = append(fields, formField{iter.Key().String(), iter.Value().Interface()})
+		}
+		sort.Slice(fields, func(i, j int) bool { return fields[i].locator < fields[j].locator })
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+			tag := sf.Tag.Get("selenium")
+			if tag == "-" {
+				continue
+			}
+			locator, omitEmpty := tag, false
+			if i := strings.LastIndex(tag, ",omitempty"); i >= 0 && i == len(tag)-len(",omitempty") {
+				locator, omitEmpty = tag[:i], true
+			}
+			if locator == "" {
+				locator = "name=" + sf.Name
+			}
+			fv := v.Field(i)
+			if omitEmpty && fv.IsZero() {
+				continue
+			}
+			fields = append(fields, formField{locator, fv.Interface()})
+		}
+	default:
+		return nil, fmt.Errorf("selenium: cannot fill a form from a %T", values)
+	}
+	return fields, nil
+}
+
+// parseLocator returns the strategy and the value of a field locator. A
+// locator without a strategy is a name.
+func parseLocator(locator string) (by, value string) {
+	strategies := map[string]string{
+		"css":   ByCSSSelector,
+		"xpath": ByXPATH,
+		"id":    ByCSSSelector,
+		"name":  ByCSSSelector,
+	}
+	kind, value := "name", locator
+	if i := strings.IndexByte(locator, '='); i > 0 {
+		if _, ok := strategies[locator[:i]]; ok {
+			kind, value = locator[:i], locator[i+1:]
+		}
+	}
+	switch kind {
+	case "id":
+		value = "[id=" + cssString(value) + "]"
+	case "name":
+		value = "[name=" + cssString(value) + "]"
+	}
+	return strategies[kind], value
+}
+
+func fillField(form *WebElement, locator string, value interface{}) error {
+	elems, err := form.FindElements(parseLocator(locator))
+	if err != nil {
+		return err
+	}
+	if len(elems) == 0 {
+		return &Error{Err: string(ErrNoSuchElement), Message: "no field matches the locator"}
+	}
+	elem := elems[0]
+	tag, err := elem.TagName()
+	if err != nil {
+		return err
+	}
+	typ := ""
+	if tag = strings.ToLower(tag); tag == "input" {
+		t, _, err := elem.attribute("type")
+		if err != nil {
+			return err
+		}
+		typ = strings.ToLower(t)
+	}
+
+	switch {
+	case tag == "select":
+		return fillSelect(elem, value)
+	case typ == "checkbox":
+		checked, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("a checkbox takes a bool, not a %T", value)
+		}
+		return setChecked(elem, checked)
+	case typ == "radio":
+		switch v := value.(type) {
+		case bool:
+			if !v {
+				return errors.New("a radio button cannot be unchecked")
+			}
+			return setChecked(elem, true)
+		case string:
+			for _, e := range elems {
+				if ev, _, err := e.attribute("value"); err != nil {
+					return err
+				} else if ev == v {
+					return setChecked(e, true)
+				}
+			}
+			return &Error{Err: string(ErrNoSuchElement), Message: fmt.Sprintf("no radio button has the value %q", v)}
+		}
+		return fmt.Errorf("a radio button takes a string or a bool, not a %T", value)
+	case typ == "file":
+		var paths []string
+		switch v := value.(type) {
+		case string:
+			paths = []string{v}
+		case []string:
+			paths = v
+		default:
+			return fmt.Errorf("a file input takes a path or a []string of paths, not a %T", value)
+		}
+		return elem.UploadFile(paths...)
+	}
+
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case fmt.Stringer:
+		text = v.String()
+	default:
+		text = fmt.Sprint(v)
+	}
+	if err := elem.Clear(); err != nil {
+		return err
+	}
+	if text == "" {
+		return nil
+	}
+	return elem.SendKeys(text)
+}
+
+func setChecked(elem *WebElement, checked bool) error {
+	selected, err := elem.IsSelected()
+	if err != nil || selected == checked {
+		return err
+	}
+	return elem.Click()
+}
+
+func fillSelect(elem *WebElement, value interface{}) error {
+	s, err := NewSelect(elem)
+	if err != nil {
+		return err
+	}
+	var options []string
+	switch v := value.(type) {
+	case string:
+		options = []string{v}
+	case []string:
+		if !s.IsMultiple() {
+			return errors.New("a single select takes a string")
+		}
+		options = v
+	default:
+		return fmt.Errorf("a select takes a string or a []string, not a %T", value)
+	}
+	if s.IsMultiple() {
+		if err := s.DeselectAll(); err != nil {
+			return err
+		}
+	}
+	for _, o := range options {
+		err := s.SelectByValue(o)
+		var notFound *NoSuchOptionError
+		if errors.As(err, &notFound) {
+			err = s.SelectByVisibleText(o)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package selenium
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+)
+
+const formPage = `<title>Sign up</title>
+<form action="/done">
+  <input id="email" name="email" value="old@example.com">
+  <input name="Age" type="number">
+  <textarea name="bio"></textarea>
+  <input type="checkbox" name="news" checked>
+  <input type="checkbox" name="terms">
+  <input type="radio" name="plan" value="free" checked>
+  <input type="radio" name="plan" value="pro">
+  <select name="country">
+    <option value="fr" selected>France</option>
+    <option value="de">Germany</option>
+  </select>
+  <select name="langs" multiple>
+    <option value="go" selected>Go</option>
+    <option value="js">JavaScript</option>
+    <option value="py">Python</option>
+  </select>
+  <input type="hidden" name="token" value="t">
+</form>`
+
+type signUp struct {
+	Email   string `selenium:"css=#email"`
+	Age     int
+	Bio     string   `selenium:"name=bio,omitempty"`
+	News    bool     `selenium:"name=news"`
+	Terms   bool     `selenium:"name=terms"`
+	Plan    string   `selenium:"name=plan"`
+	Country string   `selenium:"name=country"`
+	Langs   []string `selenium:"name=langs"`
+	Ignored string   `selenium:"-"`
+}
+
+func TestFillForm(t *testing.T) {
+	wd, _ := newFakeSession(t, nil, map[string]string{
+		homePage:                  formPage,
+		"http://example.com/done": "<title>Done</title>",
+	})
+
+	for _, tc := range []struct {
+		name   string
+		values interface{}
+		want   url.Values
+	}{
+		{
+			name: "struct",
+			values: &signUp{
+				Email:   "me@example.com",
+				Age:     42,
+				Terms:   true,
+				Plan:    "pro",
+				Country: "Germany",
+				Langs:   []string{"js", "py"},
+				Ignored: "x",
+			},
+			want: url.Values{
+				"email":   {"me@example.com"},
+				"Age":     {"42"},
+				"bio":     {""},
+				"terms":   {"on"},
+				"plan":    {"pro"},
+				"country": {"de"},
+				"langs":   {"js", "py"},
+				"token":   {"t"},
+			},
+		},
+		{
+			name: "map",
+			values: map[string]interface{}{
+				"id=email":                      "you@example.com",
+				"bio":                           "Hi",
+				"xpath=.//input[@value='free']": true,
+			},
+			want: url.Values{
+				"email":   {"you@example.com"},
+				"Age":     {""},
+				"bio":     {"Hi"},
+				"news":    {"on"},
+				"plan":    {"free"},
+				"country": {"fr"},
+				"langs":   {"go"},
+				"token":   {"t"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := wd.Get("http://example.com/"); err != nil {
+				t.Fatalf("Get() returned error: %v", err)
+			}
+			form, err := wd.FindElement(ByTagName, "form")
+			if err != nil {
+				t.Fatalf("FindElement() returned error: %v", err)
+			}
+			if err := FillForm(form, tc.values, SubmitForm()); err != nil {
+				t.Fatalf("FillForm() returned error: %v", err)
+			}
+			got, err := wd.CurrentURL()
+			if err != nil {
+				t.Fatalf("CurrentURL() returned error: %v", err)
+			}
+			u, err := url.Parse(got)
+			if err != nil {
+				t.Fatalf("url.Parse(%q) returned error: %v", got, err)
+			}
+			if u.Path != "/done" {
+				t.Errorf("the form was submitted to %q, want /done", got)
+			}
+			if q := u.Query(); q.Encode() != tc.want.Encode() {
+				t.Errorf("the form was submitted with %s, want %s", q.Encode(), tc.want.Encode())
+			}
+		})
+	}
+
+	if err := wd.Get("http://example.com/"); err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	form, err := wd.FindElement(ByTagName, "form")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	for _, tc := range []struct {
+		values interface{}
+		is     error
+	}{
+		{map[string]interface{}{"missing": "x"}, ErrNoSuchElement},
+		{map[string]interface{}{"plan": "gold"}, ErrNoSuchElement},
+		{map[string]interface{}{"country": "Spain"}, ErrNoSuchElement},
+		{map[string]interface{}{"terms": "yes"}, nil},
+		{map[string]interface{}{"country": []string{"fr"}}, nil},
+		{[]string{"email"}, nil},
+	} {
+		err := FillForm(form, tc.values)
+		if err == nil {
+			t.Errorf("FillForm(%v) returned no error", tc.values)
+			continue
+		}
+		if tc.is != nil && !errors.Is(err, tc.is) {
+			t.Errorf("FillForm(%v) returned %v, want an error matching %v", tc.values, err, tc.is)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package selenium
+
+import (
+	"github.com/injoyai/selenium/har"
+	"github.com/injoyai/selenium/log"
+)
+
+// StartHAR starts capturing the network traffic of the browser, to be
+// returned by StopHAR. It discards the performance log collected so far.
+//
+// The traffic is read from the performance log of ChromeDriver, which must be
+// enabled when creating the session:
+//
+//	caps.AddLogging(log.Capabilities{log.Performance: log.All})
+func (wd *WebDriver) StartHAR() error {
+	_, err := wd.Log(log.Performance)
+	return err
+}
+
+// StopHAR returns the network traffic since StartHAR as a HAR document and,
+// if path is not empty, writes it to the file at path.
+func (wd *WebDriver) StopHAR(path string) (*har.HAR, error) {
+	msgs, err := wd.Log(log.Performance)
+	if err != nil {
+		return nil, err
+	}
+	h, err := har.Convert(msgs)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if err := h.WriteFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
@@ -0,0 +1,415 @@
+package har
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/injoyai/selenium/cdp"
+	"github.com/injoyai/selenium/log"
+)
+
+// The events of the Page domain used for the page timings.
+const (
+	eventPageDOMContentEventFired = "Page.domContentEventFired"
+	eventPageLoadEventFired       = "Page.loadEventFired"
+)
+
+// Builder builds a HAR document from DevTools events. The zero value is not
+// usable; use NewBuilder.
+type Builder struct {
+	// Creator is written in the document.
+	Creator Creator
+
+	pages   []*page
+	entries []*entry
+	// pending holds the entries waiting for their response, by request ID.
+	pending map[string]*entry
+}
+
+type page struct {
+	Page
+	frameID string
+	start   float64
+}
+
+type entry struct {
+	Entry
+	start    float64
+	timing   *cdp.ResourceTiming
+	response bool
+	done     bool
+	// headersLength is the number of bytes received with the headers of the
+	// response, or 0 if unknown.
+	headersLength float64
+	// decoded is the length of the decoded body received so far, and
+	// chunks tells whether any chunk of it was received.
+	decoded int
+	chunks  bool
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{
+		Creator: Creator{Name: "github.com/injoyai/selenium/har", Version: Version},
+		pending: make(map[string]*entry),
+	}
+}
+
+// Convert returns the HAR document of the network events of messages, the
+// messages of the performance log.
+func Convert(messages []log.Message) (*HAR, error) {
+	b := NewBuilder()
+	if err := b.AddLog(messages); err != nil {
+		return nil, err
+	}
+	return b.HAR(), nil
+}
+
+// AddLog adds the events of messages, the messages of the performance log.
+func (b *Builder) AddLog(messages []log.Message) error {
+	for _, m := range messages {
+		var msg struct {
+			Message struct {
+				Method string          `json:"method"`
+				Params json.RawMessage `json:"params"`
+			} `json:"message"`
+		}
+		if err := json.Unmarshal([]byte(m.Message), &msg); err != nil {
+			return fmt.Errorf("har: invalid performance log message %q: %v", m.Message, err)
+		}
+		if err := b.Add(msg.Message.Method, msg.Message.Params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Add adds the event method with params. Only the requestWillBeSent,
+// responseReceived, dataReceived, loadingFinished and loadingFailed events of
+// the Network domain, and the domContentEventFired and loadEventFired events
+// of the Page domain are used; the others are ignored.
+func (b *Builder) Add(method string, params json.RawMessage) error {
+	var err error
+	switch method {
+	case cdp.EventNetworkRequestWillBeSent:
+		var ev cdp.RequestWillBeSent
+		if err = json.Unmarshal(params, &ev); err == nil {
+			b.requestWillBeSent(&ev)
+		}
+	case cdp.EventNetworkResponseReceived:
+		var ev cdp.ResponseReceived
+		if err = json.Unmarshal(params, &ev); err == nil {
+			if e := b.pending[ev.RequestID]; e != nil {
+				e.setResponse(&ev.Response)
+			}
+		}
+	case cdp.EventNetworkDataReceived:
+		var ev cdp.DataReceived
+		if err = json.Unmarshal(params, &ev); err == nil {
+			if e := b.pending[ev.RequestID]; e != nil {
+				e.decoded += ev.DataLength
+				e.chunks = true
+			}
+		}
+	case cdp.EventNetworkLoadingFinished:
+		var ev cdp.LoadingFinished
+		if err = json.Unmarshal(params, &ev); err == nil {
+			if e := b.pending[ev.RequestID]; e != nil {
+				e.setSizes(ev.EncodedDataLength)
+				e.finish(ev.Timestamp)
+				delete(b.pending, ev.RequestID)
+			}
+		}
+	case cdp.EventNetworkLoadingFailed:
+		var ev cdp.LoadingFailed
+		if err = json.Unmarshal(params, &ev); err == nil {
+			if e := b.pending[ev.RequestID]; e != nil {
+				e.Comment = ev.ErrorText
+				e.response = true
+				e.finish(ev.Timestamp)
+				delete(b.pending, ev.RequestID)
+			}
+		}
+	case eventPageDOMContentEventFired, eventPageLoadEventFired:
+		var ev struct {
+			Timestamp float64 `json:"timestamp"`
+		}
+		if err = json.Unmarshal(params, &ev); err == nil && len(b.pages) > 0 {
+			p := b.pages[len(b.pages)-1]
+			t := round((ev.Timestamp - p.start) * 1000)
+			if method == eventPageLoadEventFired {
+				p.PageTimings.OnLoad = t
+			} else {
+				p.PageTimings.OnContentLoad = t
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("har: invalid %s event: %v", method, err)
+	}
+	return nil
+}
+
+func (b *Builder) requestWillBeSent(ev *cdp.RequestWillBeSent) {
+	if prev := b.pending[ev.RequestID]; prev != nil && ev.RedirectResponse != nil {
+		// The request is redirected, and keeps its ID.
+		prev.setResponse(ev.RedirectResponse)
+		prev.finish(ev.Timestamp)
+	}
+
+	// A navigation of the main frame starts a new page.
+	if ev.Type == "Document" && ev.RequestID == ev.LoaderID && ev.RedirectResponse == nil &&
+		(len(b.pages) == 0 || ev.FrameID == b.pages[0].frameID) {
+		b.pages = append(b.pages, &page{
+			Page: Page{
+				StartedDateTime: wallTime(ev.WallTime),
+				ID:              fmt.Sprintf("page_%d", len(b.pages)+1),
+				Title:           ev.Request.URL,
+				PageTimings:     PageTimings{OnContentLoad: -1, OnLoad: -1},
+			},
+			frameID: ev.FrameID,
+			start:   ev.Timestamp,
+		})
+	}
+
+	e := &entry{start: ev.Timestamp}
+	if len(b.pages) > 0 {
+		e.Pageref = b.pages[len(b.pages)-1].ID
+	}
+	e.StartedDateTime = wallTime(ev.WallTime)
+	e.Request = Request{
+		Method:      ev.Request.Method,
+		URL:         ev.Request.URL,
+		Cookies:     requestCookies(ev.Request.Headers),
+		Headers:     headers(ev.Request.Headers),
+		QueryString: queryString(ev.Request.URL),
+		HeadersSize: -1,
+	}
+	if ev.Request.PostData != "" {
+		e.Request.PostData = &PostData{
+			MimeType: headerValue(ev.Request.Headers, "Content-Type"),
+			Text:     ev.Request.PostData,
+		}
+		e.Request.BodySize = len(ev.Request.PostData)
+	}
+	e.Response = Response{
+		Cookies:     []NameValue{},
+		Headers:     []NameValue{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	b.entries = append(b.entries, e)
+	b.pending[ev.RequestID] = e
+}
+
+func (e *entry) setResponse(r *cdp.Response) {
+	e.response = true
+	e.timing = r.Timing
+	e.ServerIPAddress = r.RemoteIPAddress
+	e.Request.HTTPVersion = httpVersion(r.Protocol)
+	if len(r.RequestHeaders) > 0 {
+		// The headers actually sent, which include the cookies.
+		e.Request.Headers = headers(r.RequestHeaders)
+		e.Request.Cookies = requestCookies(r.RequestHeaders)
+	}
+	e.headersLength = r.EncodedDataLength
+	e.decoded, e.chunks = 0, false
+	e.Response = Response{
+		Status:      r.Status,
+		StatusText:  r.StatusText,
+		HTTPVersion: httpVersion(r.Protocol),
+		Cookies:     responseCookies(r.Headers),
+		Headers:     headers(r.Headers),
+		Content:     Content{MimeType: r.MimeType},
+		RedirectURL: headerValue(r.Headers, "Location"),
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+}
+
+// setSizes sets the sizes of the body of the response, of which encoded
+// bytes were received in total, headers included. The size of the body
+// received is unknown if the size of the headers is; the size of the decoded
+// body is the size received, unless it is compressed.
+func (e *entry) setSizes(encoded float64) {
+	if e.headersLength > 0 && encoded >= e.headersLength {
+		e.Response.BodySize = int(encoded - e.headersLength)
+	}
+	if e.chunks {
+		e.Response.Content.Size = e.decoded
+		return
+	}
+	for _, h := range e.Response.Headers {
+		if strings.EqualFold(h.Name, "Content-Encoding") {
+			return
+		}
+	}
+	if e.Response.BodySize >= 0 {
+		e.Response.Content.Size = e.Response.BodySize
+	}
+}
+
+// finish computes the timings of the entry, which ended at end, a monotonic
+// time in seconds.
+func (e *entry) finish(end float64) {
+	t := Timings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	if e.timing == nil {
+		t.Receive = round(math.Max(0, (end-e.start)*1000))
+	} else {
+		tm := e.timing
+		queued := (tm.RequestTime - e.start) * 1000
+		blocked := tm.SendStart
+		for _, v := range []float64{tm.ConnectStart, tm.DNSStart} {
+			if v >= 0 {
+				blocked = v
+			}
+		}
+		t.Blocked = round(math.Max(0, queued+blocked))
+		if tm.DNSStart >= 0 {
+			t.DNS = round(tm.DNSEnd - tm.DNSStart)
+		}
+		if tm.ConnectStart >= 0 {
+			t.Connect = round(tm.ConnectEnd - tm.ConnectStart)
+		}
+		if tm.SSLStart >= 0 {
+			t.SSL = round(tm.SSLEnd - tm.SSLStart)
+		}
+		t.Send = round(math.Max(0, tm.SendEnd-tm.SendStart))
+		t.Wait = round(math.Max(0, tm.ReceiveHeadersEnd-tm.SendEnd))
+		t.Receive = round(math.Max(0, (end-tm.RequestTime)*1000-tm.ReceiveHeadersEnd))
+	}
+	e.Timings = t
+	e.done = true
+	total := 0.0
+	for _, v := range []float64{t.Blocked, t.DNS, t.Connect, t.Send, t.Wait, t.Receive} {
+		if v > 0 {
+			total += v
+		}
+	}
+	e.Time = round(total)
+}
+
+// HAR returns the document of the events added so far. The requests that
+// have neither a response nor an error are left out.
+func (b *Builder) HAR() *HAR {
+	h := &HAR{Log: Log{
+		Version: Version,
+		Creator: b.Creator,
+		Pages:   make([]Page, 0, len(b.pages)),
+		Entries: make([]Entry, 0, len(b.entries)),
+	}}
+	for _, p := range b.pages {
+		h.Log.Pages = append(h.Log.Pages, p.Page)
+	}
+	for _, e := range b.entries {
+		if !e.response {
+			continue
+		}
+		if !e.done {
+			// The body of the response has not been fully received.
+			e.finish(e.start)
+			e.done = false
+		}
+		h.Log.Entries = append(h.Log.Entries, e.Entry)
+	}
+	return h
+}
+
+func wallTime(sec float64) string {
+	return time.Unix(0, int64(sec*float64(time.Second))).UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func round(ms float64) float64 {
+	return math.Round(ms*1000) / 1000
+}
+
+func httpVersion(protocol string) string {
+	switch strings.ToLower(protocol) {
+	case "h2":
+		return "HTTP/2"
+	case "h3", "h3-29", "quic":
+		return "HTTP/3"
+	case "":
+		return ""
+	}
+	return strings.ToUpper(protocol)
+}
+
+// headers converts the headers of DevTools, where repeated headers are
+// joined by newlines, sorted by name.
+func headers(h map[string]string) []NameValue {
+	names := make([]string, 0, len(h))
+	for k := range h {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	nv := []NameValue{}
+	for _, k := range names {
+		for _, v := range strings.Split(h[k], "\n") {
+			nv = append(nv, NameValue{Name: k, Value: v})
+		}
+	}
+	return nv
+}
+
+func headerValue(h map[string]string, name string) string {
+	for k, v := range h {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+func queryString(rawURL string) []NameValue {
+	nv := []NameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nv
+	}
+	for _, kv := range strings.Split(u.RawQuery, "&") {
+		if kv == "" {
+			continue
+		}
+		name, value := kv, ""
+		if i := strings.Index(kv, "="); i >= 0 {
+			name, value = kv[:i], kv[i+1:]
+		}
+		if n, err := url.QueryUnescape(name); err == nil {
+			name = n
+		}
+		if v, err := url.QueryUnescape(value); err == nil {
+			value = v
+		}
+		nv = append(nv, NameValue{Name: name, Value: value})
+	}
+	return nv
+}
+
+func requestCookies(h map[string]string) []NameValue {
+	nv := []NameValue{}
+	req := http.Request{Header: http.Header{"Cookie": {headerValue(h, "Cookie")}}}
+	for _, c := range req.Cookies() {
+		nv = append(nv, NameValue{Name: c.Name, Value: c.Value})
+	}
+	return nv
+}
+
+func responseCookies(h map[string]string) []NameValue {
+	nv := []NameValue{}
+	v := headerValue(h, "Set-Cookie")
+	if v == "" {
+		return nv
+	}
+	resp := http.Response{Header: http.Header{"Set-Cookie": strings.Split(v, "\n")}}
+	for _, c := range resp.Cookies() {
+		nv = append(nv, NameValue{Name: c.Name, Value: c.Value})
+	}
+	return nv
+}
@@ -0,0 +1,138 @@
+// Package har builds HTTP Archive (HAR) 1.2 documents from the network
+// events of the Chrome DevTools Protocol, as found in the performance log of
+// ChromeDriver.
+//
+// The performance log must be enabled in the capabilities of the session:
+//
+//	caps.AddLogging(log.Capabilities{log.Performance: log.All})
+//
+// See http://www.softwareishard.com/blog/har-12-spec/ for the format.
+package har
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// Version is the version of the HAR format written by this package.
+const Version = "1.2"
+
+// HAR is an HTTP Archive document.
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log is the root object of a HAR document.
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Pages   []Page  `json:"pages"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator describes the application that created the document.
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Page is a page load. Entries refer to their page by ID.
+type Page struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	ID              string      `json:"id"`
+	Title           string      `json:"title"`
+	PageTimings     PageTimings `json:"pageTimings"`
+}
+
+// PageTimings are the times, in milliseconds since the start of the page
+// load, of its DOMContentLoaded and load events. They are -1 if unknown.
+type PageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad"`
+	OnLoad        float64 `json:"onLoad"`
+}
+
+// Entry is an HTTP request and its response.
+type Entry struct {
+	Pageref         string `json:"pageref,omitempty"`
+	StartedDateTime string `json:"startedDateTime"`
+	// Time is the total time of the request in milliseconds.
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           Cache    `json:"cache"`
+	Timings         Timings  `json:"timings"`
+	ServerIPAddress string   `json:"serverIPAddress,omitempty"`
+	Comment         string   `json:"comment,omitempty"`
+}
+
+// NameValue is a header, a query string parameter or a cookie.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Request is an HTTP request.
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData,omitempty"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// PostData is the body of a request.
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Response is an HTTP response.
+type Response struct {
+	Status      int         `json:"status"`
+	StatusText  string      `json:"statusText"`
+	HTTPVersion string      `json:"httpVersion"`
+	Cookies     []NameValue `json:"cookies"`
+	Headers     []NameValue `json:"headers"`
+	Content     Content     `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+}
+
+// Content describes the body of a response. The performance log does not
+// contain the bodies, so Text is usually empty.
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Cache describes the use of the browser cache. It is always empty.
+type Cache struct{}
+
+// Timings are the durations, in milliseconds, of the phases of a request.
+// Phases that did not happen are -1.
+type Timings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	// SSL is included in Connect.
+	SSL float64 `json:"ssl"`
+}
+
+// WriteFile writes h to the file at path, as indented JSON.
+func (h *HAR) WriteFile(path string) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
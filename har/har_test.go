@@ -0,0 +1,139 @@
+package har
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/injoyai/selenium/log"
+)
+
+// performanceLog is the performance log of a page load at
+// http://example.com/old, redirected to /, which loads a script and a
+// blocked image.
+var performanceLog = []string{
+	`{"message":{"method":"Network.requestWillBeSent","params":{"requestId":"L1","loaderId":"L1","frameId":"F1","documentURL":"http://example.com/old","type":"Document","timestamp":100,"wallTime":1600000000,"request":{"url":"http://example.com/old","method":"GET","headers":{"Accept":"text/html"}}}},"webview":"F1"}`,
+	`{"message":{"method":"Network.requestWillBeSent","params":{"requestId":"L1","loaderId":"L1","frameId":"F1","documentURL":"http://example.com/","type":"Document","timestamp":100.05,"wallTime":1600000000.05,"request":{"url":"http://example.com/","method":"GET","headers":{"Accept":"text/html"}},"redirectResponse":{"url":"http://example.com/old","status":301,"statusText":"Moved Permanently","headers":{"Location":"/"},"mimeType":"","protocol":"http/1.1"}}},"webview":"F1"}`,
+	`{"message":{"method":"Network.responseReceived","params":{"requestId":"L1","loaderId":"L1","timestamp":100.2,"type":"Document","response":{"url":"http://example.com/","status":200,"statusText":"OK","headers":{"Content-Type":"text/html","Set-Cookie":"a=1\nb=2"},"requestHeaders":{"Accept":"text/html","Cookie":"c=3"},"mimeType":"text/html","protocol":"h2","encodedDataLength":234,"remoteIPAddress":"192.0.2.1","timing":{"requestTime":100.06,"dnsStart":1,"dnsEnd":11,"connectStart":11,"connectEnd":41,"sslStart":21,"sslEnd":41,"sendStart":42,"sendEnd":43,"receiveHeadersEnd":93}}}},"webview":"F1"}`,
+	`{"message":{"method":"Network.dataReceived","params":{"requestId":"L1","timestamp":100.25,"dataLength":2000,"encodedDataLength":0}},"webview":"F1"}`,
+	`{"message":{"method":"Network.dataReceived","params":{"requestId":"L1","timestamp":100.28,"dataLength":1500,"encodedDataLength":0}},"webview":"F1"}`,
+	`{"message":{"method":"Network.loadingFinished","params":{"requestId":"L1","timestamp":100.3,"encodedDataLength":1234}},"webview":"F1"}`,
+	`{"message":{"method":"Page.domContentEventFired","params":{"timestamp":100.4}},"webview":"F1"}`,
+	`{"message":{"method":"Network.requestWillBeSent","params":{"requestId":"2","loaderId":"L1","frameId":"F1","documentURL":"http://example.com/","type":"Script","timestamp":100.31,"wallTime":1600000000.31,"request":{"url":"http://example.com/app.js?v=1&q=a%20b","method":"POST","headers":{"Content-Type":"text/plain"},"postData":"hello"}}},"webview":"F1"}`,
+	`{"message":{"method":"Network.requestWillBeSent","params":{"requestId":"3","loaderId":"L1","frameId":"F1","documentURL":"http://example.com/","type":"Image","timestamp":100.32,"wallTime":1600000000.32,"request":{"url":"http://example.com/ad.png","method":"GET","headers":{}}}},"webview":"F1"}`,
+	`{"message":{"method":"Network.responseReceived","params":{"requestId":"2","loaderId":"L1","timestamp":100.35,"type":"Script","response":{"url":"http://example.com/app.js","status":200,"statusText":"OK","headers":{"Content-Type":"text/javascript"},"mimeType":"text/javascript","protocol":"h2"}}},"webview":"F1"}`,
+	`{"message":{"method":"Network.loadingFailed","params":{"requestId":"3","timestamp":100.33,"type":"Image","errorText":"net::ERR_BLOCKED_BY_CLIENT"}},"webview":"F1"}`,
+	`{"message":{"method":"Network.loadingFinished","params":{"requestId":"2","timestamp":100.36,"encodedDataLength":10}},"webview":"F1"}`,
+	`{"message":{"method":"Page.loadEventFired","params":{"timestamp":100.5}},"webview":"F1"}`,
+	`{"message":{"method":"Network.requestWillBeSent","params":{"requestId":"4","loaderId":"L1","frameId":"F1","documentURL":"http://example.com/","type":"XHR","timestamp":101,"wallTime":1600000001,"request":{"url":"http://example.com/pending","method":"GET","headers":{}}}},"webview":"F1"}`,
+}
+
+func messages() []log.Message {
+	msgs := make([]log.Message, len(performanceLog))
+	for i, m := range performanceLog {
+		msgs[i] = log.Message{Level: log.Info, Message: m}
+	}
+	return msgs
+}
+
+func TestConvert(t *testing.T) {
+	h, err := Convert(messages())
+	if err != nil {
+		t.Fatalf("Convert() returned error: %v", err)
+	}
+
+	if h.Log.Version != Version {
+		t.Errorf("Log.Version = %q, want %q", h.Log.Version, Version)
+	}
+	wantPages := []Page{{
+		StartedDateTime: "2020-09-13T12:26:40.000Z",
+		ID:              "page_1",
+		Title:           "http://example.com/old",
+		PageTimings:     PageTimings{OnContentLoad: 400, OnLoad: 500},
+	}}
+	if !reflect.DeepEqual(h.Log.Pages, wantPages) {
+		t.Errorf("Log.Pages = %+v, want %+v", h.Log.Pages, wantPages)
+	}
+
+	// The pending request has no response, and is left out.
+	if len(h.Log.Entries) != 4 {
+		t.Fatalf("len(Log.Entries) = %d, want 4", len(h.Log.Entries))
+	}
+	redirect, doc, script, image := h.Log.Entries[0], h.Log.Entries[1], h.Log.Entries[2], h.Log.Entries[3]
+
+	if redirect.Response.Status != 301 || redirect.Response.RedirectURL != "/" || redirect.Pageref != "page_1" {
+		t.Errorf("redirect entry = %+v, want a 301 to / in page_1", redirect)
+	}
+	if got, want := redirect.Timings.Receive, 50.0; got != want {
+		t.Errorf("redirect Timings.Receive = %v, want %v", got, want)
+	}
+
+	if doc.Response.Status != 200 || doc.Response.HTTPVersion != "HTTP/2" || doc.ServerIPAddress != "192.0.2.1" {
+		t.Errorf("document entry = %+v, want a 200 over HTTP/2 from 192.0.2.1", doc)
+	}
+	wantTimings := Timings{Blocked: 11, DNS: 10, Connect: 30, SSL: 20, Send: 1, Wait: 50, Receive: 147}
+	if doc.Timings != wantTimings {
+		t.Errorf("document Timings = %+v, want %+v", doc.Timings, wantTimings)
+	}
+	if doc.Time != 249 {
+		t.Errorf("document Time = %v, want 249", doc.Time)
+	}
+	if want := []NameValue{{"a", "1"}, {"b", "2"}}; !reflect.DeepEqual(doc.Response.Cookies, want) {
+		t.Errorf("document Response.Cookies = %v, want %v", doc.Response.Cookies, want)
+	}
+	if want := []NameValue{{"c", "3"}}; !reflect.DeepEqual(doc.Request.Cookies, want) {
+		t.Errorf("document Request.Cookies = %v, want %v", doc.Request.Cookies, want)
+	}
+	if doc.Response.Content.Size != 3500 || doc.Response.Content.MimeType != "text/html" {
+		t.Errorf("document Response.Content = %+v, want 3500 bytes of text/html", doc.Response.Content)
+	}
+	// The headers took 234 of the 1234 bytes received.
+	if doc.Response.BodySize != 1000 {
+		t.Errorf("document Response.BodySize = %d, want 1000", doc.Response.BodySize)
+	}
+	// The size of the headers of the script is unknown.
+	if script.Response.BodySize != -1 {
+		t.Errorf("script Response.BodySize = %d, want -1", script.Response.BodySize)
+	}
+
+	if want := []NameValue{{"v", "1"}, {"q", "a b"}}; !reflect.DeepEqual(script.Request.QueryString, want) {
+		t.Errorf("script Request.QueryString = %v, want %v", script.Request.QueryString, want)
+	}
+	if want := (&PostData{MimeType: "text/plain", Text: "hello"}); !reflect.DeepEqual(script.Request.PostData, want) {
+		t.Errorf("script Request.PostData = %+v, want %+v", script.Request.PostData, want)
+	}
+
+	if image.Comment != "net::ERR_BLOCKED_BY_CLIENT" || image.Response.Status != 0 {
+		t.Errorf("image entry = %+v, want a failed request", image)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	h, err := Convert(messages())
+	if err != nil {
+		t.Fatalf("Convert() returned error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "page.har")
+	if err := h.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got HAR
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("WriteFile() wrote invalid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(&got, h) {
+		t.Errorf("WriteFile() wrote %+v, want %+v", got, h)
+	}
+}
+
+func TestConvertInvalid(t *testing.T) {
+	if _, err := Convert([]log.Message{{Message: "not JSON"}}); err == nil {
+		t.Errorf("Convert() of an invalid message did not return an error")
+	}
+}
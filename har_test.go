@@ -0,0 +1,56 @@
+package selenium
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHAR(t *testing.T) {
+	messages := []string{
+		`{"message":{"method":"Network.requestWillBeSent","params":{"requestId":"1","loaderId":"1","frameId":"F","type":"Document","timestamp":1,"wallTime":1600000000,"request":{"url":"http://example.com/","method":"GET","headers":{}}}}}`,
+		`{"message":{"method":"Network.responseReceived","params":{"requestId":"1","timestamp":1.1,"response":{"url":"http://example.com/","status":200,"statusText":"OK","headers":{},"mimeType":"text/html"}}}}`,
+		`{"message":{"method":"Network.loadingFinished","params":{"requestId":"1","timestamp":1.2,"encodedDataLength":10}}}`,
+	}
+	var logCalls int
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", jsonContentType)
+		if !strings.HasSuffix(r.URL.Path, "/log") {
+			fmt.Fprint(w, `{"value":{"sessionId":"1","capabilities":{"browserName":"chrome"}}}`)
+			return
+		}
+		logCalls++
+		var entries []map[string]interface{}
+		if logCalls == 2 {
+			for _, m := range messages {
+				entries = append(entries, map[string]interface{}{"timestamp": 1600000000000, "level": "INFO", "message": m})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"value": entries})
+	}))
+	defer hs.Close()
+
+	wd, err := NewRemote(nil, hs.URL)
+	if err != nil {
+		t.Fatalf("NewRemote() returned error: %v", err)
+	}
+	if err := wd.StartHAR(); err != nil {
+		t.Fatalf("StartHAR() returned error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "page.har")
+	h, err := wd.StopHAR(path)
+	if err != nil {
+		t.Fatalf("StopHAR() returned error: %v", err)
+	}
+	if len(h.Log.Entries) != 1 || h.Log.Entries[0].Response.Status != 200 {
+		t.Errorf("StopHAR() returned entries %+v, want a single 200 response", h.Log.Entries)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("StopHAR() did not write the file: %v", err)
+	}
+}
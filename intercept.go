@@ -0,0 +1,238 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/injoyai/selenium/cdp"
+)
+
+// InterceptedRequest is a request of the browser paused by an interception.
+type InterceptedRequest struct {
+	// ID identifies the paused request.
+	ID     string
+	URL    string
+	Method string
+	Header http.Header
+	// PostData is the body of the request, if any.
+	PostData []byte
+	// ResourceType is the type of the resource, e.g. "Document", "Image",
+	// "Script", "Stylesheet" or "XHR".
+	ResourceType string
+}
+
+// RequestModification lists the changes made to an intercepted request.
+// The empty fields keep the values of the original request.
+type RequestModification struct {
+	URL      string
+	Method   string
+	PostData []byte
+	// Header, if not nil, replaces all the headers of the request.
+	Header http.Header
+}
+
+// InterceptAction is what an interception does with a request. It is
+// returned by InterceptHandler.
+type InterceptAction struct {
+	fail    string
+	modify  *RequestModification
+	fulfill *cdp.FulfillRequest
+}
+
+// ContinueRequest sends the intercepted request unchanged.
+func ContinueRequest() *InterceptAction {
+	return &InterceptAction{}
+}
+
+// ModifyRequest sends the intercepted request with the changes of m.
+func ModifyRequest(m RequestModification) *InterceptAction {
+	return &InterceptAction{modify: &m}
+}
+
+// FailRequest fails the intercepted request with a network error. reason is
+// one of the error reasons of the DevTools protocol, e.g. "Failed",
+// "Aborted", "BlockedByClient", "AccessDenied" or "ConnectionRefused".
+func FailRequest(reason string) *InterceptAction {
+	if reason == "" {
+		reason = "Failed"
+	}
+	return &InterceptAction{fail: reason}
+}
+
+// FulfillRequest answers the intercepted request with the given response,
+// without sending it to the network.
+func FulfillRequest(status int, header http.Header, body []byte) *InterceptAction {
+	return &InterceptAction{fulfill: &cdp.FulfillRequest{
+		ResponseCode:    status,
+		ResponseHeaders: headerEntries(header),
+		Body:            body,
+	}}
+}
+
+// InterceptHandler decides what to do with an intercepted request. A nil
+// action continues the request. Handlers run concurrently, one goroutine per
+// request.
+type InterceptHandler func(req *InterceptedRequest) *InterceptAction
+
+// Interception is an active request interception, created by
+// WebDriver.Intercept.
+type Interception struct {
+	client *cdp.Client
+	sub    *cdp.Subscription
+	done   chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// Intercept pauses the requests of the page of the current window whose URL
+// matches pattern, where "*" matches any sequence of characters and "?" any
+// single character, and resumes each as told by handler. An empty pattern
+// matches all the requests. For example, to block images:
+//
+//	wd.Intercept("*.png", func(*selenium.InterceptedRequest) *selenium.InterceptAction {
+//		return selenium.FailRequest("BlockedByClient")
+//	})
+//
+// Interception uses the Fetch domain of the Chrome DevTools Protocol, so the
+// browser must expose a DevTools endpoint (see WebDriver.CDP). It lasts until
+// Stop is called, and keeps intercepting the requests of the same page if
+// another window is selected.
+func (wd *WebDriver) Intercept(pattern string, handler InterceptHandler) (*Interception, error) {
+	if handler == nil {
+		return nil, errors.New("selenium: nil intercept handler")
+	}
+	ctx := wd.Context()
+	client, err := wd.CDP(ctx)
+	if err != nil {
+		return nil, err
+	}
+	i := &Interception{
+		client: client,
+		sub:    client.Subscribe(cdp.EventFetchRequestPaused),
+		done:   make(chan struct{}),
+	}
+	if err := client.Fetch().Enable(ctx, []cdp.RequestPattern{{URLPattern: pattern}}); err != nil {
+		client.Close()
+		return nil, err
+	}
+	go i.serve(handler)
+	return i, nil
+}
+
+func (i *Interception) serve(handler InterceptHandler) {
+	defer close(i.done)
+	for ev := range i.sub.C {
+		var p cdp.RequestPaused
+		if err := ev.Decode(&p); err != nil {
+			i.setErr(err)
+			// Continue the request, if it can be identified, rather than
+			// leaving the page waiting for it.
+			var id struct {
+				RequestID string `json:"requestId"`
+			}
+			if json.Unmarshal(ev.Params, &id) == nil && id.RequestID != "" {
+				go func() {
+					if err := i.resume(&cdp.RequestPaused{RequestID: id.RequestID}, nil); err != nil {
+						i.setErr(err)
+					}
+				}()
+			}
+			continue
+		}
+		go func() {
+			if err := i.resume(&p, handler(newInterceptedRequest(&p))); err != nil {
+				i.setErr(err)
+			}
+		}()
+	}
+}
+
+func newInterceptedRequest(p *cdp.RequestPaused) *InterceptedRequest {
+	req := &InterceptedRequest{
+		ID:           p.RequestID,
+		URL:          p.Request.URL,
+		Method:       p.Request.Method,
+		Header:       make(http.Header, len(p.Request.Headers)),
+		ResourceType: p.ResourceType,
+	}
+	for k, v := range p.Request.Headers {
+		req.Header.Set(k, v)
+	}
+	if p.Request.PostData != "" {
+		req.PostData = []byte(p.Request.PostData)
+	}
+	return req
+}
+
+func (i *Interception) resume(p *cdp.RequestPaused, a *InterceptAction) error {
+	ctx := context.Background()
+	fetch := i.client.Fetch()
+	switch {
+	case a == nil:
+		return fetch.ContinueRequest(ctx, cdp.ContinueRequest{RequestID: p.RequestID})
+	case a.fail != "":
+		return fetch.FailRequest(ctx, p.RequestID, a.fail)
+	case a.fulfill != nil:
+		f := *a.fulfill
+		f.RequestID = p.RequestID
+		return fetch.FulfillRequest(ctx, f)
+	case a.modify != nil:
+		return fetch.ContinueRequest(ctx, cdp.ContinueRequest{
+			RequestID: p.RequestID,
+			URL:       a.modify.URL,
+			Method:    a.modify.Method,
+			PostData:  a.modify.PostData,
+			Headers:   headerEntries(a.modify.Header),
+		})
+	}
+	return fetch.ContinueRequest(ctx, cdp.ContinueRequest{RequestID: p.RequestID})
+}
+
+// headerEntries converts h to the header list of the DevTools protocol, in
+// a stable order.
+func headerEntries(h http.Header) []cdp.HeaderEntry {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var entries []cdp.HeaderEntry
+	for _, k := range keys {
+		for _, v := range h[k] {
+			entries = append(entries, cdp.HeaderEntry{Name: k, Value: v})
+		}
+	}
+	return entries
+}
+
+func (i *Interception) setErr(err error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.err == nil {
+		i.err = err
+	}
+}
+
+// Err returns the first error met while resuming a request, if any.
+func (i *Interception) Err() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.err
+}
+
+// Stop stops the interception. The browser resumes the requests that are
+// still paused.
+func (i *Interception) Stop() error {
+	err := i.client.Fetch().Disable(context.Background())
+	i.sub.Close()
+	<-i.done
+	if cerr := i.client.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package selenium
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIntercept(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		resumed = make(map[string]map[string]interface{})
+		enabled interface{}
+		allDone = make(chan struct{})
+	)
+	wd := newDevToolsSession(t, func(method string, params map[string]interface{}, send func(string)) {
+		switch method {
+		case "Fetch.enable":
+			mu.Lock()
+			enabled = params["patterns"]
+			mu.Unlock()
+			for i, u := range []string{"/app.js", "/ad.png", "/api", "/form"} {
+				send(fmt.Sprintf(`{"method":"Fetch.requestPaused","params":{"requestId":"r%d","resourceType":"Other","request":{"url":"http://example.com%s","method":"GET","headers":{"Accept":"*/*"}}}}`, i, u))
+			}
+		case "Fetch.continueRequest", "Fetch.failRequest", "Fetch.fulfillRequest":
+			params["method"] = method
+			mu.Lock()
+			resumed[params["requestId"].(string)] = params
+			if len(resumed) == 4 {
+				close(allDone)
+			}
+			mu.Unlock()
+		}
+	})
+	i, err := wd.Intercept("http://example.com/*", func(req *InterceptedRequest) *InterceptAction {
+		switch {
+		case strings.HasSuffix(req.URL, ".png"):
+			return FailRequest("BlockedByClient")
+		case strings.HasSuffix(req.URL, "/api"):
+			return FulfillRequest(http.StatusOK, http.Header{"Content-Type": {"application/json"}}, []byte(`{}`))
+		case strings.HasSuffix(req.URL, "/form"):
+			h := req.Header.Clone()
+			h.Set("X-Test", "1")
+			return ModifyRequest(RequestModification{Method: "POST", Header: h})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Intercept() returned error: %v", err)
+	}
+	select {
+	case <-allDone:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the intercepted requests were not all resumed")
+	}
+	if err := i.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	if err := i.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := fmt.Sprint(enabled), "[map[urlPattern:http://example.com/*]]"; got != want {
+		t.Errorf("Fetch.enable patterns = %s, want %s", got, want)
+	}
+	for _, tc := range []struct {
+		id, method, field string
+		want              interface{}
+	}{
+		{"r0", "Fetch.continueRequest", "method", "Fetch.continueRequest"},
+		{"r1", "Fetch.failRequest", "errorReason", "BlockedByClient"},
+		{"r2", "Fetch.fulfillRequest", "body", "e30="},
+		{"r3", "Fetch.continueRequest", "headers", []interface{}{
+			map[string]interface{}{"name": "Accept", "value": "*/*"},
+			map[string]interface{}{"name": "X-Test", "value": "1"},
+		}},
+	} {
+		p := resumed[tc.id]
+		if p["method"] != tc.method {
+			t.Errorf("request %s resumed with %v, want %s", tc.id, p["method"], tc.method)
+			continue
+		}
+		if got := fmt.Sprint(p[tc.field]); got != fmt.Sprint(tc.want) {
+			t.Errorf("request %s: %s = %s, want %v", tc.id, tc.field, got, tc.want)
+		}
+	}
+}
+
+func TestInterceptInvalidEvent(t *testing.T) {
+	continued := make(chan string, 1)
+	wd := newDevToolsSession(t, func(method string, params map[string]interface{}, send func(string)) {
+		switch method {
+		case "Fetch.enable":
+			send(`{"method":"Fetch.requestPaused","params":{"requestId":"r0","request":"invalid"}}`)
+		case "Fetch.continueRequest":
+			continued <- params["requestId"].(string)
+		}
+	})
+	if _, err := wd.Intercept("", nil); err == nil {
+		t.Errorf("Intercept() with a nil handler returned no error")
+	}
+
+	i, err := wd.Intercept("", func(*InterceptedRequest) *InterceptAction {
+		t.Errorf("the handler was called for an invalid event")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Intercept() returned error: %v", err)
+	}
+	select {
+	case id := <-continued:
+		if id != "r0" {
+			t.Errorf("continued request %q, want r0", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the request of the invalid event was not continued")
+	}
+	if err := i.Stop(); err != nil {
+		t.Fatalf("Stop() returned error: %v", err)
+	}
+	if i.Err() == nil {
+		t.Errorf("Err() = nil, want the decoding error")
+	}
+}
@@ -0,0 +1,130 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEcho(t *testing.T) {
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Test") != "1" {
+			t.Errorf("server received X-Test %q, want %q", r.Header.Get("X-Test"), "1")
+		}
+		c, err := Upgrade(w, r)
+		if err != nil {
+			t.Errorf("Upgrade() returned error: %v", err)
+			return
+		}
+		defer c.Close()
+		for {
+			msg, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := c.WriteMessage(msg); err != nil {
+				return
+			}
+		}
+	}))
+	defer hs.Close()
+
+	c, err := Dial(context.Background(), "ws"+strings.TrimPrefix(hs.URL, "http"), http.Header{"X-Test": {"1"}})
+	if err != nil {
+		t.Fatalf("Dial() returned error: %v", err)
+	}
+	for _, msg := range [][]byte{
+		[]byte("hello"),
+		{},
+		bytes.Repeat([]byte("a"), 200),
+		bytes.Repeat([]byte("b"), 70000),
+	} {
+		if err := c.WriteMessage(msg); err != nil {
+			t.Fatalf("WriteMessage() returned error: %v", err)
+		}
+		got, err := c.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage() returned error: %v", err)
+		}
+		if !bytes.Equal(got, msg) {
+			t.Errorf("ReadMessage() returned %d bytes, want %d", len(got), len(msg))
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close() returned error: %v", err)
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		c.writeFrame(opPing, []byte("p"))
+		// A fragmented message.
+		c.conn.Write([]byte{opText, 2, 'a', 'b', 0x80 | opContinuation, 1, 'c'})
+		c.Close()
+	}))
+	defer hs.Close()
+
+	c, err := Dial(context.Background(), "ws"+strings.TrimPrefix(hs.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() returned error: %v", err)
+	}
+	msg, err := c.ReadMessage()
+	if err != nil || string(msg) != "abc" {
+		t.Fatalf("ReadMessage() = %q, %v, want %q", msg, err, "abc")
+	}
+	if _, err := c.ReadMessage(); err != io.EOF {
+		t.Fatalf("ReadMessage() after close returned error %v, want io.EOF", err)
+	}
+}
+
+func TestDialRejected(t *testing.T) {
+	hs := httptest.NewServer(http.NotFoundHandler())
+	defer hs.Close()
+	if _, err := Dial(context.Background(), "ws"+strings.TrimPrefix(hs.URL, "http"), nil); err == nil {
+		t.Fatalf("Dial() to a plain HTTP handler did not return an error")
+	}
+}
+
+func TestDialStalledTLS(t *testing.T) {
+	// The server accepts the connection but never answers the TLS handshake.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() returned error: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := Dial(ctx, "wss://"+l.Addr().String(), nil)
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		if err != context.DeadlineExceeded {
+			t.Errorf("Dial() returned %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Dial() did not return once its context was done")
+	}
+}
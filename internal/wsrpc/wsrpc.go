@@ -0,0 +1,277 @@
+// Package wsrpc implements the command and event plumbing shared by the
+// DevTools and BiDi clients, whose protocols send JSON commands with an ID
+// over a WebSocket and receive the replies to these commands interleaved with
+// events.
+package wsrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/injoyai/selenium/internal/websocket"
+)
+
+// EventBufferSize is the capacity of the channels that deliver the events of
+// a subscription. The events that do not fit are queued until they are
+// received: they are never dropped, and a slow reader does not block the
+// connection, but a subscription that is not read keeps its events until it
+// is closed.
+const EventBufferSize = 256
+
+// Message is a message received from the remote end, as decoded by the
+// Decoder of a Conn.
+type Message struct {
+	// ID is the ID of the command that the message replies to.
+	ID int64
+	// Method is the name of the event, or empty if the message is a reply.
+	Method string
+	// Params are the parameters of the event.
+	Params json.RawMessage
+	// SessionID is the target session that sent the event, if any.
+	SessionID string
+	// Result is the result of the command.
+	Result json.RawMessage
+	// Err is the error returned for the command, if any.
+	Err error
+}
+
+// Decoder decodes a message of the remote end. The messages for which it
+// returns false are ignored.
+type Decoder func(data []byte) (Message, bool)
+
+// Conn is a connection to the remote end. It is safe for concurrent use.
+type Conn struct {
+	ws     *websocket.Conn
+	decode Decoder
+	prefix string
+	closed error
+
+	mu      sync.Mutex
+	lastID  int64
+	pending map[int64]chan Message
+	subs    []*Subscription
+	err     error
+	done    chan struct{}
+}
+
+// New returns a Conn that reads the messages of ws with decode. The errors
+// of the connection are prefixed with prefix, e.g. "cdp", and the commands
+// sent on, or pending when closing, a closed Conn fail with closed.
+func New(ws *websocket.Conn, decode Decoder, prefix string, closed error) *Conn {
+	c := &Conn{
+		ws:      ws,
+		decode:  decode,
+		prefix:  prefix,
+		closed:  closed,
+		pending: make(map[int64]chan Message),
+		done:    make(chan struct{}),
+	}
+	go c.read()
+	return c
+}
+
+// Call sends the command that encode returns for the next command ID, waits
+// for its reply and returns its result, or its error.
+func (c *Conn) Call(ctx context.Context, encode func(id int64) ([]byte, error)) (json.RawMessage, error) {
+	ch := make(chan Message, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.lastID++
+	id := c.lastID
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	data, err := encode(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.ws.WriteMessage(data); err != nil {
+		return nil, fmt.Errorf("%s: %v", c.prefix, err)
+	}
+
+	select {
+	case m := <-ch:
+		return m.Result, m.Err
+	case <-c.done:
+		return nil, c.Err()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Subscription queues the events it matches, in the order they are
+// received.
+type Subscription struct {
+	conn  *Conn
+	match func(*Message) bool
+
+	mu      sync.Mutex
+	queue   []Message
+	ended   bool          // No more events will be queued.
+	ready   chan struct{} // Signals that the queue changed.
+	closing chan struct{}
+}
+
+// Subscribe returns a subscription to the events for which match returns
+// true. match is called with the lock of the connection held.
+func (c *Conn) Subscribe(match func(*Message) bool) *Subscription {
+	s := &Subscription{
+		conn:    c,
+		match:   match,
+		ready:   make(chan struct{}, 1),
+		closing: make(chan struct{}),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		s.ended = true
+		return s
+	}
+	c.subs = append(c.subs, s)
+	return s
+}
+
+// Next waits for the next event. It returns false once the subscription is
+// closed, or once the connection is closed and the events received before
+// were returned.
+func (s *Subscription) Next() (Message, bool) {
+	for {
+		s.mu.Lock()
+		select {
+		case <-s.closing:
+			s.mu.Unlock()
+			return Message{}, false
+		default:
+		}
+		if len(s.queue) > 0 {
+			m := s.queue[0]
+			s.queue[0] = Message{}
+			s.queue = s.queue[1:]
+			s.mu.Unlock()
+			return m, true
+		}
+		ended := s.ended
+		s.mu.Unlock()
+		if ended {
+			return Message{}, false
+		}
+		select {
+		case <-s.ready:
+		case <-s.closing:
+		}
+	}
+}
+
+// Close stops the delivery of events and discards the queued ones.
+func (s *Subscription) Close() {
+	s.conn.mu.Lock()
+	defer s.conn.mu.Unlock()
+	for i, o := range s.conn.subs {
+		if o == s {
+			s.conn.subs = append(s.conn.subs[:i:i], s.conn.subs[i+1:]...)
+			break
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.closing:
+	default:
+		close(s.closing)
+	}
+	s.queue = nil
+}
+
+// Closing returns a channel that is closed when Close is called.
+func (s *Subscription) Closing() <-chan struct{} {
+	return s.closing
+}
+
+// push queues the event m, or marks the end of the events if end is true.
+func (s *Subscription) push(m Message, end bool) {
+	s.mu.Lock()
+	if end {
+		s.ended = true
+	} else {
+		s.queue = append(s.queue, m)
+	}
+	s.mu.Unlock()
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Done returns a channel that is closed when the connection is closed.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason the connection was closed, or nil while it is open.
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the connection. Pending commands fail with the closed error
+// of the Conn.
+func (c *Conn) Close() error {
+	err := c.ws.Close()
+	c.shutdown(c.closed)
+	return err
+}
+
+func (c *Conn) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+	for _, s := range c.subs {
+		s.push(Message{}, true)
+	}
+	c.subs = nil
+}
+
+// read dispatches the messages of the remote end until the connection fails.
+func (c *Conn) read() {
+	for {
+		data, err := c.ws.ReadMessage()
+		if err != nil {
+			c.shutdown(c.closed)
+			return
+		}
+		m, ok := c.decode(data)
+		if !ok {
+			continue
+		}
+
+		c.mu.Lock()
+		if m.Method == "" {
+			if ch, ok := c.pending[m.ID]; ok {
+				ch <- m
+			}
+			c.mu.Unlock()
+			continue
+		}
+		for _, s := range c.subs {
+			if s.match(&m) {
+				s.push(m, false)
+			}
+		}
+		c.mu.Unlock()
+	}
+}
@@ -0,0 +1,90 @@
+package wsrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/injoyai/selenium/internal/websocket"
+)
+
+// message is the format of the messages of the test server.
+type message struct {
+	ID     int64           `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+func decode(data []byte) (Message, bool) {
+	var m message
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Message{}, false
+	}
+	return Message{ID: m.ID, Method: m.Method, Params: m.Params, Result: json.RawMessage(`{}`)}, true
+}
+
+var errClosed = errors.New("test: connection closed")
+
+func TestSubscriptionKeepsEvents(t *testing.T) {
+	// More events than a subscription channel holds.
+	const events = 3 * EventBufferSize
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Upgrade(w, r)
+		if err != nil {
+			t.Errorf("Upgrade() returned error: %v", err)
+			return
+		}
+		defer c.Close()
+		for {
+			data, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			var cmd message
+			json.Unmarshal(data, &cmd)
+			for i := 0; i < events; i++ {
+				ev, _ := json.Marshal(message{Method: "tick", Params: json.RawMessage(fmt.Sprint(i))})
+				c.WriteMessage(ev)
+			}
+			reply, _ := json.Marshal(message{ID: cmd.ID})
+			c.WriteMessage(reply)
+		}
+	}))
+	defer hs.Close()
+
+	ctx := context.Background()
+	ws, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(hs.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() returned error: %v", err)
+	}
+	c := New(ws, decode, "test", errClosed)
+	sub := c.Subscribe(func(m *Message) bool { return m.Method == "tick" })
+	// The events are all received before the reply.
+	if _, err := c.Call(ctx, func(id int64) ([]byte, error) {
+		return json.Marshal(message{ID: id, Method: "start"})
+	}); err != nil {
+		t.Fatalf("Call() returned error: %v", err)
+	}
+	c.Close()
+	if _, err := c.Call(ctx, func(id int64) ([]byte, error) { return nil, nil }); err != errClosed {
+		t.Errorf("Call() after Close() returned error %v, want %v", err, errClosed)
+	}
+
+	for i := 0; i < events; i++ {
+		m, ok := sub.Next()
+		if !ok {
+			t.Fatalf("Next() returned %d events, want %d", i, events)
+		}
+		if string(m.Params) != fmt.Sprint(i) {
+			t.Fatalf("event %d has params %s", i, m.Params)
+		}
+	}
+	if _, ok := sub.Next(); ok {
+		t.Errorf("Next() returned an event after the events sent")
+	}
+}
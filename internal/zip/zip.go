@@ -30,33 +30,9 @@ func New(basePath string) (*bytes.Buffer, error) {
 		if !info.Mode().IsRegular() {
 			return nil
 		}
-
-		zipFI, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
-		}
-
 		// Strip the prefix from the filename (and the trailing directory
 		// separator) so that the files are at the root of the zip file.
-		zipFI.Name = filePath[len(basePath)+1:]
-
-		// Without this, the Java zip reader throws a java.util.zip.ZipException:
-		// "only DEFLATED entries can have EXT descriptor".
-		zipFI.Method = zip.Deflate
-
-		w, err := w.CreateHeader(zipFI)
-		if err != nil {
-			return err
-		}
-
-		f, err := os.Open(filePath)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-
-		_, err = io.Copy(w, bufio.NewReader(f))
-		return err
+		return addFile(w, filePath[len(basePath)+1:], filePath, info)
 	})
 	if err != nil {
 		return nil, err
@@ -66,3 +42,52 @@ func New(basePath string) (*bytes.Buffer, error) {
 	}
 	return buf, nil
 }
+
+// NewFile returns a buffer that contains the payload of a Zip file with the
+// single file at path, at the root of the Zip file.
+func NewFile(path string) (*bytes.Buffer, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, fmt.Errorf("path %q is not a regular file", path)
+	}
+
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	if err := addFile(w, filepath.Base(path), path, fi); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// addFile adds the file at path to w under name.
+func addFile(w *zip.Writer, name, path string, info os.FileInfo) error {
+	zipFI, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	zipFI.Name = filepath.ToSlash(name)
+
+	// Without this, the Java zip reader throws a java.util.zip.ZipException:
+	// "only DEFLATED entries can have EXT descriptor".
+	zipFI.Method = zip.Deflate
+
+	fw, err := w.CreateHeader(zipFI)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(fw, bufio.NewReader(f))
+	return err
+}
@@ -0,0 +1,128 @@
+package selenium
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/injoyai/logs"
+)
+
+// Command is a WebDriver command as it is sent to the server.
+type Command struct {
+	// Name is the name of the command, such as "findElement" or "getTitle".
+	Name string
+	// Method is the HTTP method of the request.
+	Method string
+	// URL is the full URL of the request, including the URL prefix of the
+	// server.
+	URL string
+	// Header holds the headers of the request.
+	Header http.Header
+	// Body is the JSON-encoded payload of the request. It is nil for commands
+	// without a payload.
+	Body []byte
+}
+
+// Response is the raw reply of the server to a Command.
+type Response struct {
+	// StatusCode is the HTTP status code, e.g. 200.
+	StatusCode int
+	// Status is the HTTP status line, e.g. "200 OK".
+	Status string
+	// Header holds the headers of the response.
+	Header http.Header
+	// Body is the payload of the response.
+	Body []byte
+}
+
+// Handler sends a Command to the server and returns its reply. An error is
+// only returned if no reply was received; errors reported by the server are
+// decoded from the Response afterwards.
+type Handler func(ctx context.Context, cmd *Command) (*Response, error)
+
+// Interceptor wraps the Handler that sends commands, e.g. to trace, measure,
+// rewrite or record them. An interceptor may modify the command before
+// calling next, inspect or replace the response, or not call next at all.
+type Interceptor func(next Handler) Handler
+
+// LoggingInterceptor writes every command and reply to the wire log. It is
+// the default interceptor of a WebDriver; see Debug and SetLogLevel to
+// control its output.
+func LoggingInterceptor(next Handler) Handler {
+	return func(ctx context.Context, cmd *Command) (*Response, error) {
+		logs.Writef(">>> %s %s %s\n", cmd.Method, filteredURL(cmd.URL), string(cmd.Body))
+		resp, err := next(ctx, cmd)
+		if err == nil {
+			logs.Readf("<<< %s %s\n", resp.Status, string(resp.Body))
+		}
+		return resp, err
+	}
+}
+
+var defaultInterceptors = []Interceptor{LoggingInterceptor}
+
+// WithInterceptors sets the interceptor chain of the WebDriver, replacing the
+// default one. The first interceptor is the outermost one. Include
+// LoggingInterceptor to keep the wire log.
+func WithInterceptors(interceptors ...Interceptor) RemoteOption {
+	return func(wd *WebDriver) error {
+		wd.interceptors = append([]Interceptor{}, interceptors...)
+		return nil
+	}
+}
+
+// Use appends interceptors to the chain of wd. They are called after, i.e.
+// closer to the server than, the interceptors already in use.
+func (wd *WebDriver) Use(interceptors ...Interceptor) {
+	wd.lock()
+	defer wd.unlock()
+	current := wd.interceptors
+	if current == nil {
+		current = defaultInterceptors
+	}
+	wd.interceptors = append(append([]Interceptor{}, current...), interceptors...)
+}
+
+// handler returns the interceptor chain of wd wrapped around roundTrip.
+func (wd *WebDriver) handler() Handler {
+	wd.lock()
+	interceptors := wd.interceptors
+	wd.unlock()
+	if interceptors == nil {
+		interceptors = defaultInterceptors
+	}
+	h := Handler(wd.roundTrip)
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		h = interceptors[i](h)
+	}
+	return h
+}
+
+// roundTrip sends cmd to the server over HTTP.
+func (wd *WebDriver) roundTrip(ctx context.Context, cmd *Command) (*Response, error) {
+	request, err := http.NewRequestWithContext(ctx, cmd.Method, cmd.URL, bytes.NewReader(cmd.Body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header = cmd.Header
+
+	response, err := wd.httpClient().Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	buf, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, errors.New(response.Status)
+	}
+	return &Response{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Header:     response.Header,
+		Body:       buf,
+	}, nil
+}
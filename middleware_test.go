@@ -0,0 +1,78 @@
+package selenium
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	var gotHeader string
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("X-Trace")
+		w.Header().Set("Content-Type", jsonContentType)
+		io.WriteString(w, `{"value":"title"}`)
+	}))
+	defer hs.Close()
+
+	var calls []string
+	record := func(name string) Interceptor {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, cmd *Command) (*Response, error) {
+				calls = append(calls, name+" "+cmd.Name)
+				cmd.Header.Set("X-Trace", name)
+				return next(ctx, cmd)
+			}
+		}
+	}
+	rewrite := func(next Handler) Handler {
+		return func(ctx context.Context, cmd *Command) (*Response, error) {
+			resp, err := next(ctx, cmd)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = []byte(strings.Replace(string(resp.Body), "title", "rewritten", 1))
+			return resp, nil
+		}
+	}
+
+	wd := &WebDriver{id: "1", urlPrefix: hs.URL}
+	if err := WithInterceptors(record("outer"), rewrite)(wd); err != nil {
+		t.Fatalf("WithInterceptors() returned error: %v", err)
+	}
+	wd.Use(record("inner"))
+
+	title, err := wd.Title()
+	if err != nil {
+		t.Fatalf("Title() returned error: %v", err)
+	}
+	if title != "rewritten" {
+		t.Errorf("Title() = %q, want %q", title, "rewritten")
+	}
+	if want := []string{"outer getTitle", "inner getTitle"}; strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Errorf("interceptors called as %q, want %q", calls, want)
+	}
+	if gotHeader != "inner" {
+		t.Errorf("server received X-Trace %q, want %q", gotHeader, "inner")
+	}
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	wd := &WebDriver{id: "1", urlPrefix: "http://invalid.invalid"}
+	wd.Use(func(Handler) Handler {
+		return func(ctx context.Context, cmd *Command) (*Response, error) {
+			return &Response{
+				StatusCode: http.StatusNotFound,
+				Status:     "404 Not Found",
+				Header:     http.Header{"Content-Type": {jsonContentType}},
+				Body:       []byte(`{"value":{"error":"no such window","message":""}}`),
+			}, nil
+		}
+	})
+	if _, err := wd.Title(); err == nil || err.(*Error).Code() != ErrNoSuchWindow {
+		t.Fatalf("Title() returned error %v, want %q", err, ErrNoSuchWindow)
+	}
+}
@@ -0,0 +1,223 @@
+package selenium
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// finder finds elements: a WebDriver, a WebElement or a ShadowRoot.
+type finder interface {
+	FindElement(by, value string) (*WebElement, error)
+	FindElements(by, value string) ([]*WebElement, error)
+}
+
+// lazyElement finds a bound element when it is first used, and again when
+// it is stale.
+type lazyElement struct {
+	find func() (*WebElement, error)
+
+	mu sync.Mutex
+	id string
+}
+
+// ref returns the reference of the element. It finds the element if it was
+// not found yet, or if its reference is stale.
+func (l *lazyElement) ref(stale string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.id == "" || l.id == stale {
+		elem, err := l.find()
+		if err != nil {
+			return "", err
+		}
+		l.id = elem.id
+	}
+	return l.id, nil
+}
+
+var (
+	elementType  = reflect.TypeOf((*WebElement)(nil))
+	elementsType = reflect.TypeOf([]*WebElement(nil))
+)
+
+// Bind binds the fields of page, a pointer to a page object, to the elements
+// of the current page located by their selenium tag, as in FillForm:
+//
+//	type SearchPage struct {
+//		Query   *selenium.WebElement   `selenium:"name=q"`
+//		Submit  *selenium.WebElement   `selenium:"css=.search button"`
+//		Results []*Result              `selenium:"css=.result"`
+//		Header  struct {
+//			Logo *selenium.WebElement `selenium:"id=logo"`
+//		} `selenium:"css=header"`
+//	}
+//
+//	type Result struct {
+//		Title *selenium.WebElement `selenium:"css=h3"`
+//		Link  *selenium.WebElement `selenium:"xpath=.//a"`
+//	}
+//
+//	var page SearchPage
+//	err := wd.Bind(&page)
+//
+// A *WebElement field is found when it is first used, and found again if it
+// is stale, e.g. after the page is reloaded. A []*WebElement field holds the
+// elements found by Bind; they are found again by their position if they are
+// stale. Call Bind again to find new elements.
+//
+// A field whose type is a struct, or a pointer to a struct, is a component:
+// its fields are bound to the elements within the element located by its
+// tag, or within the parent of the component if it has no tag. Bind
+// allocates the nil pointers of the tagged components only. A slice of
+// components has one component for each element found by Bind.
+//
+// The fields without a selenium tag, other than components, are left
+// unchanged.
+func (wd *WebDriver) Bind(page interface{}) error {
+	return bind(wd, wd, page)
+}
+
+// Bind binds the fields of page to the elements within elem. See
+// WebDriver.Bind.
+func (elem *WebElement) Bind(page interface{}) error {
+	return bind(elem.parent, elem, page)
+}
+
+// Bind binds the fields of page to the elements of the shadow root. See
+// WebDriver.Bind.
+func (s *ShadowRoot) Bind(page interface{}) error {
+	return bind(s.parent, s, page)
+}
+
+func bind(wd *WebDriver, scope finder, page interface{}) error {
+	v := reflect.ValueOf(page)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("selenium: cannot bind a %T, which is not a pointer to a struct", page)
+	}
+	return bindStruct(wd, scope, v.Elem())
+}
+
+func bindStruct(wd *WebDriver, scope finder, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		tag, tagged := sf.Tag.Lookup("selenium")
+		if tag == "-" {
+			continue
+		}
+		if err := bindField(wd, scope, v.Field(i), tag, tagged); err != nil {
+			return fmt.Errorf("selenium: binding %s.%s: %w", t.Name(), sf.Name, err)
+		}
+	}
+	return nil
+}
+
+func bindField(wd *WebDriver, scope finder, f reflect.Value, tag string, tagged bool) error {
+	by, value := parseLocator(tag)
+	switch {
+	case f.Type() == elementType:
+		if !tagged {
+			return nil
+		}
+		f.Set(reflect.ValueOf(lazyFind(wd, scope, by, value)))
+		return nil
+	case f.Type() == elementsType:
+		if !tagged {
+			return nil
+		}
+		elems, err := lazyFindAll(wd, scope, by, value)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(elems))
+		return nil
+	case isComponent(f.Type()):
+		if !tagged && f.Kind() == reflect.Ptr && f.IsNil() {
+			return nil
+		}
+		if tagged {
+			scope = lazyFind(wd, scope, by, value)
+		}
+		return bindComponent(wd, scope, f)
+	case f.Kind() == reflect.Slice && isComponent(f.Type().Elem()):
+		if !tagged {
+			return nil
+		}
+		roots, err := lazyFindAll(wd, scope, by, value)
+		if err != nil {
+			return err
+		}
+		s := reflect.MakeSlice(f.Type(), len(roots), len(roots))
+		for i, root := range roots {
+			if err := bindComponent(wd, root, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		f.Set(s)
+		return nil
+	}
+	if tagged {
+		return fmt.Errorf("a %s cannot be bound to an element", f.Type())
+	}
+	return nil
+}
+
+func isComponent(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// bindComponent binds the fields of f, a struct or a pointer to a struct
+// that it allocates if nil, to the elements within scope.
+func bindComponent(wd *WebDriver, scope finder, f reflect.Value) error {
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		f = f.Elem()
+	}
+	return bindStruct(wd, scope, f)
+}
+
+// lazyFind returns the element of scope located by by and value, which is
+// found when it is first used.
+func lazyFind(wd *WebDriver, scope finder, by, value string) *WebElement {
+	return &WebElement{parent: wd, lazy: &lazyElement{
+		find: func() (*WebElement, error) {
+			return scope.FindElement(by, value)
+		},
+	}}
+}
+
+// lazyFindAll finds the elements of scope located by by and value. They are
+// found again by their position if they are stale.
+func lazyFindAll(wd *WebDriver, scope finder, by, value string) ([]*WebElement, error) {
+	found, err := scope.FindElements(by, value)
+	if err != nil {
+		return nil, err
+	}
+	elems := make([]*WebElement, len(found))
+	for i, elem := range found {
+		i := i
+		elems[i] = &WebElement{parent: wd, lazy: &lazyElement{
+			id: elem.id,
+			find: func() (*WebElement, error) {
+				all, err := scope.FindElements(by, value)
+				if err != nil {
+					return nil, err
+				}
+				if i >= len(all) {
+					return nil, &Error{Err: string(ErrNoSuchElement), Message: fmt.Sprintf("there are no longer %d elements", i+1)}
+				}
+				return all[i], nil
+			},
+		}}
+	}
+	return elems, nil
+}
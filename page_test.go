@@ -0,0 +1,110 @@
+package selenium
+
+import (
+	"errors"
+	"testing"
+)
+
+const searchPage = `<title>Search</title>
+<header><h1 id="logo">Finder</h1></header>
+<form class="search">
+  <input name="q">
+  <button>Search</button>
+</form>
+<div class="result"><h3>First</h3><a href="/1">one</a></div>
+<div class="result"><h3>Second</h3><a href="/2">two</a></div>`
+
+type searchResult struct {
+	Title *WebElement `selenium:"css=h3"`
+	Link  *WebElement `selenium:"xpath=.//a"`
+}
+
+type searchHeader struct {
+	Logo *WebElement `selenium:"id=logo"`
+}
+
+type searchPageObject struct {
+	Query   *WebElement     `selenium:"name=q"`
+	Submit  *WebElement     `selenium:"css=.search button"`
+	Titles  []*WebElement   `selenium:"css=.result h3"`
+	Results []*searchResult `selenium:"css=.result"`
+	Header  searchHeader    `selenium:"css=header"`
+	Missing *WebElement     `selenium:"css=.missing"`
+	Other   *WebElement
+	Skipped *WebElement `selenium:"-"`
+}
+
+func TestBind(t *testing.T) {
+	wd, _ := newFakeSession(t, nil, map[string]string{homePage: searchPage})
+
+	var page searchPageObject
+	if err := wd.Bind(&page); err != nil {
+		t.Fatalf("Bind() returned error: %v", err)
+	}
+	if page.Other != nil || page.Skipped != nil {
+		t.Errorf("Bind() set the fields without a tag")
+	}
+	text := func(elem *WebElement, want string) {
+		t.Helper()
+		if got, err := elem.Text(); err != nil || got != want {
+			t.Errorf("Text() = %q, %v, want %q", got, err, want)
+		}
+	}
+	text(page.Header.Logo, "Finder")
+	text(page.Submit, "Search")
+	if len(page.Titles) != 2 || len(page.Results) != 2 {
+		t.Fatalf("Bind() found %d titles and %d results, want 2", len(page.Titles), len(page.Results))
+	}
+	for i, want := range []string{"First", "Second"} {
+		text(page.Titles[i], want)
+		text(page.Results[i].Title, want)
+	}
+	text(page.Results[1].Link, "two")
+	if err := page.Query.SendKeys("go"); err != nil {
+		t.Fatalf("SendKeys() returned error: %v", err)
+	}
+	if _, err := page.Missing.Text(); !errors.Is(err, ErrNoSuchElement) {
+		t.Errorf("Text() of a missing element returned %v, want %v", err, ErrNoSuchElement)
+	}
+
+	// The elements are stale once the page is reloaded.
+	found, err := wd.FindElement(ByCSSSelector, ".result h3")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	if err := wd.Refresh(); err != nil {
+		t.Fatalf("Refresh() returned error: %v", err)
+	}
+	if _, err := found.Text(); !errors.Is(err, ErrStaleElementReference) {
+		t.Fatalf("Text() of a found element after Refresh() returned %v, want %v", err, ErrStaleElementReference)
+	}
+	text(page.Header.Logo, "Finder")
+	text(page.Titles[1], "Second")
+	text(page.Results[0].Link, "one")
+	if v, err := page.Query.GetProperty("value"); err != nil || v != "" {
+		t.Errorf("the value of the reloaded input is %q, %v, want none", v, err)
+	}
+
+	// A component bound within an element.
+	result, err := wd.FindElement(ByXPATH, "//div[2]")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	var r searchResult
+	if err := result.Bind(&r); err != nil {
+		t.Fatalf("Bind() returned error: %v", err)
+	}
+	text(r.Title, "Second")
+
+	for _, page := range []interface{}{
+		searchPageObject{},
+		(*searchPageObject)(nil),
+		&struct {
+			N int `selenium:"css=p"`
+		}{},
+	} {
+		if err := wd.Bind(page); err == nil {
+			t.Errorf("Bind(%T) returned no error", page)
+		}
+	}
+}
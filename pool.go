@@ -0,0 +1,339 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/injoyai/selenium/cdp"
+)
+
+// ErrPoolClosed is returned by Pool.Acquire once the pool is closed.
+var ErrPoolClosed = errors.New("selenium: pool closed")
+
+// PoolOption configures a Pool.
+type PoolOption func(*Pool) error
+
+// PoolMaxUses recycles the sessions after n uses: the session is quit on
+// its nth release, and a new one is created when needed. Zero, the default,
+// keeps the sessions for the life of the pool.
+func PoolMaxUses(n int) PoolOption {
+	return func(p *Pool) error {
+		if n < 0 {
+			return errors.New("selenium: negative maximum number of uses")
+		}
+		p.maxUses = n
+		return nil
+	}
+}
+
+// PoolResetFunc replaces ResetSession, which cleans the sessions when they are
+// released. A session whose reset fails is recycled.
+func PoolResetFunc(f func(*WebDriver) error) PoolOption {
+	return func(p *Pool) error {
+		p.reset = f
+		return nil
+	}
+}
+
+// PoolRemoteOptions configures the clients of the sessions of the pool.
+func PoolRemoteOptions(opts ...RemoteOption) PoolOption {
+	return func(p *Pool) error {
+		p.remoteOpts = append(p.remoteOpts, opts...)
+		return nil
+	}
+}
+
+// PoolService makes the pool own s: Close stops it once the sessions are
+// quit.
+func PoolService(s *Service) PoolOption {
+	return func(p *Pool) error {
+		p.service = s
+		return nil
+	}
+}
+
+// Pool hands out up to a fixed number of sessions, created on demand
+// against one WebDriver server, to concurrent users. It is safe for
+// concurrent use.
+//
+//	p, err := selenium.NewPool(8, caps, service.GetUrl(), selenium.PoolMaxUses(50))
+//	...
+//	defer p.Close()
+//	wd, err := p.Acquire(ctx)
+//	...
+//	defer p.Release(wd)
+type Pool struct {
+	urlPrefix    string
+	capabilities Capabilities
+	remoteOpts   []RemoteOption
+	maxUses      int
+	reset        func(*WebDriver) error
+	service      *Service
+
+	// tokens holds one token per session in use, or being acquired.
+	tokens chan struct{}
+	// done is closed by Close.
+	done chan struct{}
+
+	mu   sync.Mutex
+	idle []*pooledSession
+	// inUse holds the sessions in use, by the state that the copies of their
+	// WebDriver share, as users may give back copies of the WebDriver they
+	// acquired, e.g. made by WithContext, and may quit them first.
+	inUse map[*sessionState]*pooledSession
+	// out is the number of sessions acquired, or being acquired, that were
+	// not given back yet.
+	out     int
+	closed  bool
+	stopped bool
+}
+
+type pooledSession struct {
+	wd   *WebDriver
+	uses int
+}
+
+// NewPool returns a pool of at most size sessions created with capabilities
+// on the server at urlPrefix, the URL of a Service or a Selenium Grid.
+func NewPool(size int, capabilities Capabilities, urlPrefix string, opts ...PoolOption) (*Pool, error) {
+	if size <= 0 {
+		return nil, errors.New("selenium: the size of the pool must be positive")
+	}
+	p := &Pool{
+		urlPrefix:    urlPrefix,
+		capabilities: capabilities,
+		reset:        ResetSession,
+		tokens:       make(chan struct{}, size),
+		done:         make(chan struct{}),
+		inUse:        make(map[*sessionState]*pooledSession),
+	}
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// Acquire returns a session of the pool, reusing an idle one or creating a
+// new one. If all the sessions are in use, it waits until one is released or
+// ctx is done. The session must be given back with Release, or Discard.
+func (p *Pool) Acquire(ctx context.Context) (*WebDriver, error) {
+	select {
+	case p.tokens <- struct{}{}:
+	case <-p.done:
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.tokens
+		return nil, ErrPoolClosed
+	}
+	p.out++
+	p.mu.Unlock()
+
+	for {
+		p.mu.Lock()
+		var s *pooledSession
+		if n := len(p.idle); n > 0 {
+			s = p.idle[n-1]
+			p.idle = p.idle[:n-1]
+		}
+		p.mu.Unlock()
+
+		if s == nil {
+			break
+		}
+		// Check that the session is still alive, e.g. that the browser did not
+		// crash while idle.
+		if _, err := s.wd.WithContext(ctx).CurrentWindowHandle(); err != nil {
+			s.wd.Quit()
+			continue
+		}
+		p.mu.Lock()
+		p.inUse[s.wd.session()] = s
+		p.mu.Unlock()
+		return s.wd, nil
+	}
+
+	wd, err := p.newSession(ctx)
+	if err != nil {
+		p.finish()
+		return nil, err
+	}
+	p.mu.Lock()
+	p.inUse[wd.session()] = &pooledSession{wd: wd}
+	p.mu.Unlock()
+	return wd, nil
+}
+
+// newSession checks the health of the server, and creates a session.
+func (p *Pool) newSession(ctx context.Context) (*WebDriver, error) {
+	probe := &WebDriver{urlPrefix: p.urlPrefix, ctx: ctx}
+	probe.state.Store(new(sessionState))
+	if probe.urlPrefix == "" {
+		probe.urlPrefix = DefaultURLPrefix
+	}
+	for _, opt := range p.remoteOpts {
+		if err := opt(probe); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := probe.Status(); err != nil {
+		return nil, err
+	}
+	return NewRemoteContext(ctx, p.capabilities, p.urlPrefix, p.remoteOpts...)
+}
+
+// Release gives back a session returned by Acquire. The session is reset
+// for its next user, or quit if it has reached its maximum number of uses,
+// its reset fails, or the pool is closed.
+func (p *Pool) Release(wd *WebDriver) error {
+	p.mu.Lock()
+	s, ok := p.inUse[wd.session()]
+	delete(p.inUse, wd.session())
+	closed := p.closed
+	p.mu.Unlock()
+	if !ok {
+		return errors.New("selenium: the session does not belong to the pool")
+	}
+	err := p.recycle(s, closed)
+	if serr := p.finish(); err == nil {
+		err = serr
+	}
+	return err
+}
+
+// recycle quits, or resets and keeps, a session given back.
+func (p *Pool) recycle(s *pooledSession, closed bool) error {
+	s.uses++
+	if closed || (p.maxUses > 0 && s.uses >= p.maxUses) {
+		return s.wd.Quit()
+	}
+	if err := p.reset(s.wd); err != nil {
+		s.wd.Quit()
+		return nil
+	}
+
+	p.mu.Lock()
+	if !p.closed {
+		p.idle = append(p.idle, s)
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+	return s.wd.Quit()
+}
+
+// Discard gives back a session returned by Acquire that must not be reused,
+// e.g. because its browser crashed. The session is quit, ignoring errors,
+// and a new one is created when needed.
+func (p *Pool) Discard(wd *WebDriver) {
+	p.mu.Lock()
+	s, ok := p.inUse[wd.session()]
+	delete(p.inUse, wd.session())
+	p.mu.Unlock()
+	if !ok {
+		return
+	}
+	s.wd.Quit()
+	p.finish()
+}
+
+// finish frees the token of a session given back, or that failed to be
+// acquired. It stops the Service of the pool once the pool is closed and all
+// its sessions are quit.
+func (p *Pool) finish() error {
+	p.mu.Lock()
+	p.out--
+	stop := p.closed && p.out == 0 && p.service != nil && !p.stopped
+	p.stopped = p.stopped || stop
+	p.mu.Unlock()
+	<-p.tokens
+	if stop {
+		return p.service.Stop()
+	}
+	return nil
+}
+
+// Close quits the idle sessions and stops handing out sessions. The
+// sessions in use are quit when released. If the pool owns a Service, it is
+// stopped once all the sessions are quit.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+	idle := p.idle
+	p.idle = nil
+	stop := p.out == 0 && p.service != nil
+	p.stopped = stop
+	p.mu.Unlock()
+
+	var err error
+	for _, s := range idle {
+		if qerr := s.wd.Quit(); err == nil {
+			err = qerr
+		}
+	}
+	if stop {
+		if serr := p.service.Stop(); err == nil {
+			err = serr
+		}
+	}
+	return err
+}
+
+// ResetSession prepares a session for a new user: it closes all the windows
+// but one, deletes the cookies, clears the web storage of the current page,
+// and loads about:blank. The cookies of all the domains are deleted if the
+// browser has a DevTools endpoint, see WebDriver.CDP; otherwise only those of
+// the current page are, as WebDriver gives access to no others.
+func ResetSession(wd *WebDriver) error {
+	handles, err := wd.WindowHandles()
+	if err != nil {
+		return err
+	}
+	if len(handles) == 0 {
+		return errors.New("selenium: the session has no window")
+	}
+	if err := wd.SwitchWindow(handles[0]); err != nil {
+		return err
+	}
+	for _, h := range handles[1:] {
+		if err := wd.CloseWindow(h); err != nil {
+			return err
+		}
+	}
+	if err := clearBrowserCookies(wd); err != nil {
+		return err
+	}
+	if err := wd.DeleteAllCookies(); err != nil {
+		return err
+	}
+	// Storage is not available on every page, e.g. about:blank.
+	wd.ExecuteScript(`try { localStorage.clear(); sessionStorage.clear(); } catch (e) {}`, nil)
+	return wd.Get("about:blank")
+}
+
+// clearBrowserCookies deletes the cookies of all the domains through the
+// DevTools protocol, if the browser has an endpoint.
+func clearBrowserCookies(wd *WebDriver) error {
+	ctx := wd.Context()
+	c, err := wd.CDP(ctx)
+	if errors.Is(err, cdp.ErrNoEndpoint) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	return c.Call(ctx, "Network.clearBrowserCookies", nil, nil)
+}
@@ -0,0 +1,232 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPool(t *testing.T) {
+	s := newFakeServer(t, map[string]string{homePage: `<title>Home</title>`})
+	p, err := NewPool(2, nil, s.URL, PoolMaxUses(2))
+	if err != nil {
+		t.Fatalf("NewPool() returned error: %v", err)
+	}
+	ctx := context.Background()
+
+	wd1, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() returned error: %v", err)
+	}
+	wd2, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() returned error: %v", err)
+	}
+	if wd1.SessionID() == wd2.SessionID() {
+		t.Fatalf("Acquire() returned the same session twice")
+	}
+
+	// The pool is exhausted.
+	short, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := p.Acquire(short); err != context.DeadlineExceeded {
+		t.Fatalf("Acquire() of an exhausted pool returned %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// A released session is reset and reused.
+	id := wd1.SessionID()
+	if err := wd1.Get("http://example.com/"); err != nil {
+		t.Fatalf("Get() returned error: %v", err)
+	}
+	if err := p.Release(wd1.WithContext(ctx)); err != nil {
+		t.Fatalf("Release() returned error: %v", err)
+	}
+	wd3, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() returned error: %v", err)
+	}
+	if wd3.SessionID() != id {
+		t.Errorf("Acquire() returned session %q, want the released %q", wd3.SessionID(), id)
+	}
+	if u, err := wd3.CurrentURL(); err != nil || u != "about:blank" {
+		t.Errorf("CurrentURL() of a reused session = %q, %v, want about:blank", u, err)
+	}
+
+	// The session is recycled after its second use.
+	if err := p.Release(wd3); err != nil {
+		t.Fatalf("Release() returned error: %v", err)
+	}
+	wd4, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() returned error: %v", err)
+	}
+	if wd4.SessionID() == id {
+		t.Errorf("Acquire() returned session %q after its maximum number of uses", id)
+	}
+	if _, err := AttachSession(s.URL, id); !errors.Is(err, ErrInvalidSessionID) {
+		t.Errorf("the recycled session still exists: %v", err)
+	}
+
+	// A discarded session is quit.
+	id = wd2.SessionID()
+	p.Discard(wd2)
+	if _, err := AttachSession(s.URL, id); !errors.Is(err, ErrInvalidSessionID) {
+		t.Errorf("the discarded session still exists: %v", err)
+	}
+
+	id = wd4.SessionID()
+	if err := p.Release(wd4); err != nil {
+		t.Fatalf("Release() returned error: %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+	if _, err := p.Acquire(ctx); err != ErrPoolClosed {
+		t.Errorf("Acquire() after Close() returned %v, want %v", err, ErrPoolClosed)
+	}
+	if _, err := AttachSession(s.URL, id); !errors.Is(err, ErrInvalidSessionID) {
+		t.Errorf("the idle session still exists after Close(): %v", err)
+	}
+}
+
+func TestPoolConcurrent(t *testing.T) {
+	s := newFakeServer(t, map[string]string{homePage: `<title>Home</title>`})
+	p, err := NewPool(3, nil, s.URL)
+	if err != nil {
+		t.Fatalf("NewPool() returned error: %v", err)
+	}
+	defer p.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			wd, err := p.Acquire(context.Background())
+			if err != nil {
+				t.Errorf("Acquire() returned error: %v", err)
+				return
+			}
+			defer p.Release(wd)
+			if err := wd.Get("http://example.com/"); err != nil {
+				t.Errorf("Get() returned error: %v", err)
+			}
+			if title, err := wd.Title(); err != nil || title != "Home" {
+				t.Errorf("Title() = %q, %v, want %q", title, err, "Home")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPoolReleaseQuitSession(t *testing.T) {
+	s := newFakeServer(t, nil)
+	p, err := NewPool(1, nil, s.URL)
+	if err != nil {
+		t.Fatalf("NewPool() returned error: %v", err)
+	}
+	defer p.Close()
+
+	for _, give := range []struct {
+		name string
+		back func(*WebDriver) error
+	}{
+		{"Release", p.Release},
+		{"Discard", func(wd *WebDriver) error { p.Discard(wd); return nil }},
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		wd, err := p.Acquire(ctx)
+		cancel()
+		if err != nil {
+			t.Fatalf("Acquire() before %s() returned error: %v", give.name, err)
+		}
+		if err := wd.Quit(); err != nil {
+			t.Fatalf("Quit() returned error: %v", err)
+		}
+		if err := give.back(wd); err != nil {
+			t.Errorf("%s() of a quit session returned error: %v", give.name, err)
+		}
+	}
+	// The session given back frees its place in the pool.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := p.Acquire(ctx); err != nil {
+		t.Fatalf("Acquire() returned error: %v", err)
+	}
+}
+
+func TestPoolClose(t *testing.T) {
+	s := newFakeServer(t, nil)
+	var stopped bool
+	shutdown := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stopped = true
+	}))
+	defer shutdown.Close()
+	u, err := url.Parse(shutdown.URL)
+	if err != nil {
+		t.Fatalf("url.Parse(%q) returned error: %v", shutdown.URL, err)
+	}
+	service := &Service{url: u, shutdownURLPath: "/shutdown", cmd: exec.Command("true")}
+	if err := service.cmd.Start(); err != nil {
+		t.Skipf("cannot run true: %v", err)
+	}
+
+	p, err := NewPool(1, nil, s.URL, PoolService(service))
+	if err != nil {
+		t.Fatalf("NewPool() returned error: %v", err)
+	}
+	ctx := context.Background()
+	wd, err := p.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire() returned error: %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+
+	// Acquire does not wait for the sessions in use once the pool is closed.
+	short, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if _, err := p.Acquire(short); err != ErrPoolClosed {
+		t.Errorf("Acquire() after Close() returned %v, want %v", err, ErrPoolClosed)
+	}
+	if stopped {
+		t.Fatalf("Close() stopped the service while a session is in use")
+	}
+	if title, err := wd.Title(); err != nil {
+		t.Errorf("Title() after Close() = %q, %v", title, err)
+	}
+	id := wd.SessionID()
+	if err := p.Release(wd); err != nil {
+		t.Fatalf("Release() returned error: %v", err)
+	}
+	if _, err := AttachSession(s.URL, id); !errors.Is(err, ErrInvalidSessionID) {
+		t.Errorf("the released session still exists after Close(): %v", err)
+	}
+	if !stopped {
+		t.Errorf("the service was not stopped once the last session was released")
+	}
+}
+
+func TestClearBrowserCookies(t *testing.T) {
+	cleared := make(chan bool, 1)
+	wd := newDevToolsSession(t, func(method string, params map[string]interface{}, send func(string)) {
+		if method == "Network.clearBrowserCookies" {
+			cleared <- true
+		}
+	})
+	if err := clearBrowserCookies(wd); err != nil {
+		t.Fatalf("clearBrowserCookies() returned error: %v", err)
+	}
+	select {
+	case <-cleared:
+	case <-time.After(time.Second):
+		t.Errorf("clearBrowserCookies() did not send Network.clearBrowserCookies")
+	}
+}
@@ -0,0 +1,105 @@
+package selenium
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Orientation is the orientation of the pages printed by PrintPDF.
+type Orientation string
+
+const (
+	Portrait  Orientation = "portrait"
+	Landscape Orientation = "landscape"
+)
+
+// PageSize is the size of a printed page, in centimeters.
+type PageSize struct {
+	Width, Height float64
+}
+
+// Common page sizes.
+var (
+	PageA4     = PageSize{Width: 21, Height: 29.7}
+	PageLetter = PageSize{Width: 21.59, Height: 27.94}
+)
+
+// Margins are the margins of a printed page, in centimeters.
+type Margins struct {
+	Top, Bottom, Left, Right float64
+}
+
+// PrintOptions are the options of PrintPDF. The zero value prints all the
+// pages in portrait on US letter paper with margins of 1cm, the defaults of
+// the WebDriver specification.
+type PrintOptions struct {
+	Orientation Orientation
+	// Scale is the scale of the page, between 0.1 and 2. Zero stands for 1.
+	Scale float64
+	// Background includes the background colors and images.
+	Background bool
+	// Page is the size of the pages. Nil stands for PageLetter.
+	Page *PageSize
+	// Margins are the margins of the pages. Nil stands for 1cm on each side.
+	Margins *Margins
+	// NoShrinkToFit disables the shrinking of the content to the width of
+	// the page.
+	NoShrinkToFit bool
+	// PageRanges are the pages to print, e.g. "1", "3-5" or "7-", numbered
+	// from 1. All the pages are printed if empty.
+	PageRanges []string
+}
+
+func (o *PrintOptions) params() (map[string]interface{}, error) {
+	params := map[string]interface{}{
+		"background":  o.Background,
+		"shrinkToFit": !o.NoShrinkToFit,
+	}
+	switch o.Orientation {
+	case "":
+	case Portrait, Landscape:
+		params["orientation"] = o.Orientation
+	default:
+		return nil, fmt.Errorf("selenium: invalid orientation %q", o.Orientation)
+	}
+	if o.Scale != 0 {
+		if o.Scale < 0.1 || o.Scale > 2 {
+			return nil, fmt.Errorf("selenium: invalid scale %v, want a scale between 0.1 and 2", o.Scale)
+		}
+		params["scale"] = o.Scale
+	}
+	if o.Page != nil {
+		if o.Page.Width <= 0 || o.Page.Height <= 0 {
+			return nil, errors.New("selenium: the size of the page must be positive")
+		}
+		params["page"] = map[string]float64{"width": o.Page.Width, "height": o.Page.Height}
+	}
+	if m := o.Margins; m != nil {
+		if m.Top < 0 || m.Bottom < 0 || m.Left < 0 || m.Right < 0 {
+			return nil, errors.New("selenium: negative margin")
+		}
+		params["margin"] = map[string]float64{"top": m.Top, "bottom": m.Bottom, "left": m.Left, "right": m.Right}
+	}
+	if len(o.PageRanges) > 0 {
+		params["pageRanges"] = o.PageRanges
+	}
+	return params, nil
+}
+
+// PrintPDF prints the current page to PDF, and returns the PDF document. A
+// nil opts stands for the default options.
+func (wd *WebDriver) PrintPDF(opts *PrintOptions) ([]byte, error) {
+	if opts == nil {
+		opts = &PrintOptions{}
+	}
+	params, err := opts.params()
+	if err != nil {
+		return nil, err
+	}
+	var data string
+	if err := wd.valueCommand(print, params, &data); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(data)
+}
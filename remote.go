@@ -0,0 +1,71 @@
+package selenium
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+)
+
+// RemoteOption configures the client of a WebDriver instance.
+type RemoteOption func(*WebDriver) error
+
+// WithHTTPClient specifies the HTTP client used to talk to the WebDriver
+// server instead of the package-level HTTPClient. Use it to configure TLS,
+// proxies or timeouts per server.
+func WithHTTPClient(c *http.Client) RemoteOption {
+	return func(wd *WebDriver) error {
+		if c == nil {
+			return errors.New("nil HTTP client")
+		}
+		wd.client = c
+		return nil
+	}
+}
+
+// WithHeader adds a header that is sent with every request to the WebDriver
+// server.
+func WithHeader(key, value string) RemoteOption {
+	return func(wd *WebDriver) error {
+		if wd.header == nil {
+			wd.header = make(http.Header)
+		}
+		wd.header.Add(key, value)
+		return nil
+	}
+}
+
+// WithBasicAuth authenticates every request to the WebDriver server with the
+// provided user name and password.
+func WithBasicAuth(username, password string) RemoteOption {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return withAuthorization("Basic " + auth)
+}
+
+// WithBearerToken authenticates every request to the WebDriver server with
+// the provided bearer token.
+func WithBearerToken(token string) RemoteOption {
+	return withAuthorization("Bearer " + token)
+}
+
+func withAuthorization(value string) RemoteOption {
+	return func(wd *WebDriver) error {
+		if wd.header == nil {
+			wd.header = make(http.Header)
+		}
+		wd.header.Set("Authorization", value)
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of the requests to the WebDriver
+// server. It does not change the user agent of the browser; see
+// Entity.SetUserAgent for that.
+func WithUserAgent(ua string) RemoteOption {
+	return func(wd *WebDriver) error {
+		if wd.header == nil {
+			wd.header = make(http.Header)
+		}
+		wd.header.Set("User-Agent", ua)
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+package selenium
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type countingTransport struct {
+	n int
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.n++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestNewRemoteWithOptions(t *testing.T) {
+	var got []http.Header
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Clone())
+		w.Header().Set("Content-Type", jsonContentType)
+		if r.URL.Path == "/session" {
+			io.WriteString(w, `{"value":{"sessionId":"1","capabilities":{}}}`)
+			return
+		}
+		io.WriteString(w, `{"value":"title"}`)
+	}))
+	defer hs.Close()
+
+	transport := new(countingTransport)
+	wd, err := NewRemoteWithOptions(nil, hs.URL,
+		WithHTTPClient(&http.Client{Transport: transport}),
+		WithHeader("X-Test", "a"),
+		WithBearerToken("token"),
+		WithUserAgent("test-agent"))
+	if err != nil {
+		t.Fatalf("NewRemoteWithOptions() returned error: %v", err)
+	}
+	if _, err := wd.Title(); err != nil {
+		t.Fatalf("Title() returned error: %v", err)
+	}
+
+	if transport.n != 2 {
+		t.Errorf("custom client was used for %d requests, want 2", transport.n)
+	}
+	for i, h := range got {
+		for key, want := range map[string]string{
+			"X-Test":        "a",
+			"Authorization": "Bearer token",
+			"User-Agent":    "test-agent",
+			"Accept":        jsonContentType,
+		} {
+			if h.Get(key) != want {
+				t.Errorf("request %d: header %s = %q, want %q", i, key, h.Get(key), want)
+			}
+		}
+	}
+}
+
+func TestWithBasicAuth(t *testing.T) {
+	wd := new(WebDriver)
+	if err := WithBasicAuth("user", "pass")(wd); err != nil {
+		t.Fatalf("WithBasicAuth() returned error: %v", err)
+	}
+	cmd := wd.newCommand(getStatus, http.MethodGet, "http://localhost/status", nil)
+	r := &http.Request{Header: cmd.Header}
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != "user" || pass != "pass" {
+		t.Fatalf("BasicAuth() = %q, %q, %t, want %q, %q, true", user, pass, ok, "user", "pass")
+	}
+}
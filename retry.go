@@ -0,0 +1,143 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy controls how commands that fail with a transient error are
+// retried. A command is retried when
+//
+//   - the connection to the server could not be established, since the
+//     command was never received;
+//   - it is an idempotent GET command and the request failed in transit or the
+//     server replied with a 502, 503 or 504 status;
+//   - the server replied with one of the error codes listed in Errors.
+//
+// The delay before the n-th retry is InitialBackoff*Multiplier^(n-1), capped
+// at MaxBackoff.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a command is sent, including
+	// the first attempt. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff, if positive, caps the delay between two attempts.
+	MaxBackoff time.Duration
+	// Multiplier is the factor applied to the delay after each retry. Values
+	// below 1 default to 2.
+	Multiplier float64
+	// Errors lists the error codes, such as ErrStaleElementReference, for
+	// which any command is retried.
+	Errors []ErrorCode
+	// OnRetry, if set, is called before each retry with the name of the
+	// command, the number of the failed attempt, the delay before the next one
+	// and the error.
+	OnRetry func(command string, attempt int, wait time.Duration, err error)
+}
+
+// DefaultRetryPolicy retries commands up to 3 times on connection failures
+// and overloaded servers.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+}
+
+// WithRetryPolicy configures the retry policy of the WebDriver.
+func WithRetryPolicy(p RetryPolicy) RemoteOption {
+	return func(wd *WebDriver) error {
+		if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+			return fmt.Errorf("negative retry backoff")
+		}
+		wd.retry = &p
+		return nil
+	}
+}
+
+// statusError is returned when the server replies with an HTTP error status
+// and without a WebDriver error payload.
+type statusError struct {
+	code   int
+	status string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("bad server reply status: %s", e.status)
+}
+
+func (p *RetryPolicy) shouldRetry(ctx context.Context, method string, attempt int, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return false
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		for _, code := range p.Errors {
+			if e.Code() == code {
+				return true
+			}
+		}
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+	if method != http.MethodGet {
+		return false
+	}
+
+	var se *statusError
+	if errors.As(err, &se) {
+		switch se.code {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	// Any other error happened while sending the request or reading the
+	// response, before a reply could be decoded.
+	return true
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	m := p.Multiplier
+	if m < 1 {
+		m = 2
+	}
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= m
+		if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	return time.Duration(d)
+}
+
+// wait sleeps before the attempt following the failed one. It returns a
+// non-nil error if ctx is done first.
+func (p *RetryPolicy) wait(ctx context.Context, command string, attempt int, err error) error {
+	d := p.backoff(attempt)
+	if p.OnRetry != nil {
+		p.OnRetry(command, attempt, d, err)
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,155 @@
+package selenium
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		desc     string
+		policy   RetryPolicy
+		failures int
+		reply    func(w http.ResponseWriter)
+		post     bool
+		wantErr  bool
+		wantHits int
+	}{
+		{
+			desc:     "GET retried on 503",
+			policy:   RetryPolicy{MaxAttempts: 3},
+			failures: 2,
+			reply: func(w http.ResponseWriter) {
+				http.Error(w, "busy", http.StatusServiceUnavailable)
+			},
+			wantHits: 3,
+		},
+		{
+			desc:     "GET gives up after MaxAttempts",
+			policy:   RetryPolicy{MaxAttempts: 2},
+			failures: 2,
+			reply: func(w http.ResponseWriter) {
+				http.Error(w, "busy", http.StatusServiceUnavailable)
+			},
+			wantErr:  true,
+			wantHits: 2,
+		},
+		{
+			desc:     "POST not retried on 503",
+			policy:   RetryPolicy{MaxAttempts: 3},
+			failures: 1,
+			reply: func(w http.ResponseWriter) {
+				http.Error(w, "busy", http.StatusServiceUnavailable)
+			},
+			post:     true,
+			wantErr:  true,
+			wantHits: 1,
+		},
+		{
+			desc:     "POST retried on a listed error code",
+			policy:   RetryPolicy{MaxAttempts: 3, Errors: []ErrorCode{ErrStaleElementReference}},
+			failures: 1,
+			reply: func(w http.ResponseWriter) {
+				w.Header().Set("Content-Type", jsonContentType)
+				w.WriteHeader(http.StatusNotFound)
+				io.WriteString(w, `{"value":{"error":"stale element reference","message":""}}`)
+			},
+			post:     true,
+			wantHits: 2,
+		},
+		{
+			desc:     "GET not retried on an unlisted error code",
+			policy:   RetryPolicy{MaxAttempts: 3},
+			failures: 1,
+			reply: func(w http.ResponseWriter) {
+				w.Header().Set("Content-Type", jsonContentType)
+				w.WriteHeader(http.StatusNotFound)
+				io.WriteString(w, `{"value":{"error":"no such element","message":""}}`)
+			},
+			wantErr:  true,
+			wantHits: 1,
+		},
+	}
+	for _, test := range tests {
+		hits := 0
+		hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits++
+			if hits <= test.failures {
+				test.reply(w)
+				return
+			}
+			w.Header().Set("Content-Type", jsonContentType)
+			io.WriteString(w, `{"value":null}`)
+		}))
+
+		retries := 0
+		p := test.policy
+		p.OnRetry = func(string, int, time.Duration, error) { retries++ }
+		wd := &WebDriver{id: "1", urlPrefix: hs.URL}
+		if err := WithRetryPolicy(p)(wd); err != nil {
+			t.Fatalf("%s: WithRetryPolicy() returned error: %v", test.desc, err)
+		}
+
+		var err error
+		if test.post {
+			err = wd.Refresh()
+		} else {
+			_, err = wd.WindowHandles()
+		}
+		hs.Close()
+
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("%s: returned error %v, want error: %t", test.desc, err, test.wantErr)
+		}
+		if hits != test.wantHits {
+			t.Errorf("%s: server received %d requests, want %d", test.desc, hits, test.wantHits)
+		}
+		if retries != test.wantHits-1 {
+			t.Errorf("%s: OnRetry called %d times, want %d", test.desc, retries, test.wantHits-1)
+		}
+	}
+}
+
+func TestRetryPolicyConnectionRefused(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() returned error: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	retries := 0
+	wd := &WebDriver{id: "1", urlPrefix: "http://" + addr}
+	WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		OnRetry:     func(string, int, time.Duration, error) { retries++ },
+	})(wd)
+	if err := wd.Refresh(); err == nil {
+		t.Fatalf("Refresh() did not return an error")
+	}
+	if retries != 2 {
+		t.Fatalf("OnRetry called %d times, want 2", retries)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 3}
+	for attempt, want := range []time.Duration{
+		1: 100 * time.Millisecond,
+		2: 300 * time.Millisecond,
+		3: 900 * time.Millisecond,
+		4: time.Second,
+		5: time.Second,
+	} {
+		if attempt == 0 {
+			continue
+		}
+		if got := p.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// UnexpectedTagNameError is returned by NewSelect if the element is not a
+// select element.
+type UnexpectedTagNameError struct {
+	Expected, Actual string
+}
+
+func (e *UnexpectedTagNameError) Error() string {
+	return fmt.Sprintf("selenium: element should have been %q but was %q", e.Expected, e.Actual)
+}
+
+// NoSuchOptionError is returned by the methods of Select if no option
+// matches. It matches ErrNoSuchElement.
+type NoSuchOptionError struct {
+	// By is "value", "text" or "index".
+	By    string
+	Value string
+}
+
+func (e *NoSuchOptionError) Error() string {
+	return fmt.Sprintf("selenium: no option with %s %q", e.By, e.Value)
+}
+
+// Is makes errors.Is(err, ErrNoSuchElement) true.
+func (e *NoSuchOptionError) Is(target error) bool {
+	return target == ErrNoSuchElement
+}
+
+// Select is a select element, e.g. a dropdown list.
+//
+//	elem, err := wd.FindElement(selenium.ByName, "country")
+//	...
+//	s, err := selenium.NewSelect(elem)
+//	...
+//	err = s.SelectByVisibleText("France")
+type Select struct {
+	elem     *WebElement
+	multiple bool
+}
+
+// NewSelect returns the Select of elem. It returns an UnexpectedTagNameError
+// if elem is not a select element.
+func NewSelect(elem *WebElement) (*Select, error) {
+	tag, err := elem.TagName()
+	if err != nil {
+		return nil, err
+	}
+	if tag = strings.ToLower(tag); tag != "select" {
+		return nil, &UnexpectedTagNameError{Expected: "select", Actual: tag}
+	}
+	_, ok, err := elem.attribute("multiple")
+	if err != nil {
+		return nil, err
+	}
+	return &Select{elem: elem, multiple: ok}, nil
+}
+
+// Element returns the select element.
+func (s *Select) Element() *WebElement {
+	return s.elem
+}
+
+// IsMultiple reports whether several options can be selected at once.
+func (s *Select) IsMultiple() bool {
+	return s.multiple
+}
+
+// Options returns the options of the select element.
+func (s *Select) Options() ([]*WebElement, error) {
+	return s.elem.FindElements(ByTagName, "option")
+}
+
+// SelectedOptions returns the selected options of the select element.
+func (s *Select) SelectedOptions() ([]*WebElement, error) {
+	options, err := s.Options()
+	if err != nil {
+		return nil, err
+	}
+	var selected []*WebElement
+	for _, o := range options {
+		ok, err := o.IsSelected()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			selected = append(selected, o)
+		}
+	}
+	return selected, nil
+}
+
+// SelectByValue selects the options whose value is value: all of them if
+// several options can be selected, the first one otherwise.
+func (s *Select) SelectByValue(value string) error {
+	options, err := s.elem.FindElements(ByCSSSelector, "option[value="+cssString(value)+"]")
+	if err != nil {
+		return err
+	}
+	return s.selectOptions(options, &NoSuchOptionError{By: "value", Value: value})
+}
+
+// SelectByVisibleText selects the options displayed as text, ignoring
+// extra whitespace: all of them if several options can be selected, the
+// first one otherwise.
+func (s *Select) SelectByVisibleText(text string) error {
+	options, err := s.Options()
+	if err != nil {
+		return err
+	}
+	text = normalizeSpace(text)
+	var matching []*WebElement
+	for _, o := range options {
+		t, err := o.Text()
+		if err != nil {
+			return err
+		}
+		if normalizeSpace(t) == text {
+			matching = append(matching, o)
+		}
+	}
+	return s.selectOptions(matching, &NoSuchOptionError{By: "text", Value: text})
+}
+
+// SelectByIndex selects the option at index i, counted from 0.
+func (s *Select) SelectByIndex(i int) error {
+	options, err := s.Options()
+	if err != nil {
+		return err
+	}
+	if i < 0 || i >= len(options) {
+		return &NoSuchOptionError{By: "index", Value: fmt.Sprint(i)}
+	}
+	return s.selectOptions(options[i:i+1], nil)
+}
+
+// DeselectAll clears the selection. It is only supported if several options
+// can be selected.
+func (s *Select) DeselectAll() error {
+	if !s.multiple {
+		return errors.New("selenium: only the options of a multiple select element can be deselected")
+	}
+	options, err := s.SelectedOptions()
+	if err != nil {
+		return err
+	}
+	for _, o := range options {
+		if err := o.Click(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// selectOptions selects options, or only the first one unless several
+// options can be selected. It returns notFound if there are no options.
+func (s *Select) selectOptions(options []*WebElement, notFound error) error {
+	if len(options) == 0 {
+		return notFound
+	}
+	if !s.multiple {
+		options = options[:1]
+	}
+	for _, o := range options {
+		if err := selectOption(o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func selectOption(o *WebElement) error {
+	enabled, err := o.IsEnabled()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return errors.New("selenium: a disabled option cannot be selected")
+	}
+	selected, err := o.IsSelected()
+	if err != nil || selected {
+		return err
+	}
+	return o.Click()
+}
+
+// attribute returns the value of the attribute name of elem, and whether
+// elem has it.
+func (elem *WebElement) attribute(name string) (string, bool, error) {
+	var v *string
+	if err := elem.valueCommand(getElementAttribute, nil, &v, name); err != nil {
+		return "", false, err
+	}
+	if v == nil {
+		return "", false, nil
+	}
+	return *v, true, nil
+}
+
+// cssString quotes s as a CSS string.
+func cssString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\a `).Replace(s) + `"`
+}
@@ -0,0 +1,120 @@
+package selenium
+
+import (
+	"errors"
+	"testing"
+)
+
+const selectPage = `<title>Order</title>
+<form>
+  <select name="size">
+    <option value="s">Small</option>
+    <option value="m" selected>Medium</option>
+    <option value="l">  Large
+      size</option>
+    <option value="xl" disabled>Extra large</option>
+  </select>
+  <select name="toppings" multiple>
+    <option value="cheese" selected>Cheese</option>
+    <option value="ham">Ham</option>
+    <option value="olives">Olives</option>
+  </select>
+  <input name="note">
+</form>`
+
+func newSelect(t *testing.T, wd *WebDriver, name string) *Select {
+	t.Helper()
+	elem, err := wd.FindElement(ByCSSSelector, "select[name="+name+"]")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	s, err := NewSelect(elem)
+	if err != nil {
+		t.Fatalf("NewSelect() returned error: %v", err)
+	}
+	return s
+}
+
+func selectedValues(t *testing.T, s *Select) []string {
+	t.Helper()
+	options, err := s.SelectedOptions()
+	if err != nil {
+		t.Fatalf("SelectedOptions() returned error: %v", err)
+	}
+	var values []string
+	for _, o := range options {
+		v, err := o.GetAttribute("value")
+		if err != nil {
+			t.Fatalf("GetAttribute() returned error: %v", err)
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func TestSelect(t *testing.T) {
+	wd, _ := newFakeSession(t, nil, map[string]string{homePage: selectPage})
+
+	size := newSelect(t, wd, "size")
+	if size.IsMultiple() {
+		t.Errorf("IsMultiple() = true for a single select")
+	}
+	if options, err := size.Options(); err != nil || len(options) != 4 {
+		t.Errorf("Options() returned %d options, %v, want 4", len(options), err)
+	}
+	for _, tc := range []struct {
+		do   func() error
+		want string
+	}{
+		{func() error { return size.SelectByValue("s") }, "s"},
+		{func() error { return size.SelectByVisibleText("Large size") }, "l"},
+		{func() error { return size.SelectByIndex(1) }, "m"},
+	} {
+		if err := tc.do(); err != nil {
+			t.Fatalf("selecting %q returned error: %v", tc.want, err)
+		}
+		if got := selectedValues(t, size); len(got) != 1 || got[0] != tc.want {
+			t.Errorf("the selected options are %v, want [%s]", got, tc.want)
+		}
+	}
+
+	var notFound *NoSuchOptionError
+	if err := size.SelectByValue("xxl"); !errors.As(err, &notFound) || !errors.Is(err, ErrNoSuchElement) {
+		t.Errorf("SelectByValue() of a missing option returned %v, want a NoSuchOptionError", err)
+	}
+	if err := size.SelectByIndex(4); !errors.As(err, &notFound) {
+		t.Errorf("SelectByIndex() out of range returned %v, want a NoSuchOptionError", err)
+	}
+	if err := size.SelectByValue("xl"); err == nil {
+		t.Errorf("SelectByValue() of a disabled option did not return an error")
+	}
+	if err := size.DeselectAll(); err == nil {
+		t.Errorf("DeselectAll() of a single select did not return an error")
+	}
+
+	toppings := newSelect(t, wd, "toppings")
+	if !toppings.IsMultiple() {
+		t.Errorf("IsMultiple() = false for a multiple select")
+	}
+	if err := toppings.SelectByVisibleText("Olives"); err != nil {
+		t.Fatalf("SelectByVisibleText() returned error: %v", err)
+	}
+	if got := selectedValues(t, toppings); len(got) != 2 || got[0] != "cheese" || got[1] != "olives" {
+		t.Errorf("the selected options are %v, want [cheese olives]", got)
+	}
+	if err := toppings.DeselectAll(); err != nil {
+		t.Fatalf("DeselectAll() returned error: %v", err)
+	}
+	if got := selectedValues(t, toppings); len(got) != 0 {
+		t.Errorf("the selected options after DeselectAll() are %v, want none", got)
+	}
+
+	note, err := wd.FindElement(ByName, "note")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	var tagErr *UnexpectedTagNameError
+	if _, err := NewSelect(note); !errors.As(err, &tagErr) || tagErr.Actual != "input" {
+		t.Errorf("NewSelect() of an input returned %v, want an UnexpectedTagNameError", err)
+	}
+}
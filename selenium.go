@@ -1,6 +1,8 @@
 package selenium
 
 import (
+	"time"
+
 	"github.com/injoyai/selenium/chrome"
 	"github.com/injoyai/selenium/firefox"
 	"github.com/injoyai/selenium/log"
@@ -108,6 +110,12 @@ func (c Capabilities) AddProxy(p Proxy) {
 	c["proxy"] = p
 }
 
+// AddBiDi requests a WebDriver BiDi connection for the session. The remote
+// end returns its URL in the "webSocketUrl" capability; see WebDriver.BiDi.
+func (c Capabilities) AddBiDi() {
+	c["webSocketUrl"] = true
+}
+
 // AddLogging adds logging configuration to the capabilities.
 func (c Capabilities) AddLogging(l log.Capabilities) {
 	c[log.CapabilitiesKey] = l
@@ -201,6 +209,31 @@ type Size struct {
 	Width, Height int
 }
 
+// Rect is the position and the size of a window.
+type Rect struct {
+	X, Y          int
+	Width, Height int
+}
+
+// WindowType is the type of a window created by NewWindow.
+type WindowType string
+
+const (
+	TabWindow     WindowType = "tab"
+	BrowserWindow WindowType = "window"
+)
+
+// Timeouts are the timeouts of a session.
+type Timeouts struct {
+	// Implicit is the time to wait for elements to be found.
+	Implicit time.Duration
+	// PageLoad is the time to wait for pages to load.
+	PageLoad time.Duration
+	// Script is the time scripts may run for. It is negative if scripts are
+	// never interrupted.
+	Script time.Duration
+}
+
 // Cookie represents an HTTP cookie.
 type Cookie struct {
 	Name     string   `json:"name"`
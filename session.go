@@ -0,0 +1,145 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// AttachSession returns a WebDriver for the existing session id of the
+// server at urlPrefix, e.g. a session created by another process that has
+// since exited. Unlike NewRemote, it does not create a session.
+//
+// The W3C mode and the browser version are restored from the capabilities of
+// the session, which the server is asked for.
+func AttachSession(urlPrefix, id string, opts ...RemoteOption) (*WebDriver, error) {
+	return AttachSessionContext(context.Background(), urlPrefix, id, opts...)
+}
+
+// AttachSessionContext is like AttachSession, but ctx bounds the requests
+// made to the server while attaching. The context is not retained by the
+// returned WebDriver.
+func AttachSessionContext(ctx context.Context, urlPrefix, id string, opts ...RemoteOption) (*WebDriver, error) {
+	if urlPrefix == "" {
+		urlPrefix = DefaultURLPrefix
+	}
+	if _, err := url.Parse(urlPrefix); err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, errors.New("selenium: empty session ID")
+	}
+	wd := &WebDriver{id: id, urlPrefix: strings.TrimSuffix(urlPrefix, "/"), ctx: ctx}
+	wd.state.Store(new(sessionState))
+	for _, opt := range opts {
+		if err := opt(wd); err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := wd.execute(getCapabilities, nil)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSessionID) {
+			return nil, err
+		}
+		// W3C servers need not implement this command. Check that the session
+		// exists with a command that they do implement.
+		if _, err := wd.CurrentWindowHandle(); err != nil {
+			return nil, err
+		}
+		wd.w3cCompatible = true
+		wd.ctx = nil
+		return wd, nil
+	}
+
+	var reply map[string]json.RawMessage
+	if err := json.Unmarshal(response, &reply); err != nil {
+		return nil, err
+	}
+	// The replies of the legacy protocol always hold a status.
+	_, legacy := reply["status"]
+	wd.w3cCompatible = !legacy
+	wd.restoreCapabilities(sessionCapabilities(reply["value"]))
+	wd.ctx = nil
+	return wd, nil
+}
+
+// restoreCapabilities sets the capabilities of the session, and the browser
+// name and version they hold.
+func (wd *WebDriver) restoreCapabilities(caps Capabilities) {
+	wd.sessionCapabilities = caps
+	if b, ok := caps["browserName"].(string); ok {
+		wd.browser = b
+	}
+	for _, k := range []string{"version", "browserVersion"} {
+		s, _ := caps[k].(string)
+		if s == "" {
+			continue
+		}
+		if v, err := parseVersion(s); err == nil {
+			wd.browserVersion = v
+		}
+	}
+}
+
+// SessionDescriptor holds what is needed to attach to an existing session.
+type SessionDescriptor struct {
+	URLPrefix string `json:"urlPrefix"`
+	SessionID string `json:"sessionId"`
+	W3C       bool   `json:"w3c"`
+	// Capabilities are the capabilities of the session, if known. They hold
+	// the endpoints used by WebDriver.CDP and WebDriver.BiDi.
+	Capabilities Capabilities `json:"capabilities,omitempty"`
+}
+
+// Descriptor returns the descriptor of the session of wd.
+func (wd *WebDriver) Descriptor() SessionDescriptor {
+	return SessionDescriptor{
+		URLPrefix:    wd.urlPrefix,
+		SessionID:    wd.SessionID(),
+		W3C:          wd.w3cCompatible,
+		Capabilities: wd.sessionCapabilities,
+	}
+}
+
+// SaveSession writes the descriptor of the session of wd to the file at
+// path, as JSON, to be read by LoadSession.
+func (wd *WebDriver) SaveSession(path string) error {
+	data, err := json.MarshalIndent(wd.Descriptor(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// LoadSession attaches to the session whose descriptor was written to the
+// file at path by SaveSession. See AttachSession.
+func LoadSession(path string, opts ...RemoteOption) (*WebDriver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var d SessionDescriptor
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, err
+	}
+	return AttachDescriptor(d, opts...)
+}
+
+// AttachDescriptor attaches to the session of d. See AttachSession. The
+// capabilities saved in d are used if the server does not return those of the
+// session.
+func AttachDescriptor(d SessionDescriptor, opts ...RemoteOption) (*WebDriver, error) {
+	wd, err := AttachSession(d.URLPrefix, d.SessionID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if wd.sessionCapabilities == nil && d.Capabilities != nil {
+		wd.restoreCapabilities(d.Capabilities)
+		wd.w3cCompatible = d.W3C
+	}
+	return wd, nil
+}
@@ -0,0 +1,70 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveAndLoadSession(t *testing.T) {
+	wd, _ := newFakeSession(t, nil, map[string]string{homePage: `<title>Home</title>`})
+
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := wd.SaveSession(path); err != nil {
+		t.Fatalf("SaveSession() returned error: %v", err)
+	}
+
+	attached, err := LoadSession(path)
+	if err != nil {
+		t.Fatalf("LoadSession() returned error: %v", err)
+	}
+	if attached.SessionID() != wd.SessionID() {
+		t.Errorf("SessionID() = %q, want %q", attached.SessionID(), wd.SessionID())
+	}
+	if !attached.w3cCompatible {
+		t.Errorf("the attached session is not in W3C mode")
+	}
+	if attached.browser != "fakedriver" {
+		t.Errorf("the attached session has browser %q, want %q", attached.browser, "fakedriver")
+	}
+	if title, err := attached.Title(); err != nil || title != "Home" {
+		t.Errorf("Title() = %q, %v, want %q", title, err, "Home")
+	}
+}
+
+func TestAttachSessionInvalid(t *testing.T) {
+	s := newFakeServer(t, nil)
+	_, err := AttachSession(s.URL, "no-such-session")
+	if !errors.Is(err, ErrInvalidSessionID) {
+		t.Errorf("AttachSession() returned %v, want %v", err, ErrInvalidSessionID)
+	}
+}
+
+func TestAttachSessionLegacy(t *testing.T) {
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/session/1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", jsonContentType)
+		fmt.Fprint(w, `{"status":0,"sessionId":"1","value":{"browserName":"chrome","version":"61.0.3116.0"}}`)
+	}))
+	defer hs.Close()
+
+	wd, err := AttachSession(hs.URL, "1")
+	if err != nil {
+		t.Fatalf("AttachSession() returned error: %v", err)
+	}
+	if wd.w3cCompatible {
+		t.Errorf("the attached session is in W3C mode, want legacy")
+	}
+	if got, want := wd.browserVersion.String(), "61.0.3116"; got != want {
+		t.Errorf("browserVersion = %s, want %s", got, want)
+	}
+	if got := wd.Descriptor().Capabilities["browserName"]; got != "chrome" {
+		t.Errorf("Descriptor().Capabilities[browserName] = %v, want chrome", got)
+	}
+}
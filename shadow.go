@@ -0,0 +1,141 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// shadowRootIdentifier is the key, defined by the W3C specification, of the
+// map that holds the reference of a shadow root.
+const shadowRootIdentifier = "shadow-6066-11e4-a52e-4f735466cecf"
+
+// ShadowRoot is the shadow root of an element, whose elements are not found
+// from the document or from the host element.
+type ShadowRoot struct {
+	parent *WebDriver
+	id     string
+}
+
+// ShadowRoot returns the shadow root of elem. It returns ErrNoSuchShadowRoot
+// if elem has none.
+func (elem *WebElement) ShadowRoot() (*ShadowRoot, error) {
+	var response []byte
+	err := elem.do(func(id string) (err error) {
+		response, err = elem.parent.execute(getShadowRoot, nil, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return elem.parent.DecodeShadowRoot(response)
+}
+
+// DecodeShadowRoot decodes the shadow root reference in the value of a reply,
+// e.g. of a script that returns element.shadowRoot.
+func (wd *WebDriver) DecodeShadowRoot(data []byte) (*ShadowRoot, error) {
+	reply := new(struct{ Value map[string]string })
+	if err := json.Unmarshal(data, reply); err != nil {
+		return nil, err
+	}
+	id := reply.Value[shadowRootIdentifier]
+	if id == "" {
+		return nil, fmt.Errorf("invalid shadow root returned: %+v", reply)
+	}
+	return &ShadowRoot{parent: wd, id: id}, nil
+}
+
+// Context returns the context that bounds the commands issued for this
+// shadow root, which is the context of the WebDriver it was found through.
+func (s *ShadowRoot) Context() context.Context {
+	return s.parent.Context()
+}
+
+// WithContext returns a copy of s whose commands are bound to ctx.
+func (s *ShadowRoot) WithContext(ctx context.Context) *ShadowRoot {
+	x := *s
+	x.parent = s.parent.WithContext(ctx)
+	return &x
+}
+
+// FindElement finds an element of the shadow root. Browsers do not support
+// XPath in shadow roots.
+func (s *ShadowRoot) FindElement(by, value string) (*WebElement, error) {
+	response, err := s.parent.find(findElementFromShadow, s.id, by, value)
+	if err != nil {
+		return nil, err
+	}
+	return s.parent.DecodeElement(response)
+}
+
+// FindElements finds the elements of the shadow root.
+func (s *ShadowRoot) FindElements(by, value string) ([]*WebElement, error) {
+	response, err := s.parent.find(findElementsFromShadow, s.id, by, value)
+	if err != nil {
+		return nil, err
+	}
+	return s.parent.DecodeElements(response)
+}
+
+// MarshalJSON encodes s as a reference, to pass it to scripts.
+func (s *ShadowRoot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{shadowRootIdentifier: s.id})
+}
+
+// PiercingSeparator separates the CSS selectors of a piercing selector.
+const PiercingSeparator = ">>>"
+
+// FindElementsPiercing finds elements inside nested shadow roots. The
+// selector is a list of CSS selectors separated by ">>>": the first one is
+// matched in the document, and each following one in the shadow roots of
+// the elements matched by the previous one. Elements without a shadow root
+// are skipped. For example, the items of the list of a web component:
+//
+//	items, err := wd.FindElementsPiercing("my-app >>> my-list >>> li.item")
+func (wd *WebDriver) FindElementsPiercing(selector string) ([]*WebElement, error) {
+	parts := strings.Split(selector, PiercingSeparator)
+	for i, p := range parts {
+		if parts[i] = strings.TrimSpace(p); parts[i] == "" {
+			return nil, fmt.Errorf("invalid piercing selector %q", selector)
+		}
+	}
+	elems, err := wd.FindElements(ByCSSSelector, parts[0])
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range parts[1:] {
+		var next []*WebElement
+		for _, host := range elems {
+			root, err := host.ShadowRoot()
+			if errors.Is(err, ErrNoSuchShadowRoot) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			found, err := root.FindElements(ByCSSSelector, p)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, found...)
+		}
+		elems = next
+	}
+	return elems, nil
+}
+
+// FindElementPiercing returns the first element matched by a piercing
+// selector. See FindElementsPiercing. It returns an error matching
+// ErrNoSuchElement if no element matches.
+func (wd *WebDriver) FindElementPiercing(selector string) (*WebElement, error) {
+	elems, err := wd.FindElementsPiercing(selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(elems) == 0 {
+		return nil, &Error{Err: string(ErrNoSuchElement), Message: fmt.Sprintf("no element matches %q", selector)}
+	}
+	return elems[0], nil
+}
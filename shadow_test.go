@@ -0,0 +1,94 @@
+package selenium
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const shadowPage = `<title>Components</title>
+<my-app>
+  <template shadowrootmode="open">
+    <my-list id="first">
+      <template shadowrootmode="open">
+        <li class="item">a</li><li class="item">b</li>
+      </template>
+    </my-list>
+    <my-list id="second">
+      <template shadowrootmode="open"><li class="item">c</li></template>
+    </my-list>
+    <my-list id="empty"></my-list>
+  </template>
+</my-app>`
+
+func TestShadowRoot(t *testing.T) {
+	wd, _ := newFakeSession(t, nil, map[string]string{homePage: shadowPage})
+
+	if _, err := wd.FindElement(ByCSSSelector, "my-list"); !errors.Is(err, ErrNoSuchElement) {
+		t.Errorf("FindElement() found an element of a shadow root from the document: %v", err)
+	}
+	app, err := wd.FindElement(ByTagName, "my-app")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	root, err := app.ShadowRoot()
+	if err != nil {
+		t.Fatalf("ShadowRoot() returned error: %v", err)
+	}
+	lists, err := root.FindElements(ByCSSSelector, "my-list")
+	if err != nil || len(lists) != 3 {
+		t.Fatalf("FindElements() returned %d elements, %v, want 3", len(lists), err)
+	}
+	list, err := root.FindElement(ByID, "second")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	inner, err := list.ShadowRoot()
+	if err != nil {
+		t.Fatalf("ShadowRoot() returned error: %v", err)
+	}
+	item, err := inner.FindElement(ByClassName, "item")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	if text, err := item.Text(); err != nil || text != "c" {
+		t.Errorf("Text() = %q, %v, want %q", text, err, "c")
+	}
+	if _, err := lists[2].ShadowRoot(); !errors.Is(err, ErrNoSuchShadowRoot) {
+		t.Errorf("ShadowRoot() of an element without one returned %v, want %v", err, ErrNoSuchShadowRoot)
+	}
+
+	if err := wd.Refresh(); err != nil {
+		t.Fatalf("Refresh() returned error: %v", err)
+	}
+	if _, err := root.FindElement(ByTagName, "my-list"); !errors.Is(err, ErrDetachedShadowRoot) {
+		t.Errorf("FindElement() in a detached shadow root returned %v, want %v", err, ErrDetachedShadowRoot)
+	}
+}
+
+func TestFindElementsPiercing(t *testing.T) {
+	wd, _ := newFakeSession(t, nil, map[string]string{homePage: shadowPage})
+
+	items, err := wd.FindElementsPiercing("my-app >>> my-list >>> li.item")
+	if err != nil {
+		t.Fatalf("FindElementsPiercing() returned error: %v", err)
+	}
+	var texts []string
+	for _, item := range items {
+		text, err := item.Text()
+		if err != nil {
+			t.Fatalf("Text() returned error: %v", err)
+		}
+		texts = append(texts, text)
+	}
+	if got, want := fmt.Sprint(texts), "[a b c]"; got != want {
+		t.Errorf("FindElementsPiercing() found %s, want %s", got, want)
+	}
+
+	if _, err := wd.FindElementPiercing("my-app >>> #empty >>> li"); !errors.Is(err, ErrNoSuchElement) {
+		t.Errorf("FindElementPiercing() returned %v, want %v", err, ErrNoSuchElement)
+	}
+	if _, err := wd.FindElementsPiercing("my-app >>> "); err == nil {
+		t.Errorf("FindElementsPiercing() of an invalid selector did not return an error")
+	}
+}
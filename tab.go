@@ -0,0 +1,67 @@
+package selenium
+
+// Tab is a window of a session whose commands run in that window, whatever
+// the current window of the session is: the window is switched to before
+// each command, if needed. The commands of the tabs of a session, and the
+// window commands of its WebDriver, are serialized, so that the tabs can be
+// used concurrently:
+//
+//	a, err := wd.NewTab("https://example.com/a")
+//	...
+//	b, err := wd.NewTab("https://example.com/b")
+//	...
+//	go a.FindElement(selenium.ByID, "x")
+//	go b.FindElement(selenium.ByID, "y")
+//
+// Switching windows resets the current frame: the frame a tab switched to is
+// lost once another tab, or the WebDriver, has run a command in another
+// window. The commands of the WebDriver itself run in the current window of
+// the session, which tabs change.
+type Tab struct {
+	*WebDriver
+}
+
+// NewTab opens a new tab, and loads url in it unless url is empty.
+func (wd *WebDriver) NewTab(url string) (*Tab, error) {
+	handle, err := wd.NewWindow(TabWindow)
+	if err != nil {
+		return nil, err
+	}
+	t := wd.tab(handle)
+	if url != "" {
+		if err := t.Get(url); err != nil {
+			t.Close()
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Tabs returns the tabs of the windows of the session.
+func (wd *WebDriver) Tabs() ([]*Tab, error) {
+	handles, err := wd.WindowHandles()
+	if err != nil {
+		return nil, err
+	}
+	tabs := make([]*Tab, len(handles))
+	for i, h := range handles {
+		tabs[i] = wd.tab(h)
+	}
+	return tabs, nil
+}
+
+func (wd *WebDriver) tab(handle string) *Tab {
+	x := wd.Copy()
+	x.window = handle
+	return &Tab{WebDriver: x}
+}
+
+// Handle returns the handle of the window of t.
+func (t *Tab) Handle() string {
+	return t.window
+}
+
+// Close closes the window of t.
+func (t *Tab) Close() error {
+	return t.WebDriver.Close()
+}
@@ -0,0 +1,67 @@
+package selenium
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestTab(t *testing.T) {
+	wd, _ := newFakeSession(t, nil, map[string]string{
+		"http://example.com/a": `<title>A</title>`,
+		"http://example.com/b": `<title>B</title>`,
+	})
+
+	a, err := wd.NewTab("http://example.com/a")
+	if err != nil {
+		t.Fatalf("NewTab() returned error: %v", err)
+	}
+	b, err := wd.NewTab("http://example.com/b")
+	if err != nil {
+		t.Fatalf("NewTab() returned error: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, tc := range []struct {
+			tab   *Tab
+			title string
+		}{{a, "A"}, {b, "B"}} {
+			wg.Add(1)
+			go func(tab *Tab, want string) {
+				defer wg.Done()
+				if title, err := tab.Title(); err != nil || title != want {
+					t.Errorf("Title() of tab %s = %q, %v, want %q", tab.Handle(), title, err, want)
+				}
+			}(tc.tab, tc.title)
+		}
+		// Switching windows through the WebDriver does not affect the tabs.
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := wd.SwitchWindow(a.Handle()); err != nil {
+				t.Errorf("SwitchWindow() returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	tabs, err := wd.Tabs()
+	if err != nil {
+		t.Fatalf("Tabs() returned error: %v", err)
+	}
+	if len(tabs) != 3 {
+		t.Fatalf("Tabs() returned %d tabs, want 3", len(tabs))
+	}
+	if err := a.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+	if _, err := a.Title(); err == nil {
+		t.Errorf("Title() of a closed tab did not return an error")
+	}
+	if title, err := b.Title(); err != nil || title != "B" {
+		t.Errorf("Title() = %q, %v, want %q", title, err, "B")
+	}
+	if tabs, err := wd.Tabs(); err != nil || len(tabs) != 2 {
+		t.Errorf("Tabs() after Close() returned %d tabs, %v, want 2", len(tabs), err)
+	}
+}
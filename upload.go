@@ -0,0 +1,64 @@
+package selenium
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/injoyai/selenium/internal/zip"
+)
+
+// UploadFile sets the files of elem, a file input, to the local files at
+// paths.
+//
+// SendKeys only works with the paths of files on the machine of the browser,
+// which may be another machine or a container, e.g. with a Selenium Grid.
+// UploadFile first copies the files to the server, with the file upload
+// command of Selenium, and sends the paths of the copies. If the server does
+// not know the command, as is the case of the drivers that run on this
+// machine, such as ChromeDriver, it sends the local paths.
+func (elem *WebElement) UploadFile(paths ...string) error {
+	if len(paths) == 0 {
+		return errors.New("selenium: no file to upload")
+	}
+	files := make([]string, len(paths))
+	for i, p := range paths {
+		path, err := filepath.Abs(p)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+		files[i] = path
+	}
+	copies := make([]string, len(files))
+	for i, path := range files {
+		remotePath, err := elem.parent.uploadFile(path)
+		if i == 0 && unsupported(err) {
+			return elem.SendKeys(strings.Join(files, "\n"))
+		}
+		if err != nil {
+			return err
+		}
+		copies[i] = remotePath
+	}
+	return elem.SendKeys(strings.Join(copies, "\n"))
+}
+
+// uploadFile copies the file at path to the server and returns the path of
+// the copy.
+func (wd *WebDriver) uploadFile(path string) (string, error) {
+	buf, err := zip.NewFile(path)
+	if err != nil {
+		return "", err
+	}
+	params := map[string]string{"file": base64.StdEncoding.EncodeToString(buf.Bytes())}
+	var remotePath string
+	if err := wd.valueCommand(uploadFile, params, &remotePath); err != nil {
+		return "", err
+	}
+	return remotePath, nil
+}
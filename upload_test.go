@@ -0,0 +1,88 @@
+package selenium
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const uploadPage = `<title>Upload</title>
+<form>
+  <input type="file" name="avatar">
+  <input type="file" name="attachments" multiple>
+</form>`
+
+func TestUploadFile(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+	for path, content := range map[string]string{a: "first", b: "second"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile(%q) returned error: %v", path, err)
+		}
+	}
+
+	// sent records the text sent to the file inputs.
+	var sent string
+	// local makes the server reject the file upload command, as the drivers
+	// that run on this machine.
+	local := false
+	record := func(next Handler) Handler {
+		return func(ctx context.Context, cmd *Command) (*Response, error) {
+			switch cmd.Name {
+			case setElementValue:
+				var params struct{ Text string }
+				json.Unmarshal(cmd.Body, &params)
+				sent = params.Text
+			case uploadFile:
+				if local {
+					return nil, &Error{Err: string(ErrUnknownCommand), Message: "unknown command"}
+				}
+			}
+			return next(ctx, cmd)
+		}
+	}
+	wd, s := newFakeSession(t, nil, map[string]string{homePage: uploadPage}, WithInterceptors(record))
+	avatar, err := wd.FindElement(ByCSSSelector, "[name=avatar]")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+	attachments, err := wd.FindElement(ByCSSSelector, "[name=attachments]")
+	if err != nil {
+		t.Fatalf("FindElement() returned error: %v", err)
+	}
+
+	// The files are copied to the server first.
+	if err := attachments.UploadFile(a, b); err != nil {
+		t.Fatalf("UploadFile() returned error: %v", err)
+	}
+	paths := strings.Split(sent, "\n")
+	if len(paths) != 2 {
+		t.Fatalf("UploadFile() sent %q, want two paths", sent)
+	}
+	for i, want := range []string{"first", "second"} {
+		if content, ok := s.UploadedFile(paths[i]); !ok || string(content) != want {
+			t.Errorf("the server has %q, %t under %q, want %q", content, ok, paths[i], want)
+		}
+	}
+
+	// The server cannot copy the files: the local paths are sent.
+	local = true
+	if err := avatar.UploadFile(a); err != nil {
+		t.Fatalf("UploadFile() returned error: %v", err)
+	}
+	if sent != a {
+		t.Errorf("UploadFile() sent %q, want %q", sent, a)
+	}
+	if v, err := avatar.GetAttribute("value"); err != nil || v != `C:\fakepath\a.txt` {
+		t.Errorf("the value of the input is %q, %v, want %q", v, err, `C:\fakepath\a.txt`)
+	}
+
+	for _, paths := range [][]string{nil, {filepath.Join(dir, "missing.txt")}, {a, b}} {
+		if err := avatar.UploadFile(paths...); err == nil {
+			t.Errorf("UploadFile(%q) returned no error", paths)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package selenium
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -68,23 +70,67 @@ const (
 	screenshot              = "screenshot"
 	elementScreenshot       = "elementScreenshot"
 	print                   = "print"
-)
 
-func getApi(key string) api {
-	a := apiMap[key]
-	a.Path = strings.ReplaceAll(a.Path, "{session id}", "%s")
-	a.Path = strings.ReplaceAll(a.Path, "{element id}", "%s")
-	a.Path = strings.ReplaceAll(a.Path, "{shadow id}", "%s")
-	return a
-}
+	// The following commands are not part of the W3C specification. They are
+	// implemented by Selenium and by drivers that speak the legacy JSON wire
+	// protocol.
+	getCapabilities                = "getCapabilities"
+	isElementDisplayed             = "isElementDisplayed"
+	getLog                         = "getLog"
+	uploadFile                     = "uploadFile"
+	getDownloadableFiles           = "getDownloadableFiles"
+	downloadFile                   = "downloadFile"
+	deleteDownloadableFiles        = "deleteDownloadableFiles"
+	legacySetAsyncScriptTimeout    = "legacySetAsyncScriptTimeout"
+	legacySetImplicitWaitTimeout   = "legacySetImplicitWaitTimeout"
+	legacyMaximizeWindow           = "legacyMaximizeWindow"
+	legacyMinimizeWindow           = "legacyMinimizeWindow"
+	legacySetWindowSize            = "legacySetWindowSize"
+	legacyGetActiveElement         = "legacyGetActiveElement"
+	legacyGetElementLocation       = "legacyGetElementLocation"
+	legacyGetElementLocationInView = "legacyGetElementLocationInView"
+	legacySubmitElement            = "legacySubmitElement"
+	legacyMoveTo                   = "legacyMoveTo"
+	legacyClick                    = "legacyClick"
+	legacyDoubleClick              = "legacyDoubleClick"
+	legacyButtonDown               = "legacyButtonDown"
+	legacyButtonUp                 = "legacyButtonUp"
+	legacySendKeys                 = "legacySendKeys"
+	legacyExecuteScript            = "legacyExecuteScript"
+	legacyExecuteScriptAsync       = "legacyExecuteScriptAsync"
+)
 
-func getApi2(key string, sessionID, elementID, shadowID, name string) api {
-	a := apiMap[key]
-	a.Path = strings.ReplaceAll(a.Path, "{session id}", sessionID)
-	a.Path = strings.ReplaceAll(a.Path, "{element id}", elementID)
-	a.Path = strings.ReplaceAll(a.Path, "{shadow id}", shadowID)
-	a.Path = strings.ReplaceAll(a.Path, "{name}", shadowID)
-	return a
+// commandPath returns the method and the path of the command registered under
+// key in apiMap. The placeholders of the path template, such as
+// "{session id}" or "{element id}", are replaced by args in the order in which
+// they appear. Surplus args are ignored.
+func commandPath(key string, args ...string) (api, error) {
+	a, ok := apiMap[key]
+	if !ok {
+		return api{}, fmt.Errorf("unknown command %q", key)
+	}
+	var b strings.Builder
+	rest := a.Path
+	for {
+		i := strings.Index(rest, "{")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(rest[i:], "}")
+		if j < 0 {
+			break
+		}
+		if len(args) == 0 {
+			return api{}, fmt.Errorf("command %q: missing value for %s", key, rest[i:i+j+1])
+		}
+		b.WriteString(rest[:i])
+		b.WriteString(url.PathEscape(args[0]))
+		args = args[1:]
+		rest = rest[i+j+1:]
+	}
+	b.WriteString(rest)
+	a.Path = b.String()
+	return a, nil
 }
 
 var apiMap = map[string]api{
@@ -150,6 +196,31 @@ var apiMap = map[string]api{
 	screenshot:              {http.MethodGet, "/session/{session id}/screenshot"},
 	elementScreenshot:       {http.MethodGet, "/session/{session id}/element/{element id}/screenshot"},
 	print:                   {http.MethodPost, "/session/{session id}/print"},
+
+	getCapabilities:                {http.MethodGet, "/session/{session id}"},
+	isElementDisplayed:             {http.MethodGet, "/session/{session id}/element/{element id}/displayed"},
+	getLog:                         {http.MethodPost, "/session/{session id}/log"},
+	uploadFile:                     {http.MethodPost, "/session/{session id}/se/file"},
+	getDownloadableFiles:           {http.MethodGet, "/session/{session id}/se/files"},
+	downloadFile:                   {http.MethodPost, "/session/{session id}/se/files"},
+	deleteDownloadableFiles:        {http.MethodDelete, "/session/{session id}/se/files"},
+	legacySetAsyncScriptTimeout:    {http.MethodPost, "/session/{session id}/timeouts/async_script"},
+	legacySetImplicitWaitTimeout:   {http.MethodPost, "/session/{session id}/timeouts/implicit_wait"},
+	legacyMaximizeWindow:           {http.MethodPost, "/session/{session id}/window/{window handle}/maximize"},
+	legacyMinimizeWindow:           {http.MethodPost, "/session/{session id}/window/{window handle}/minimize"},
+	legacySetWindowSize:            {http.MethodPost, "/session/{session id}/window/{window handle}/size"},
+	legacyGetActiveElement:         {http.MethodPost, "/session/{session id}/element/active"},
+	legacyGetElementLocation:       {http.MethodGet, "/session/{session id}/element/{element id}/location"},
+	legacyGetElementLocationInView: {http.MethodGet, "/session/{session id}/element/{element id}/location_in_view"},
+	legacySubmitElement:            {http.MethodPost, "/session/{session id}/element/{element id}/submit"},
+	legacyMoveTo:                   {http.MethodPost, "/session/{session id}/moveto"},
+	legacyClick:                    {http.MethodPost, "/session/{session id}/click"},
+	legacyDoubleClick:              {http.MethodPost, "/session/{session id}/doubleclick"},
+	legacyButtonDown:               {http.MethodPost, "/session/{session id}/buttondown"},
+	legacyButtonUp:                 {http.MethodPost, "/session/{session id}/buttonup"},
+	legacySendKeys:                 {http.MethodPost, "/session/{session id}/keys"},
+	legacyExecuteScript:            {http.MethodPost, "/session/{session id}/execute"},
+	legacyExecuteScriptAsync:       {http.MethodPost, "/session/{session id}/execute_async"},
 }
 
 type api struct {
@@ -4,92 +4,178 @@
 package selenium
 
 import (
-	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/blang/semver"
-	"github.com/injoyai/conv"
 	"github.com/injoyai/goutil/g"
 	"github.com/injoyai/goutil/oss"
 	"github.com/injoyai/logs"
 	"github.com/injoyai/selenium/firefox"
 	"github.com/injoyai/selenium/log"
-	"io"
 	"mime"
 	"net/http"
 	"net/url"
-	"path"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Errors returned by Selenium server.
-var remoteErrors = map[int]string{
-	6:   "invalid session ID",
-	7:   "no such element",
-	8:   "no such frame",
-	9:   "unknown command",
-	10:  "stale element reference",
-	11:  "element not visible",
-	12:  "invalid element state",
-	13:  "unknown error",
-	15:  "element is not selectable",
-	17:  "javascript error",
-	19:  "xpath lookup error",
-	21:  "timeout",
-	23:  "no such window",
-	24:  "invalid cookie domain",
-	25:  "unable to set cookie",
-	26:  "unexpected alert open",
-	27:  "no alert open",
-	28:  "script timeout",
-	29:  "invalid element coordinates",
-	32:  "invalid selector",
-	100: "浏览器已关闭",
-}
-
+// WebDriver is a client of a session of a WebDriver server. It is safe for
+// concurrent use by multiple goroutines, as are its copies made by Copy and
+// WithContext, which share its lock.
+//
+// The server runs the commands of a session one at a time, in the browsing
+// context that the session currently has: goroutines that switch windows or
+// frames must coordinate with the other users of the session.
 type WebDriver struct {
 	id, urlPrefix string
 	capabilities  Capabilities
-	w3cCompatible bool
+	// sessionCapabilities are the capabilities returned by the remote end
+	// when the session was created.
+	sessionCapabilities Capabilities
+	w3cCompatible       bool
 	// storedActions stores KeyActions and PointerActions for later execution.
 	storedActions  Actions
 	browser        string
 	browserVersion semver.Version
+	// ctx, if set, bounds every command issued through this instance. It is
+	// only set by WithContext.
+	ctx context.Context
+	// client, if set, is used instead of HTTPClient to talk to the server.
+	client *http.Client
+	// header holds the extra headers sent with every request.
+	header http.Header
+	// retry, if set, controls how failed commands are retried.
+	retry *RetryPolicy
+	// interceptors wrap the sending of every command. A nil slice stands for
+	// the default chain, which only logs the commands.
+	interceptors []Interceptor
+	// state holds the *sessionState shared by the copies of the instance. It
+	// is set by the constructors, or on first use if the instance was
+	// created as a literal.
+	state atomic.Value
+	// window, if set, is the handle of the window in which the commands of
+	// the instance run. It is only set for the WebDriver of a Tab.
+	window string
+	// downloadDir, if set, is the local directory in which WaitForDownload
+	// looks for the downloads.
+	downloadDir string
 
 	wait
 }
 
-// Copy 复制实例,可以生成多个标签页的实例
+// sessionState holds what the copies of a WebDriver share.
+type sessionState struct {
+	// mu guards the fields of the instances that change after the creation
+	// of the session: id, storedActions and interceptors.
+	mu sync.Mutex
+	// windowMu serializes the commands that depend on, or change, the
+	// current window of the session. It guards window.
+	windowMu sync.Mutex
+	// window is the handle of the current window of the session, if known.
+	window string
+}
+
+// initMu guards the creation of the state of the instances created as
+// literals, e.g. new(WebDriver), on their first use. The constructors set the
+// state, so their instances never take it, and once set the state is loaded
+// without locking. A package-level lock is used because WebDriver is copied
+// by value, which rules out a per-instance mutex or sync.Once, and
+// atomic.Value has no compare-and-swap before Go 1.17.
+var initMu sync.Mutex
+
+func (wd *WebDriver) session() *sessionState {
+	if st, ok := wd.state.Load().(*sessionState); ok {
+		return st
+	}
+	initMu.Lock()
+	defer initMu.Unlock()
+	if st, ok := wd.state.Load().(*sessionState); ok {
+		return st
+	}
+	st := new(sessionState)
+	wd.state.Store(st)
+	return st
+}
+
+func (wd *WebDriver) lock() {
+	wd.session().mu.Lock()
+}
+
+func (wd *WebDriver) unlock() {
+	wd.session().mu.Unlock()
+}
+
+// Copy 复制实例,副本共享同一会话及其当前窗口,多个标签页请使用 NewTab
 func (this *WebDriver) Copy() *WebDriver {
+	// The state is set before copying, so that the copy does not race with
+	// its creation.
+	st := this.session()
+	st.mu.Lock()
+	defer st.mu.Unlock()
 	x := *this
+	x.state = atomic.Value{}
+	x.state.Store(st)
+	x.storedActions = append(Actions(nil), this.storedActions...)
+	x.header = this.header.Clone()
 	return &x
 }
 
+// Context returns the context that bounds the commands of this instance. The
+// returned context is always non-nil; it defaults to the background context.
+func (wd *WebDriver) Context() context.Context {
+	if wd.ctx != nil {
+		return wd.ctx
+	}
+	return context.Background()
+}
+
+// WithContext returns a shallow copy of wd whose commands are bound to ctx.
+// Cancelling ctx, or reaching its deadline, aborts any in-flight HTTP request
+// to the WebDriver server as well as any pending Wait. Elements found through
+// the returned instance inherit its context.
+func (wd *WebDriver) WithContext(ctx context.Context) *WebDriver {
+	if ctx == nil {
+		panic("nil context")
+	}
+	x := wd.Copy()
+	x.ctx = ctx
+	return x
+}
+
 // SessionID returns the current session ID
 func (wd *WebDriver) SessionID() string {
+	wd.lock()
+	defer wd.unlock()
 	return wd.id
 }
 
-func newRequest(method string, url string, data []byte) (*http.Request, error) {
-	request, err := http.NewRequest(method, url, bytes.NewBuffer(data))
-	if err != nil {
-		return nil, err
+func (wd *WebDriver) newCommand(name, method, url string, data []byte) *Command {
+	header := make(http.Header, len(wd.header)+1)
+	for k, v := range wd.header {
+		header[k] = append([]string(nil), v...)
+	}
+	header.Add("Accept", jsonContentType)
+	return &Command{
+		Name:   name,
+		Method: method,
+		URL:    url,
+		Header: header,
+		Body:   data,
 	}
-	request.Header.Add("Accept", jsonContentType)
-	return request, nil
 }
 
-func (wd *WebDriver) requestURL(template string, args ...interface{}) string {
-	return wd.urlPrefix + fmt.Sprintf(template, args...)
+func (wd *WebDriver) httpClient() *http.Client {
+	if wd.client != nil {
+		return wd.client
+	}
+	return HTTPClient
 }
 
-// TODO(minusnine): provide a "sessionURL" function that prepends the
-// /session/<id> URL prefix and replace most requestURL (and voidCommand) calls
-// with it.
-
 type serverReply struct {
 	SessionID *string // SessionID can be nil.
 	Value     json.RawMessage
@@ -105,8 +191,8 @@ type serverReply struct {
 // Error contains information about a failure of a command. See the table of
 // these strings at https://www.w3.org/TR/webdriver/#handling-errors .
 //
-// This error type is only returned by servers that implement the W3C
-// specification.
+// Errors reported with the legacy JSON wire protocol are returned with Err set
+// to the equivalent W3C error code. Use Code or errors.Is to match them.
 type Error struct {
 	// Err contains a general error string provided by the server.
 	Err string `json:"error"`
@@ -130,58 +216,99 @@ func (e *Error) Error() string {
 	return fmt.Sprintf("%s: %s", e.Err, e.Message)
 }
 
-// execute performs an HTTP request and inspects the returned data for an error
-// encoded by the remote end in a JSON structure. If no error is present, the
-// entire, raw request payload is returned.
-func (wd *WebDriver) execute(method, url string, data []byte) (json.RawMessage, error) {
-	return executeCommand(method, url, data)
+// execute performs the command registered under key in apiMap and inspects
+// the returned data for an error encoded by the remote end in a JSON
+// structure. args fill the placeholders of the command path that follow the
+// session ID. body is encoded as JSON; a nil body is sent as an empty object
+// for POST commands and omitted otherwise. If no error is present, the entire,
+// raw request payload is returned.
+//
+// The commands of a Tab run in its window, which is switched to when it is
+// not the current window of the session.
+func (wd *WebDriver) execute(key string, body interface{}, args ...string) (json.RawMessage, error) {
+	if wd.window == "" && key != switchToWindow && key != closeWindow {
+		return wd.send(key, body, args...)
+	}
+	st := wd.session()
+	st.windowMu.Lock()
+	defer st.windowMu.Unlock()
+	if wd.window != "" && st.window != wd.window {
+		if _, err := wd.send(switchToWindow, wd.switchWindowParams(wd.window)); err != nil {
+			return nil, err
+		}
+		st.window = wd.window
+	}
+	response, err := wd.send(key, body, args...)
+	if key == switchToWindow || key == closeWindow {
+		// The current window is unknown until a Tab switches to its own.
+		st.window = ""
+	}
+	return response, err
 }
 
-func executeCommand(method, url string, data []byte) (json.RawMessage, error) {
-	logs.Writef(">>> %s %s %s\n", method, filteredURL(url), string(data))
-	request, err := newRequest(method, url, data)
+// send performs the command registered under key, retrying it according to
+// the retry policy of wd.
+func (wd *WebDriver) send(key string, body interface{}, args ...string) (json.RawMessage, error) {
+	api, err := commandPath(key, append([]string{wd.SessionID()}, args...)...)
 	if err != nil {
 		return nil, err
 	}
-
-	response, err := HTTPClient.Do(request)
+	data, err := encodeBody(api.Method, body)
 	if err != nil {
 		return nil, err
 	}
+	for attempt := 1; ; attempt++ {
+		response, err := wd.executeCommand(wd.newCommand(key, api.Method, wd.urlPrefix+api.Path, data))
+		if err == nil || !wd.retry.shouldRetry(wd.Context(), api.Method, attempt, err) {
+			return response, err
+		}
+		if err := wd.retry.wait(wd.Context(), key, attempt, err); err != nil {
+			return nil, err
+		}
+	}
+}
 
-	buf, err := io.ReadAll(response.Body)
-	{
-		//if err == nil {
-		//	// Pretty print the JSON response
-		//	var prettyBuf bytes.Buffer
-		//	if err = json.Indent(&prettyBuf, buf, "", "    "); err == nil && prettyBuf.Len() > 0 {
-		//		buf = prettyBuf.Bytes()
-		//	}
-		//}
-		//response.Header["Content-Type"],
-		logs.Readf("<<< %s %s\n", response.Status, string(buf))
+func encodeBody(method string, body interface{}) ([]byte, error) {
+	if body == nil {
+		if method != http.MethodPost {
+			return nil, nil
+		}
+		body = make(map[string]interface{})
 	}
+	return json.Marshal(body)
+}
+
+// executeCommand sends cmd through the interceptor chain and decodes the
+// reply of the server.
+func (wd *WebDriver) executeCommand(cmd *Command) (json.RawMessage, error) {
+	response, err := wd.handler()(wd.Context(), cmd)
 	if err != nil {
-		return nil, errors.New(response.Status)
+		return nil, err
 	}
+	buf := response.Body
 
 	fullCType := response.Header.Get("Content-Type")
 	cType, _, err := mime.ParseMediaType(fullCType)
-	if err != nil {
-		return nil, fmt.Errorf("got content type header %q, expected %q", fullCType, jsonContentType)
-	}
-	if cType != jsonContentType {
+	if err != nil || cType != jsonContentType {
+		// Proxies and overloaded servers reply with plain error pages.
+		if response.StatusCode >= http.StatusBadRequest {
+			return nil, &statusError{response.StatusCode, response.Status}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("got content type header %q, expected %q", fullCType, jsonContentType)
+		}
 		return nil, fmt.Errorf("got content type %q, expected %q", cType, jsonContentType)
 	}
 
 	reply := new(serverReply)
 	if err := json.Unmarshal(buf, reply); err != nil {
 		if response.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("bad server reply status: %s", response.Status)
+			return nil, &statusError{response.StatusCode, response.Status}
 		}
 		return nil, err
 	}
 	if reply.Err != "" {
+		reply.Error.HTTPCode = response.StatusCode
 		return nil, &reply.Error
 	}
 
@@ -198,17 +325,17 @@ func executeCommand(method, url string, data []byte) (json.RawMessage, error) {
 	// Handle the legacy error format.
 	const success = 0
 	if reply.Status != success {
-		shortMsg, ok := remoteErrors[reply.Status]
-		if !ok {
-			shortMsg = fmt.Sprintf("unknown error - %d", reply.Status)
+		shortMsg := fmt.Sprintf("unknown error - %d", reply.Status)
+		if code, ok := legacyErrorCodes[reply.Status]; ok {
+			shortMsg = string(code)
 		}
 
 		longMsg := new(struct {
 			Message string
 		})
-		if err := json.Unmarshal(reply.Value, longMsg); err != nil {
-			return nil, errors.New(shortMsg)
-		}
+		// The value may be missing or hold something other than an object, in
+		// which case only the short message is reported.
+		_ = json.Unmarshal(reply.Value, longMsg)
 		return nil, &Error{
 			Err:        shortMsg,
 			Message:    longMsg.Message,
@@ -227,6 +354,19 @@ func executeCommand(method, url string, data []byte) (json.RawMessage, error) {
 // Providing an empty string for urlPrefix causes the DefaultURLPrefix to be
 // used.
 func NewRemote(capabilities Capabilities, urlPrefix string) (*WebDriver, error) {
+	return NewRemoteContext(context.Background(), capabilities, urlPrefix)
+}
+
+// NewRemoteWithOptions is like NewRemote, but the client is configured by the
+// provided options.
+func NewRemoteWithOptions(capabilities Capabilities, urlPrefix string, opts ...RemoteOption) (*WebDriver, error) {
+	return NewRemoteContext(context.Background(), capabilities, urlPrefix, opts...)
+}
+
+// NewRemoteContext is like NewRemoteWithOptions, but ctx bounds the creation
+// of the session. The context is not retained by the returned WebDriver; use
+// WithContext to bound later commands.
+func NewRemoteContext(ctx context.Context, capabilities Capabilities, urlPrefix string, opts ...RemoteOption) (*WebDriver, error) {
 	if urlPrefix == "" {
 		urlPrefix = DefaultURLPrefix
 	}
@@ -234,106 +374,98 @@ func NewRemote(capabilities Capabilities, urlPrefix string) (*WebDriver, error)
 	wd := &WebDriver{
 		urlPrefix:    urlPrefix,
 		capabilities: capabilities,
+		ctx:          ctx,
 	}
+	wd.state.Store(new(sessionState))
 	if b := capabilities["browserName"]; b != nil {
 		wd.browser = b.(string)
 	}
+	for _, opt := range opts {
+		if err := opt(wd); err != nil {
+			return nil, err
+		}
+	}
 
 	if _, err := wd.NewSession(); err != nil {
 		return nil, err
 	}
+	wd.ctx = nil
 	return wd, nil
 }
 
 // DeleteSession deletes an existing session at the WebDriver instance
 // specified by the urlPrefix and the session ID.
 func DeleteSession(urlPrefix, id string) error {
-	u, err := url.Parse(urlPrefix)
-	if err != nil {
+	return DeleteSessionContext(context.Background(), urlPrefix, id)
+}
+
+// DeleteSessionContext is like DeleteSession, but the request is bound to ctx
+// and the client is configured by the provided options.
+func DeleteSessionContext(ctx context.Context, urlPrefix, id string, opts ...RemoteOption) error {
+	if _, err := url.Parse(urlPrefix); err != nil {
 		return err
 	}
-	u.Path = path.Join(u.Path, "session", id)
-	return voidCommand("DELETE", u.String(), nil)
+	wd := &WebDriver{id: id, urlPrefix: strings.TrimSuffix(urlPrefix, "/"), ctx: ctx}
+	wd.state.Store(new(sessionState))
+	for _, opt := range opts {
+		if err := opt(wd); err != nil {
+			return err
+		}
+	}
+	return wd.voidCommand(delSession, nil)
 }
 
-func (wd *WebDriver) stringCommand(urlTemplate string) (string, error) {
-	url := wd.requestURL(urlTemplate, wd.id)
-	response, err := wd.execute("GET", url, nil)
+// valueCommand performs the command registered under key and decodes the
+// "value" field of the reply into value.
+func (wd *WebDriver) valueCommand(key string, body, value interface{}, args ...string) error {
+	response, err := wd.execute(key, body, args...)
 	if err != nil {
-		return "", err
+		return err
 	}
+	reply := struct{ Value interface{} }{value}
+	return json.Unmarshal(response, &reply)
+}
 
-	reply := new(struct{ Value *string })
-	if err := json.Unmarshal(response, reply); err != nil {
+func (wd *WebDriver) stringCommand(key string, args ...string) (string, error) {
+	var value *string
+	if err := wd.valueCommand(key, nil, &value, args...); err != nil {
 		return "", err
 	}
 
-	if reply.Value == nil {
+	if value == nil {
 		return "", fmt.Errorf("nil return value")
 	}
 
-	return *reply.Value, nil
+	return *value, nil
 }
 
-func voidCommand(method, url string, params interface{}) error {
-	if params == nil {
-		params = make(map[string]interface{})
-	}
-	data, err := json.Marshal(params)
-	if err != nil {
-		return err
-	}
-	_, err = executeCommand(method, url, data)
+func (wd *WebDriver) voidCommand(key string, body interface{}, args ...string) error {
+	_, err := wd.execute(key, body, args...)
 	return err
 }
 
-func (wd *WebDriver) voidCommand(urlTemplate string, params interface{}) error {
-	return voidCommand("POST", wd.requestURL(urlTemplate, wd.id), params)
-}
-
-func (wd *WebDriver) stringsCommand(urlTemplate string) ([]string, error) {
-	url := wd.requestURL(urlTemplate, wd.id)
-	response, err := wd.execute("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	reply := new(struct{ Value []string })
-	if err := json.Unmarshal(response, reply); err != nil {
+func (wd *WebDriver) stringsCommand(key string, args ...string) ([]string, error) {
+	var value []string
+	if err := wd.valueCommand(key, nil, &value, args...); err != nil {
 		return nil, err
 	}
-
-	return reply.Value, nil
+	return value, nil
 }
 
-func (wd *WebDriver) boolCommand(urlTemplate string) (bool, error) {
-	url := wd.requestURL(urlTemplate, wd.id)
-	response, err := wd.execute("GET", url, nil)
-	if err != nil {
+func (wd *WebDriver) boolCommand(key string, args ...string) (bool, error) {
+	var value bool
+	if err := wd.valueCommand(key, nil, &value, args...); err != nil {
 		return false, err
 	}
-
-	reply := new(struct{ Value bool })
-	if err := json.Unmarshal(response, reply); err != nil {
-		return false, err
-	}
-
-	return reply.Value, nil
+	return value, nil
 }
 
 func (wd *WebDriver) Status() (*Status, error) {
-	url := wd.requestURL("/status")
-	reply, err := wd.execute("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	status := new(struct{ Value Status })
-	if err := json.Unmarshal(reply, status); err != nil {
+	status := new(Status)
+	if err := wd.valueCommand(getStatus, nil, status); err != nil {
 		return nil, err
 	}
-
-	return &status.Value, nil
+	return status, nil
 }
 
 // parseVersion sanitizes the browser version enough for semver.ParseTolerant
@@ -366,6 +498,7 @@ var w3cCapabilityNames = []string{
 	"setWindowRect",
 	"timeouts",
 	"unhandledPromptBehavior",
+	"webSocketUrl",
 }
 
 var chromeCapabilityNames = []string{
@@ -441,12 +574,7 @@ func (wd *WebDriver) NewSession() (string, error) {
 		}}}
 
 	for i, s := range attempts {
-		data, err := json.Marshal(s.params)
-		if err != nil {
-			return "", err
-		}
-
-		response, err := wd.execute("POST", wd.requestURL("/session"), data)
+		response, err := wd.execute(newSession, s.params)
 		if err != nil {
 			return "", err
 		}
@@ -462,7 +590,7 @@ func (wd *WebDriver) NewSession() (string, error) {
 			continue
 		}
 		if reply.SessionID != nil {
-			wd.id = *reply.SessionID
+			wd.setSessionID(*reply.SessionID)
 		}
 
 		if len(reply.Value) > 0 {
@@ -498,8 +626,9 @@ func (wd *WebDriver) NewSession() (string, error) {
 			if err := json.Unmarshal(reply.Value, &value); err != nil {
 				return "", fmt.Errorf("error unmarshalling value: %v", err)
 			}
-			if value.SessionID != "" && wd.id == "" {
-				wd.id = value.SessionID
+			wd.sessionCapabilities = sessionCapabilities(reply.Value)
+			if value.SessionID != "" && wd.SessionID() == "" {
+				wd.setSessionID(value.SessionID)
 			}
 			var caps returnedCapabilities
 			if value.Capabilities != nil {
@@ -522,44 +651,53 @@ func (wd *WebDriver) NewSession() (string, error) {
 			}
 		}
 
-		return wd.id, nil
+		return wd.SessionID(), nil
 	}
 	panic("unreachable")
 }
 
-func (wd *WebDriver) Capabilities() (Capabilities, error) {
-	url := wd.requestURL("/session/%s", wd.id)
-	response, err := wd.execute("GET", url, nil)
-	if err != nil {
-		return nil, err
+// sessionCapabilities extracts the capabilities from the value of a new
+// session reply, in either the W3C or the legacy format.
+func sessionCapabilities(value json.RawMessage) Capabilities {
+	var w3c struct {
+		Capabilities Capabilities
+	}
+	if err := json.Unmarshal(value, &w3c); err == nil && w3c.Capabilities != nil {
+		return w3c.Capabilities
+	}
+	var legacy Capabilities
+	if err := json.Unmarshal(value, &legacy); err != nil {
+		return nil
 	}
+	return legacy
+}
 
-	c := new(struct{ Value Capabilities })
-	if err := json.Unmarshal(response, c); err != nil {
+func (wd *WebDriver) Capabilities() (Capabilities, error) {
+	var caps Capabilities
+	if err := wd.valueCommand(getCapabilities, nil, &caps); err != nil {
 		return nil, err
 	}
-
-	return c.Value, nil
+	return caps, nil
 }
 
 func (wd *WebDriver) SetAsyncScriptTimeout(timeout time.Duration) error {
 	if !wd.w3cCompatible {
-		return wd.voidCommand("/session/%s/timeouts/async_script", map[string]uint{
+		return wd.voidCommand(legacySetAsyncScriptTimeout, map[string]uint{
 			"ms": uint(timeout / time.Millisecond),
 		})
 	}
-	return wd.voidCommand("/session/%s/timeouts", map[string]uint{
+	return wd.voidCommand(setTimeout, map[string]uint{
 		"script": uint(timeout / time.Millisecond),
 	})
 }
 
 func (wd *WebDriver) SetImplicitWaitTimeout(timeout time.Duration) error {
 	if !wd.w3cCompatible {
-		return wd.voidCommand("/session/%s/timeouts/implicit_wait", map[string]uint{
+		return wd.voidCommand(legacySetImplicitWaitTimeout, map[string]uint{
 			"ms": uint(timeout / time.Millisecond),
 		})
 	}
-	return wd.voidCommand("/session/%s/timeouts", map[string]uint{
+	return wd.voidCommand(setTimeout, map[string]uint{
 		"implicit": uint(timeout / time.Millisecond),
 	})
 }
@@ -572,84 +710,89 @@ func (wd *WebDriver) SetPageLoadTimeout(timeout time.Duration) error {
 	} else {
 		body["pageLoad"] = uint(timeout / time.Millisecond)
 	}
-	_, err := wd.request(setTimeout, "", "", "", body)
-	return err
+	return wd.voidCommand(setTimeout, body)
+}
+
+// Timeouts returns the current timeouts of the session.
+func (wd *WebDriver) Timeouts() (*Timeouts, error) {
+	var reply struct {
+		Implicit float64
+		PageLoad float64
+		Script   *float64
+	}
+	if err := wd.valueCommand(getTimeout, nil, &reply); err != nil {
+		return nil, err
+	}
+	ms := func(v float64) time.Duration { return time.Duration(v * float64(time.Millisecond)) }
+	t := &Timeouts{Implicit: ms(reply.Implicit), PageLoad: ms(reply.PageLoad), Script: -1}
+	if reply.Script != nil {
+		t.Script = ms(*reply.Script)
+	}
+	return t, nil
 }
 
 func (wd *WebDriver) Quit() error {
-	if wd.id == "" {
+	if wd.SessionID() == "" {
 		return nil
 	}
-	api := getApi(delSession)
-	_, err := wd.execute(api.Method, wd.requestURL(api.Path, wd.id), nil)
+	err := wd.voidCommand(delSession, nil)
 	if err == nil {
-		wd.id = ""
+		wd.setSessionID("")
 	}
 	return err
 }
 
+func (wd *WebDriver) setSessionID(id string) {
+	wd.lock()
+	defer wd.unlock()
+	wd.id = id
+}
+
 func (wd *WebDriver) CurrentWindowHandle() (string, error) {
-	v, err := wd.request(getWindow, "", "", "", nil)
-	if err != nil {
-		return "", err
-	}
-	return conv.String(v), nil
+	return wd.stringCommand(getWindow)
 }
 
 func (wd *WebDriver) WindowHandles() ([]string, error) {
-	v, err := wd.request(getWindows, "", "", "", nil)
-	if err != nil {
-		return nil, err
-	}
-	return conv.Strings(v), nil
+	return wd.stringsCommand(getWindows)
 }
 
 func (wd *WebDriver) CurrentURL() (string, error) {
-	v, err := wd.request(getUrl, "", "", "", nil)
-	if err != nil {
-		return "", err
-	}
-	return conv.String(v), nil
+	return wd.stringCommand(getUrl)
 }
 
 func (wd *WebDriver) Get(url string) error {
-	requestURL := wd.requestURL("/session/%s/url", wd.id)
-	params := map[string]string{
+	return wd.voidCommand(openUrl, map[string]string{
 		"url": url,
-	}
-	data, err := json.Marshal(params)
-	if err != nil {
-		return err
-	}
-	_, err = wd.execute("POST", requestURL, data)
-	return err
+	})
 }
 
 func (wd *WebDriver) Forward() error {
-	return wd.voidCommand("/session/%s/forward", nil)
+	return wd.voidCommand(forward, nil)
 }
 
 func (wd *WebDriver) Back() error {
-	return wd.voidCommand("/session/%s/back", nil)
+	return wd.voidCommand(back, nil)
 }
 
 func (wd *WebDriver) Refresh() error {
-	return wd.voidCommand("/session/%s/refresh", nil)
+	return wd.voidCommand(refresh, nil)
 }
 
 func (wd *WebDriver) Title() (string, error) {
-	return wd.stringCommand("/session/%s/title")
+	return wd.stringCommand(getTitle)
 }
 
 func (wd *WebDriver) PageSource() (string, error) {
-	return wd.stringCommand("/session/%s/source")
+	return wd.stringCommand(getSource)
 }
 
 func (wd *WebDriver) Text() (string, error) {
 	return wd.PageSource()
 }
 
-func (wd *WebDriver) find(by, value, suffix, url string) ([]byte, error) {
+// find performs one of the find commands. elementID is the element to search
+// from and is only used by findElementFromElement and findElementsFromElement.
+func (wd *WebDriver) find(key, elementID, by, value string) ([]byte, error) {
 	// The W3C specification removed the specific ID and Name locator strategies,
 	// instead only providing a CSS-based strategy. Emulate the old behavior to
 	// maintain API compatibility.
@@ -668,16 +811,7 @@ func (wd *WebDriver) find(by, value, suffix, url string) ([]byte, error) {
 		"using": by,
 		"value": value,
 	}
-	data, err := json.Marshal(params)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(url) == 0 {
-		url = "/session/%s/element"
-	}
-
-	return wd.execute("POST", wd.requestURL(url+suffix, wd.id), data)
+	return wd.execute(key, params, elementID)
 }
 
 func (wd *WebDriver) DecodeElement(data []byte) (*WebElement, error) {
@@ -740,7 +874,7 @@ func (wd *WebDriver) DecodeElements(data []byte) ([]*WebElement, error) {
 }
 
 func (wd *WebDriver) FindElement(by, value string) (*WebElement, error) {
-	response, err := wd.find(by, value, "", "")
+	response, err := wd.find(findElement, "", by, value)
 	if err != nil {
 		return nil, err
 	}
@@ -748,7 +882,7 @@ func (wd *WebDriver) FindElement(by, value string) (*WebElement, error) {
 }
 
 func (wd *WebDriver) FindElements(by, value string) ([]*WebElement, error) {
-	response, err := wd.find(by, value, "s", "")
+	response, err := wd.find(findElements, "", by, value)
 	if err != nil {
 		return nil, err
 	}
@@ -835,46 +969,88 @@ func (this *WebDriver) FindTag(tag string) (*WebElement, error) {
 }
 
 func (wd *WebDriver) Close() error {
-	url := wd.requestURL("/session/%s/window", wd.id)
-	_, err := wd.execute("DELETE", url, nil)
-	return err
+	return wd.voidCommand(closeWindow, nil)
 }
 
 func (wd *WebDriver) SwitchWindow(name string) error {
+	return wd.voidCommand(switchToWindow, wd.switchWindowParams(name))
+}
+
+func (wd *WebDriver) switchWindowParams(name string) map[string]string {
 	params := make(map[string]string)
 	if !wd.w3cCompatible {
 		params["name"] = name
 	} else {
 		params["handle"] = name
 	}
-	return wd.voidCommand("/session/%s/window", params)
+	return params
 }
 
 func (wd *WebDriver) CloseWindow(name string) error {
-	return wd.modifyWindow(name, "DELETE", "", nil)
+	return wd.modifyWindow(name, closeWindow, nil)
 }
 
 func (wd *WebDriver) MaximizeWindow(name string) error {
 	if !wd.w3cCompatible {
-		if name != "" {
+		if name == "" {
 			var err error
 			name, err = wd.CurrentWindowHandle()
 			if err != nil {
 				return err
 			}
 		}
-		url := wd.requestURL("/session/%s/window/%s/maximize", wd.id, name)
-		_, err := wd.execute("POST", url, nil)
-		return err
+		return wd.voidCommand(legacyMaximizeWindow, nil, name)
 	}
-	return wd.modifyWindow(name, "POST", "maximize", map[string]string{})
+	return wd.modifyWindow(name, maximizeWindow, map[string]string{})
 }
 
 func (wd *WebDriver) MinimizeWindow(name string) error {
-	return wd.modifyWindow(name, "POST", "minimize", map[string]string{})
+	if !wd.w3cCompatible {
+		return wd.modifyWindow(name, legacyMinimizeWindow, map[string]string{})
+	}
+	return wd.modifyWindow(name, minimizeWindow, map[string]string{})
+}
+
+// FullscreenWindow makes a window fullscreen. If the name is empty, the
+// current window is used.
+func (wd *WebDriver) FullscreenWindow(name string) error {
+	return wd.modifyWindow(name, fullscreenWindow, map[string]string{})
+}
+
+// NewWindow opens a new window of type typ, without switching to it, and
+// returns its handle. The browser may open a window of another type.
+func (wd *WebDriver) NewWindow(typ WindowType) (string, error) {
+	var reply struct {
+		Handle string `json:"handle"`
+	}
+	if err := wd.valueCommand(newWindow, map[string]string{"type": string(typ)}, &reply); err != nil {
+		return "", err
+	}
+	return reply.Handle, nil
+}
+
+// WindowRect returns the position and the size of the current window.
+func (wd *WebDriver) WindowRect() (*Rect, error) {
+	r := new(rect)
+	if err := wd.valueCommand(getWindowRect, nil, r); err != nil {
+		return nil, err
+	}
+	return &Rect{round(r.X), round(r.Y), round(r.Width), round(r.Height)}, nil
+}
+
+// SetWindowRect moves and resizes the current window.
+func (wd *WebDriver) SetWindowRect(r Rect) error {
+	return wd.voidCommand(setWindowRect, map[string]float64{
+		"x":      float64(r.X),
+		"y":      float64(r.Y),
+		"width":  float64(r.Width),
+		"height": float64(r.Height),
+	})
 }
 
-func (wd *WebDriver) modifyWindow(name, verb, command string, params interface{}) error {
+// modifyWindow performs the window command registered under key on the named
+// window. Legacy commands take the window name as their only path argument.
+func (wd *WebDriver) modifyWindow(name, key string, params interface{}) error {
 	// The original protocol allowed for maximizing any named window. The W3C
 	// specification only allows the current window be be modified. Emulate the
 	// previous behavior by switching to the target window, maximizing the
@@ -893,24 +1069,7 @@ func (wd *WebDriver) modifyWindow(name, verb, command string, params interface{}
 		}
 	}
 
-	url := wd.requestURL("/session/%s/window", wd.id)
-	if command != "" {
-		if wd.w3cCompatible {
-			url = wd.requestURL("/session/%s/window/%s", wd.id, command)
-		} else {
-			url = wd.requestURL("/session/%s/window/%s/%s", wd.id, name, command)
-		}
-	}
-
-	var data []byte
-	if params != nil {
-		var err error
-		if data, err = json.Marshal(params); err != nil {
-			return err
-		}
-	}
-
-	if _, err := wd.execute(verb, url, data); err != nil {
+	if err := wd.voidCommand(key, params, name); err != nil {
 		return err
 	}
 
@@ -926,12 +1085,12 @@ func (wd *WebDriver) modifyWindow(name, verb, command string, params interface{}
 
 func (wd *WebDriver) ResizeWindow(name string, width, height int) error {
 	if !wd.w3cCompatible {
-		return wd.modifyWindow(name, "POST", "size", map[string]int{
+		return wd.modifyWindow(name, legacySetWindowSize, map[string]int{
 			"width":  width,
 			"height": height,
 		})
 	}
-	return wd.modifyWindow(name, "POST", "rect", map[string]float64{
+	return wd.modifyWindow(name, setWindowRect, map[string]float64{
 		"width":  float64(width),
 		"height": float64(height),
 	})
@@ -957,16 +1116,20 @@ func (wd *WebDriver) SwitchFrame(frame interface{}) error {
 	default:
 		return fmt.Errorf("invalid type %T", frame)
 	}
-	return wd.voidCommand("/session/%s/frame", params)
+	return wd.voidCommand(switchToFrame, params)
+}
+
+// SwitchToParentFrame switches to the parent of the current frame.
+func (wd *WebDriver) SwitchToParentFrame() error {
+	return wd.voidCommand(switchToParentFrame, nil)
 }
 
 func (wd *WebDriver) ActiveElement() (*WebElement, error) {
-	verb := "GET"
+	key := getActiveElement
 	if wd.browser == "firefox" && wd.browserVersion.Major < 47 {
-		verb = "POST"
+		key = legacyGetActiveElement
 	}
-	url := wd.requestURL("/session/%s/element/active", wd.id)
-	response, err := wd.execute(verb, url, nil)
+	response, err := wd.execute(key, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1036,8 +1199,7 @@ func (wd *WebDriver) GetCookie(name string) (Cookie, error) {
 		}
 		return Cookie{}, errors.New("cookie not found")
 	}
-	url := wd.requestURL("/session/%s/cookie/%s", wd.id, name)
-	data, err := wd.execute("GET", url, nil)
+	data, err := wd.execute(getCookie, nil, name)
 	if err != nil {
 		return Cookie{}, err
 	}
@@ -1061,12 +1223,8 @@ func (wd *WebDriver) GetCookie(name string) (Cookie, error) {
 }
 
 func (wd *WebDriver) GetCookies() ([]Cookie, error) {
-	v, err := wd.request(getCookies, "", "", "", nil)
-	if err != nil {
-		return nil, err
-	}
-	reply := []cookie(nil)
-	if err := json.Unmarshal(conv.Bytes(v), &reply); err != nil {
+	var reply []cookie
+	if err := wd.valueCommand(getCookies, nil, &reply); err != nil {
 		return nil, err
 	}
 
@@ -1078,43 +1236,39 @@ func (wd *WebDriver) GetCookies() ([]Cookie, error) {
 }
 
 func (wd *WebDriver) AddCookie(cookie *Cookie) error {
-	return wd.voidCommand("/session/%s/cookie", map[string]*Cookie{
+	return wd.voidCommand(addCookie, map[string]*Cookie{
 		"cookie": cookie,
 	})
 }
 
 func (wd *WebDriver) DeleteAllCookies() error {
-	url := wd.requestURL("/session/%s/cookie", wd.id)
-	_, err := wd.execute("DELETE", url, nil)
-	return err
+	return wd.voidCommand(delCookies, nil)
 }
 
 func (wd *WebDriver) DeleteCookie(name string) error {
-	url := wd.requestURL("/session/%s/cookie/%s", wd.id, name)
-	_, err := wd.execute("DELETE", url, nil)
-	return err
+	return wd.voidCommand(delCookie, nil, name)
 }
 
 // Click TODO(minusnine): add a test for Click.
 func (wd *WebDriver) Click(button int) error {
-	return wd.voidCommand("/session/%s/click", map[string]int{
+	return wd.voidCommand(legacyClick, map[string]int{
 		"button": button,
 	})
 }
 
 // DoubleClick TODO(minusnine): add a test for DoubleClick.
 func (wd *WebDriver) DoubleClick() error {
-	return wd.voidCommand("/session/%s/doubleclick", nil)
+	return wd.voidCommand(legacyDoubleClick, nil)
 }
 
 // ButtonDown TODO(minusnine): add a test for ButtonDown.
 func (wd *WebDriver) ButtonDown() error {
-	return wd.voidCommand("/session/%s/buttondown", nil)
+	return wd.voidCommand(legacyButtonDown, nil)
 }
 
 // ButtonUp TODO(minusnine): add a test for ButtonUp.
 func (wd *WebDriver) ButtonUp() error {
-	return wd.voidCommand("/session/%s/buttonup", nil)
+	return wd.voidCommand(legacyButtonUp, nil)
 }
 
 func (wd *WebDriver) SendModifier(modifier string, isDown bool) error {
@@ -1136,7 +1290,7 @@ func (wd *WebDriver) keyAction(action, keys string) error {
 			Key:  string(key),
 		})
 	}
-	return wd.voidCommand("/session/%s/actions", map[string]interface{}{
+	return wd.voidCommand(addActions, map[string]interface{}{
 		"actions": []interface{}{
 			map[string]interface{}{
 				"type":    "key",
@@ -1150,7 +1304,7 @@ func (wd *WebDriver) KeyDown(keys string) error {
 	// Selenium implemented the actions API but has not yet updated its new
 	// session response.
 	if !wd.w3cCompatible && !(wd.browser == "firefox" && wd.browserVersion.Major > 47) {
-		return wd.voidCommand("/session/%s/keys", wd.processKeyString(keys))
+		return wd.voidCommand(legacySendKeys, wd.processKeyString(keys))
 	}
 	return wd.keyAction("keyDown", keys)
 }
@@ -1230,6 +1384,8 @@ func (wd *WebDriver) StoreKeyActions(inputID string, actions ...KeyAction) {
 	for _, action := range actions {
 		rawActions = append(rawActions, action)
 	}
+	wd.lock()
+	defer wd.unlock()
 	wd.storedActions = append(wd.storedActions, map[string]interface{}{
 		"type":    "key",
 		"id":      inputID,
@@ -1242,6 +1398,8 @@ func (wd *WebDriver) StorePointerActions(inputID string, pointer PointerType, ac
 	for _, action := range actions {
 		rawActions = append(rawActions, action)
 	}
+	wd.lock()
+	defer wd.unlock()
 	wd.storedActions = append(wd.storedActions, map[string]interface{}{
 		"type":       "pointer",
 		"id":         inputID,
@@ -1250,53 +1408,52 @@ func (wd *WebDriver) StorePointerActions(inputID string, pointer PointerType, ac
 	})
 }
 
+// PerformActions performs the actions stored in wd, and clears them. The
+// actions stored concurrently with the call are kept for the next one.
 func (wd *WebDriver) PerformActions() error {
-	err := wd.voidCommand("/session/%s/actions", map[string]interface{}{
-		"actions": wd.storedActions,
-	})
+	wd.lock()
+	actions := wd.storedActions
 	wd.storedActions = nil
-	return err
+	wd.unlock()
+	return wd.voidCommand(addActions, map[string]interface{}{
+		"actions": actions,
+	})
 }
 
 func (wd *WebDriver) ReleaseActions() error {
-	return voidCommand("DELETE", wd.requestURL("/session/%s/actions", wd.id), nil)
+	return wd.voidCommand(delActions, nil)
 }
 
 func (wd *WebDriver) DismissAlert() error {
-	return wd.voidCommand("/session/%s/alert/dismiss", nil)
+	return wd.voidCommand(dismissAlert, nil)
 }
 
 func (wd *WebDriver) AcceptAlert() error {
-	return wd.voidCommand("/session/%s/alert/accept", nil)
+	return wd.voidCommand(acceptAlert, nil)
 }
 
 func (wd *WebDriver) AlertText() (string, error) {
-	return wd.stringCommand("/session/%s/alert/text")
+	return wd.stringCommand(getAlertText)
 }
 
 func (wd *WebDriver) SetAlertText(text string) error {
 	data := map[string]string{"text": text}
-	return wd.voidCommand("/session/%s/alert/text", data)
+	return wd.voidCommand(setAlertText, data)
 }
 
-func (wd *WebDriver) execScriptRaw(script string, args []interface{}, suffix string) ([]byte, error) {
+func (wd *WebDriver) execScriptRaw(key, script string, args []interface{}) ([]byte, error) {
 	if args == nil {
 		args = make([]interface{}, 0)
 	}
 
-	data, err := json.Marshal(map[string]interface{}{
+	return wd.execute(key, map[string]interface{}{
 		"script": script,
 		"args":   args,
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	return wd.execute("POST", wd.requestURL("/session/%s/execute"+suffix, wd.id), data)
 }
 
-func (wd *WebDriver) execScript(script string, args []interface{}, suffix string) (interface{}, error) {
-	response, err := wd.execScriptRaw(script, args, suffix)
+func (wd *WebDriver) execScript(key, script string, args []interface{}) (interface{}, error) {
+	response, err := wd.execScriptRaw(key, script, args)
 	if err != nil {
 		return nil, err
 	}
@@ -1311,39 +1468,39 @@ func (wd *WebDriver) execScript(script string, args []interface{}, suffix string
 
 func (wd *WebDriver) ExecuteScript(script string, args []interface{}) (interface{}, error) {
 	if !wd.w3cCompatible {
-		return wd.execScript(script, args, "")
+		return wd.execScript(legacyExecuteScript, script, args)
 	}
-	return wd.execScript(script, args, "/sync")
+	return wd.execScript(executeScriptSync, script, args)
 }
 
 func (wd *WebDriver) ExecuteScriptAsync(script string, args []interface{}) (interface{}, error) {
 	if !wd.w3cCompatible {
-		return wd.execScript(script, args, "_async")
+		return wd.execScript(legacyExecuteScriptAsync, script, args)
 	}
-	return wd.execScript(script, args, "/async")
+	return wd.execScript(executeScriptAsync, script, args)
 }
 
 func (wd *WebDriver) ExecuteScriptRaw(script string, args []interface{}) ([]byte, error) {
 	if !wd.w3cCompatible {
-		return wd.execScriptRaw(script, args, "")
+		return wd.execScriptRaw(legacyExecuteScript, script, args)
 	}
-	return wd.execScriptRaw(script, args, "/sync")
+	return wd.execScriptRaw(executeScriptSync, script, args)
 }
 
 func (wd *WebDriver) ExecuteScriptAsyncRaw(script string, args []interface{}) ([]byte, error) {
 	if !wd.w3cCompatible {
-		return wd.execScriptRaw(script, args, "_async")
+		return wd.execScriptRaw(legacyExecuteScriptAsync, script, args)
 	}
-	return wd.execScriptRaw(script, args, "/async")
+	return wd.execScriptRaw(executeScriptAsync, script, args)
 }
 
 // Screenshot 截图信息
 func (wd *WebDriver) Screenshot() ([]byte, error) {
-	v, err := wd.request(screenshot, "", "", "", nil)
+	data, err := wd.stringCommand(screenshot)
 	if err != nil {
 		return nil, err
 	}
-	return base64.StdEncoding.DecodeString(conv.String(v))
+	return base64.StdEncoding.DecodeString(data)
 }
 
 // SaveScreenshot 保存截图
@@ -1359,7 +1516,10 @@ func (wd *WebDriver) SaveScreenshot(filename string) error {
 // for selenium.Wait(cond Condition) (error) function.
 type Condition func(wd *WebDriver) (bool, error)
 
+// WaitWithTimeoutAndInterval polls condition every interval until it returns
+// true, it returns an error, the timeout elapses or the context of wd is done.
 func (wd *WebDriver) WaitWithTimeoutAndInterval(condition Condition, timeout, interval time.Duration) error {
+	ctx := wd.Context()
 	startTime := time.Now()
 
 	for {
@@ -1374,7 +1534,11 @@ func (wd *WebDriver) WaitWithTimeoutAndInterval(condition Condition, timeout, in
 		if elapsed := time.Since(startTime); elapsed > timeout {
 			return fmt.Errorf("timeout after %v", elapsed)
 		}
-		time.Sleep(interval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
 	}
 }
 
@@ -1387,32 +1551,20 @@ func (wd *WebDriver) Wait(condition Condition) error {
 }
 
 func (wd *WebDriver) Log(typ log.Type) ([]log.Message, error) {
-	url := wd.requestURL("/session/%s/log", wd.id)
 	params := map[string]log.Type{
 		"type": typ,
 	}
-	data, err := json.Marshal(params)
-	if err != nil {
-		return nil, err
-	}
-	response, err := wd.execute("POST", url, data)
-	if err != nil {
-		return nil, err
+	var value []struct {
+		Timestamp int64
+		Level     string
+		Message   string
 	}
-
-	c := new(struct {
-		Value []struct {
-			Timestamp int64
-			Level     string
-			Message   string
-		}
-	})
-	if err = json.Unmarshal(response, c); err != nil {
+	if err := wd.valueCommand(getLog, params, &value); err != nil {
 		return nil, err
 	}
 
-	val := make([]log.Message, len(c.Value))
-	for i, v := range c.Value {
+	val := make([]log.Message, len(value))
+	for i, v := range value {
 		val[i] = log.Message{
 			// n.b.: Chrome, which is the only browser that supports this API,
 			// supplies timestamps in milliseconds since the Epoch.
@@ -1424,42 +1576,3 @@ func (wd *WebDriver) Log(typ log.Type) ([]log.Message, error) {
 
 	return val, nil
 }
-
-func (wd *WebDriver) request(key, elementID, shadowID, name string, body interface{}) (interface{}, error) {
-	api := getApi2(key, wd.id, elementID, shadowID, name)
-	bodyBytes := conv.Bytes(body)
-	req, err := http.NewRequest(api.Method, wd.urlPrefix+api.Path, bytes.NewReader(bodyBytes))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Add("Accept", jsonContentType)
-	logs.Writef(">>> %s %s %s\n", api.Method, api.Path, string(bodyBytes))
-	resp, err := HTTPClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("状态码错误: %s", resp.Status)
-	}
-	bs, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	logs.Readf("<<< %s %s\n", resp.Status, string(bs))
-	m := conv.NewMap(bs)
-	status := m.GetInt("status", -1)
-	if status == -1 {
-		return nil, errors.New("未知错误: " + m.String())
-	}
-	if status != 0 {
-		if msg := remoteErrors[status]; len(msg) > 0 {
-			return nil, errors.New(remoteErrors[status])
-		}
-		if msg := m.GetString("value.message"); len(msg) > 0 {
-			return nil, errors.New(msg)
-		}
-		return nil, errors.New("未知错误")
-	}
-	return m.GetInterface("value"), nil
-}
@@ -0,0 +1,359 @@
+package selenium
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/injoyai/selenium/fakedriver"
+)
+
+// homePage is the page at which newFakeSession starts the session, if the
+// server has it.
+const homePage = "http://example.com/"
+
+// newFakeServer starts a fakedriver server for pages, which is closed when
+// the test ends. WebElement.Submit works on the server.
+func newFakeServer(t *testing.T, pages map[string]string) *fakedriver.Server {
+	s := fakedriver.New(pages)
+	t.Cleanup(s.Close)
+	s.HandleSubmitScript(submitScript)
+	return s
+}
+
+// newFakeSession starts a fakedriver server for pages and creates a session
+// with caps and opts on it, which ends with the test. The session is at
+// homePage if pages has it.
+func newFakeSession(t *testing.T, caps Capabilities, pages map[string]string, opts ...RemoteOption) (*WebDriver, *fakedriver.Server) {
+	t.Helper()
+	s := newFakeServer(t, pages)
+	wd, err := NewRemoteWithOptions(caps, s.URL, opts...)
+	if err != nil {
+		t.Fatalf("NewRemoteWithOptions() returned error: %v", err)
+	}
+	t.Cleanup(func() { wd.Quit() })
+	if _, ok := pages[homePage]; ok {
+		if err := wd.Get(homePage); err != nil {
+			t.Fatalf("Get() returned error: %v", err)
+		}
+	}
+	return wd, s
+}
+
+func TestWithContextCancelsCommand(t *testing.T) {
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer hs.Close()
+
+	wd := &WebDriver{id: "1", urlPrefix: hs.URL}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := wd.WithContext(ctx).Title(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Title() returned error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if wd.ctx != nil {
+		t.Fatalf("WithContext modified the original WebDriver")
+	}
+}
+
+func TestWaitWithCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	wd := (&WebDriver{}).WithContext(ctx)
+
+	calls := 0
+	err := wd.WaitWithTimeoutAndInterval(func(*WebDriver) (bool, error) {
+		calls++
+		cancel()
+		return false, nil
+	}, time.Minute, time.Minute)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("WaitWithTimeoutAndInterval returned error %v, want %v", err, context.Canceled)
+	}
+	if calls != 1 {
+		t.Fatalf("condition called %d times, want 1", calls)
+	}
+}
+
+func TestCommandPath(t *testing.T) {
+	tests := []struct {
+		key  string
+		args []string
+		want api
+	}{
+		{getStatus, nil, api{http.MethodGet, "/status"}},
+		{getStatus, []string{"1"}, api{http.MethodGet, "/status"}},
+		{getTitle, []string{"1"}, api{http.MethodGet, "/session/1/title"}},
+		{getElementAttribute, []string{"1", "e", "data-x"}, api{http.MethodGet, "/session/1/element/e/attribute/data-x"}},
+		{delCookie, []string{"1", "a b"}, api{http.MethodDelete, "/session/1/cookie/a%20b"}},
+		{legacyMaximizeWindow, []string{"1", "w"}, api{http.MethodPost, "/session/1/window/w/maximize"}},
+	}
+	for _, test := range tests {
+		got, err := commandPath(test.key, test.args...)
+		if err != nil {
+			t.Errorf("commandPath(%q, %q) returned error: %v", test.key, test.args, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("commandPath(%q, %q) = %v, want %v", test.key, test.args, got, test.want)
+		}
+	}
+
+	if _, err := commandPath(getElementText, "1"); err == nil {
+		t.Errorf("commandPath(%q) with a missing element ID did not return an error", getElementText)
+	}
+	if _, err := commandPath("noSuchCommand"); err == nil {
+		t.Errorf("commandPath with an unknown command did not return an error")
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	tests := []struct {
+		desc   string
+		status int
+		body   string
+		want   Error
+	}{
+		{
+			desc:   "W3C error with a non-200 status",
+			status: http.StatusNotFound,
+			body:   `{"value":{"error":"no such element","message":"m","stacktrace":"s"}}`,
+			want:   Error{Err: "no such element", Message: "m", Stacktrace: "s", HTTPCode: http.StatusNotFound},
+		},
+		{
+			desc:   "top-level error",
+			status: http.StatusInternalServerError,
+			body:   `{"error":"unknown error","message":"m"}`,
+			want:   Error{Err: "unknown error", Message: "m", HTTPCode: http.StatusInternalServerError},
+		},
+		{
+			desc:   "legacy error",
+			status: http.StatusOK,
+			body:   `{"status":7,"value":{"message":"m"}}`,
+			want:   Error{Err: "no such element", Message: "m", HTTPCode: http.StatusOK, LegacyCode: 7},
+		},
+		{
+			desc:   "legacy error without a message",
+			status: http.StatusOK,
+			body:   `{"status":10,"value":null}`,
+			want:   Error{Err: "stale element reference", HTTPCode: http.StatusOK, LegacyCode: 10},
+		},
+	}
+	for _, test := range tests {
+		hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", jsonContentType)
+			w.WriteHeader(test.status)
+			io.WriteString(w, test.body)
+		}))
+		wd := &WebDriver{id: "1", urlPrefix: hs.URL}
+
+		_, err := wd.CurrentURL()
+		hs.Close()
+		e, ok := err.(*Error)
+		if !ok {
+			t.Errorf("%s: CurrentURL() returned error %#v, want an *Error", test.desc, err)
+			continue
+		}
+		if *e != test.want {
+			t.Errorf("%s: CurrentURL() returned error %#v, want %#v", test.desc, *e, test.want)
+		}
+	}
+}
+
+func TestConcurrentUse(t *testing.T) {
+	var mu sync.Mutex
+	performed := make(map[string]bool)
+	record := func(next Handler) Handler {
+		return func(ctx context.Context, cmd *Command) (*Response, error) {
+			if cmd.Name == addActions {
+				var body struct {
+					Actions []struct{ ID string }
+				}
+				if err := json.Unmarshal(cmd.Body, &body); err != nil {
+					t.Errorf("invalid actions %s: %v", cmd.Body, err)
+				}
+				mu.Lock()
+				for _, a := range body.Actions {
+					performed[a.ID] = true
+				}
+				mu.Unlock()
+			}
+			return next(ctx, cmd)
+		}
+	}
+	wd, _ := newFakeSession(t, nil, map[string]string{homePage: `<title>Home</title>`}, WithInterceptors(record))
+
+	const n = 8
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("keyboard%d", i)
+			wd.StoreKeyActions(id, KeyDownAction("a"), KeyUpAction("a"))
+			c := wd.WithContext(context.Background())
+			c.StorePointerActions("copy"+id, MousePointer, PointerPauseAction(0))
+			if title, err := c.Title(); err != nil || title != "Home" {
+				t.Errorf("Title() = %q, %v, want %q", title, err, "Home")
+			}
+			if err := c.PerformActions(); err != nil {
+				t.Errorf("PerformActions() on a copy returned error: %v", err)
+			}
+			if err := wd.PerformActions(); err != nil {
+				t.Errorf("PerformActions() returned error: %v", err)
+			}
+			if wd.SessionID() == "" {
+				t.Errorf("SessionID() returned an empty ID")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		for _, id := range []string{fmt.Sprintf("keyboard%d", i), fmt.Sprintf("copykeyboard%d", i)} {
+			if !performed[id] {
+				t.Errorf("the actions of input %q were not performed", id)
+			}
+		}
+	}
+
+	var quitErrs [2]error
+	for i := range quitErrs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			quitErrs[i] = wd.Quit()
+		}(i)
+	}
+	wg.Wait()
+	if wd.SessionID() != "" {
+		t.Errorf("SessionID() = %q after Quit(), want an empty ID", wd.SessionID())
+	}
+	if quitErrs[0] != nil && quitErrs[1] != nil {
+		t.Errorf("Quit() returned errors %v and %v", quitErrs[0], quitErrs[1])
+	}
+}
+
+func TestCopyStoredActions(t *testing.T) {
+	wd := &WebDriver{id: "1"}
+	for i := 0; i < 3; i++ {
+		wd.StoreKeyActions(fmt.Sprint(i), KeyDownAction("a"))
+	}
+	c := wd.Copy()
+	c.StoreKeyActions("copy", KeyDownAction("b"))
+	wd.StoreKeyActions("original", KeyDownAction("c"))
+
+	if got := c.storedActions[3]["id"]; got != "copy" {
+		t.Errorf("the copy stored an action for input %q, want %q", got, "copy")
+	}
+	if got := wd.storedActions[3]["id"]; got != "original" {
+		t.Errorf("the original stored an action for input %q, want %q", got, "original")
+	}
+}
+
+func TestCopyLiteral(t *testing.T) {
+	wd := &WebDriver{id: "1"}
+	copies := make([]*WebDriver, 8)
+	var wg sync.WaitGroup
+	for i := range copies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			copies[i] = wd.Copy()
+		}(i)
+	}
+	wg.Wait()
+	for _, c := range copies {
+		if c.session() != wd.session() {
+			t.Fatalf("a copy of a literal does not share its state")
+		}
+	}
+}
+
+func TestWindowCommands(t *testing.T) {
+	wd, _ := newFakeSession(t, nil, map[string]string{homePage: `<title>Home</title>`})
+
+	current, err := wd.CurrentWindowHandle()
+	if err != nil {
+		t.Fatalf("CurrentWindowHandle() returned error: %v", err)
+	}
+	handle, err := wd.NewWindow(BrowserWindow)
+	if err != nil {
+		t.Fatalf("NewWindow() returned error: %v", err)
+	}
+	if handle == "" || handle == current {
+		t.Errorf("NewWindow() returned handle %q, want a new handle", handle)
+	}
+	if h, err := wd.CurrentWindowHandle(); err != nil || h != current {
+		t.Errorf("CurrentWindowHandle() after NewWindow() = %q, %v, want %q", h, err, current)
+	}
+
+	want := Rect{X: 10, Y: 20, Width: 800, Height: 600}
+	if err := wd.SetWindowRect(want); err != nil {
+		t.Fatalf("SetWindowRect() returned error: %v", err)
+	}
+	if r, err := wd.WindowRect(); err != nil || *r != want {
+		t.Errorf("WindowRect() = %+v, %v, want %+v", r, err, want)
+	}
+	if err := wd.FullscreenWindow(""); err != nil {
+		t.Errorf("FullscreenWindow() returned error: %v", err)
+	}
+	if err := wd.SwitchToParentFrame(); err != nil {
+		t.Errorf("SwitchToParentFrame() returned error: %v", err)
+	}
+
+	if err := wd.SetImplicitWaitTimeout(2 * time.Second); err != nil {
+		t.Fatalf("SetImplicitWaitTimeout() returned error: %v", err)
+	}
+	wantTimeouts := Timeouts{Implicit: 2 * time.Second, PageLoad: 5 * time.Minute, Script: 30 * time.Second}
+	if got, err := wd.Timeouts(); err != nil || *got != wantTimeouts {
+		t.Errorf("Timeouts() = %+v, %v, want %+v", got, err, wantTimeouts)
+	}
+}
+
+func TestPrintPDF(t *testing.T) {
+	wd, _ := newFakeSession(t, nil, map[string]string{homePage: `<title>Home</title>`})
+
+	pdf, err := wd.PrintPDF(nil)
+	if err != nil {
+		t.Fatalf("PrintPDF() returned error: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) || !bytes.Contains(pdf, []byte("/MediaBox [0 0 612.00 792.00]")) {
+		t.Errorf("PrintPDF() returned %q, want a PDF document of a letter page", pdf)
+	}
+
+	pdf, err = wd.PrintPDF(&PrintOptions{
+		Orientation: Landscape,
+		Scale:       0.5,
+		Background:  true,
+		Page:        &PageA4,
+		Margins:     &Margins{},
+		PageRanges:  []string{"1", "3-5"},
+	})
+	if err != nil {
+		t.Fatalf("PrintPDF() returned error: %v", err)
+	}
+	if !bytes.Contains(pdf, []byte("/MediaBox [0 0 841.89 595.28]")) {
+		t.Errorf("PrintPDF() returned %q, want a PDF document of a landscape A4 page", pdf)
+	}
+
+	for _, opts := range []*PrintOptions{
+		{Orientation: "sideways"},
+		{Scale: 3},
+		{Page: &PageSize{}},
+		{Margins: &Margins{Top: -1}},
+	} {
+		if _, err := wd.PrintPDF(opts); err == nil {
+			t.Errorf("PrintPDF(%+v) did not return an error", opts)
+		}
+	}
+}